@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"github.com/twinj/uuid"
+	"lagoon/datasource"
+	"log"
+	"sort"
+	"time"
+)
+
+// Statuses of the analyses.
+const (
+	AnalysisRunning   = "running"
+	AnalysisCompleted = "completed"
+	AnalysisFailed    = "failed"
+	AnalysisCancelled = "cancelled"
+)
+
+// Count of finished analyses kept for each data source, the oldest being removed first.
+const maxFinishedAnalyses = 10
+
+// AnalysisJob describes an analysis of the entry points of a data source, running in background, with its report.
+type AnalysisJob struct {
+	Id           string                     `json:"id"`
+	DataSourceId datasource.DataSourceId    `json:"dataSourceId"`
+	Status       string                     `json:"status"`
+	Error        string                     `json:"error,omitempty"`
+	Options      datasource.AnalysisOptions `json:"options"`
+	StartedAt    time.Time                  `json:"startedAt"`
+	FinishedAt   *time.Time                 `json:"finishedAt,omitempty"`
+	Report       datasource.AnalysisReport  `json:"report"`
+}
+
+// analysisJob is an analysis registered in the registry. Its status, end and error are set before done is closed.
+type analysisJob struct {
+	id           string
+	dataSourceId datasource.DataSourceId
+	analysis     *datasource.Analysis
+	cancel       context.CancelFunc
+	startedAt    time.Time
+	done         chan struct{}
+	status       string
+	finishedAt   time.Time
+	err          error
+}
+
+// StartAnalysis starts the analysis of the entry points of the data source in background, and returns it.
+// The analysis is not limited by the timeout of the data source, but is cancelled when the data source is closed.
+func (r *Registry) StartAnalysis(dataSourceId datasource.DataSourceId, dataSource datasource.DataSource, options datasource.AnalysisOptions) AnalysisJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &analysisJob{
+		id:           uuid.NewV4().String(),
+		dataSourceId: dataSourceId,
+		analysis:     datasource.NewAnalysis(options),
+		cancel:       cancel,
+		startedAt:    time.Now(),
+		done:         make(chan struct{}),
+	}
+	r.mutex.Lock()
+	r.analyses[job.id] = job
+	r.removeFinishedAnalyses(dataSourceId)
+	r.mutex.Unlock()
+
+	go func() {
+		defer cancel()
+		log.Printf("Starting the analysis %s of the data source %s\n", job.id, dataSourceId)
+		err := dataSource.AnalyzeEntryPoints(ctx, job.analysis)
+		switch {
+		case ctx.Err() != nil:
+			job.status = AnalysisCancelled
+		case err != nil:
+			job.status = AnalysisFailed
+			job.err = err
+		default:
+			job.status = AnalysisCompleted
+		}
+		job.analysis.Finish()
+		job.finishedAt = time.Now()
+		log.Printf("The analysis %s of the data source %s is %s\n", job.id, dataSourceId, job.status)
+		close(job.done)
+	}()
+	return job.snapshot()
+}
+
+// Analysis returns the analysis of the data source.
+func (r *Registry) Analysis(dataSourceId datasource.DataSourceId, analysisId string) (*analysisJob, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	job, exists := r.analyses[analysisId]
+	if !exists || job.dataSourceId != dataSourceId {
+		return nil, false
+	}
+	return job, true
+}
+
+// Analyses returns the running and finished analyses of the data source, from the oldest to the newest.
+func (r *Registry) Analyses(dataSourceId datasource.DataSourceId) []AnalysisJob {
+	r.mutex.RLock()
+	var jobs []*analysisJob
+	for _, job := range r.analyses {
+		if job.dataSourceId == dataSourceId {
+			jobs = append(jobs, job)
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].startedAt.Before(jobs[j].startedAt)
+	})
+	result := []AnalysisJob{}
+	for _, job := range jobs {
+		result = append(result, job.snapshot())
+	}
+	return result
+}
+
+// CancelAnalysis cancels the analysis if it is running, or removes it once finished. It returns false
+// if the analysis does not exist.
+func (r *Registry) CancelAnalysis(dataSourceId datasource.DataSourceId, analysisId string) bool {
+	job, exists := r.Analysis(dataSourceId, analysisId)
+	if !exists {
+		return false
+	}
+	if job.isRunning() {
+		job.cancel()
+	} else {
+		r.mutex.Lock()
+		delete(r.analyses, analysisId)
+		r.mutex.Unlock()
+	}
+	return true
+}
+
+// removeFinishedAnalyses removes the oldest finished analyses of the data source, above the maximal count.
+// The lock has to be held by the caller.
+func (r *Registry) removeFinishedAnalyses(dataSourceId datasource.DataSourceId) {
+	var finished []*analysisJob
+	for _, job := range r.analyses {
+		if job.dataSourceId == dataSourceId && !job.isRunning() {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= maxFinishedAnalyses {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].startedAt.Before(finished[j].startedAt)
+	})
+	for _, job := range finished[:len(finished)-maxFinishedAnalyses] {
+		delete(r.analyses, job.id)
+	}
+}
+
+// removeAnalyses unregisters the analyses of the data source, and returns them to be cancelled.
+// The lock has to be held by the caller.
+func (r *Registry) removeAnalyses(dataSourceId datasource.DataSourceId) []*analysisJob {
+	var jobs []*analysisJob
+	for analysisId, job := range r.analyses {
+		if job.dataSourceId == dataSourceId {
+			jobs = append(jobs, job)
+			delete(r.analyses, analysisId)
+		}
+	}
+	return jobs
+}
+
+func (j *analysisJob) isRunning() bool {
+	select {
+	case <-j.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// snapshot returns the current state of the analysis and of its report.
+func (j *analysisJob) snapshot() AnalysisJob {
+	// The status is read first, so that the report of a finished analysis is complete.
+	running := j.isRunning()
+	result := AnalysisJob{
+		Id:           j.id,
+		DataSourceId: j.dataSourceId,
+		Status:       AnalysisRunning,
+		Options:      j.analysis.Options(),
+		StartedAt:    j.startedAt,
+		Report:       j.analysis.Report(),
+	}
+	if !running {
+		finishedAt := j.finishedAt
+		result.Status = j.status
+		result.FinishedAt = &finishedAt
+		if j.err != nil {
+			result.Error = j.err.Error()
+		}
+	}
+	return result
+}
@@ -1,7 +1,7 @@
 package api
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -11,13 +11,26 @@ import (
 	"log"
 	"math"
 	"net/http"
-	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const webSocketBatchSize = uint64(500)
 
+// Default number of pending entries returned for each consumer group of a stream.
+const defaultPendingEntriesCount = 100
+
+// Default number of children returned in the preview of a deletion.
+const defaultDeletionSampleSize = 20
+
+// Duration during which the confirmation token of a deletion can be used.
+const deletionConfirmationValidity = 5 * time.Minute
+
+// Interval between two reports sent to the web-socket streaming a running analysis.
+const analysisProgressInterval = time.Second
+
 type DataSourceHeader struct {
 	Id          datasource.DataSourceId `json:"id" binding:"required"`
 	Vendor      string                  `json:"vendor" binding:"required"`
@@ -27,32 +40,89 @@ type DataSourceHeader struct {
 }
 
 type CommandRequest struct {
-	Args   []interface{} `json:"args" binding:"required`
+	Args   []interface{} `json:"args" binding:"required"`
 	NodeID string        `json:"nodeId"`
 }
 
-var DataSourcesHeaders = make(map[datasource.DataSourceId]DataSourceHeader)
+type ValueRequest struct {
+	Value string `json:"value"`
+}
+
+type MembersRequest struct {
+	Members []string `json:"members" binding:"required"`
+}
+
+type ScoredMembersRequest struct {
+	Members []datasource.ScoredMember `json:"members" binding:"required"`
+}
+
+type HashFieldsRequest struct {
+	Fields map[string]string `json:"fields" binding:"required"`
+}
+
+type ListValuesRequest struct {
+	Values []string `json:"values" binding:"required"`
+	Head   bool     `json:"head"`
+}
+
+type StreamMessageRequest struct {
+	Id     string                 `json:"id"`
+	Fields map[string]interface{} `json:"fields" binding:"required"`
+}
+
+type MoveRequest struct {
+	Target string `json:"target" binding:"required"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// ExpiryRequest sets the expiry of entry points, either absolute with ExpireAt or relative with TimeToLive in milliseconds.
+// The expiry is removed when none is set.
+type ExpiryRequest struct {
+	TimeToLive *int64     `json:"timeToLive"`
+	ExpireAt   *time.Time `json:"expireAt"`
+}
+
+// deletionConfirmation is the pending deletion of the children of an entry point, confirmed by its token.
+type deletionConfirmation struct {
+	dataSourceId datasource.DataSourceId
+	entrypoint   datasource.EntryPoint
+	expiresAt    time.Time
+	// inUse is true while the deletion confirmed by the token is starting, so that it cannot be confirmed twice.
+	inUse bool
+}
+
+var registry = NewRegistry()
 
-var dataSources = make(map[datasource.DataSourceId]datasource.DataSource)
-var webSocketChannels = make(map[string]chan datasource.DataBatch)
-var webSocketErrorChannels = make(map[string]chan error)
+var deletionConfirmations = make(map[string]deletionConfirmation)
+var deletionConfirmationsMutex = sync.Mutex{}
 
 func CloseAllDataSources() {
 	log.Println("Closing all data sources...")
-	for _, ds := range dataSources {
-		ds.Close()
-	}
-	dataSources = make(map[datasource.DataSourceId]datasource.DataSource)
-	DataSourcesHeaders = make(map[datasource.DataSourceId]DataSourceHeader)
-
+	registry.CloseAll()
 	log.Println("Data sources closed")
 }
 
+// SetPendingWebSocketExpiry sets the duration after which the web-sockets whose client did not connect expire,
+// cancelling their operation. 0 disables the expiration.
+func SetPendingWebSocketExpiry(expiry time.Duration) {
+	registry.SetPendingExpiry(expiry)
+}
+
+// ListWebSockets returns the streams to web-sockets, pending until their client connects, then active.
+func ListWebSockets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"streams": registry.WebSockets()})
+}
+
+// AddDataSourceHooks registers hooks to be notified when the data sources are opened, replaced or closed.
+func AddDataSourceHooks(hooks DataSourceHooks) {
+	registry.AddHooks(hooks)
+}
+
 func CreateNewDataSource(c *gin.Context) {
 	// TODO Validate: https://gin-gonic.com/docs/examples/custom-validators/
 	var dataSourceDescriptor datasource.DataSourceDescriptor
 	if err := c.Bind(&dataSourceDescriptor); err == nil {
-		ds, err := CreateDataSourceFromDescriptor(dataSourceDescriptor, true)
+		ds, err := CreateDataSourceFromDescriptor(dataSourceDescriptor)
 		if err != nil {
 			if xerrors.Is(err, datasource.ErrUnkownDatasource) {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -60,8 +130,7 @@ func CreateNewDataSource(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			}
 		} else {
-			DataSourcesHeaders[ds.Id] = ds
-			log.Printf("Current data sources: %v \n", DataSourcesHeaders)
+			log.Printf("Current data sources: %v \n", registry.Headers())
 			c.JSON(http.StatusOK, gin.H{"dataSourceId": ds.Id})
 		}
 	} else {
@@ -69,24 +138,16 @@ func CreateNewDataSource(c *gin.Context) {
 	}
 }
 
+// UpdateDataSource replaces the data source with a new one created from the descriptor. The existing data source
+// is only closed once the new one was successfully opened.
 func UpdateDataSource(c *gin.Context) {
 	var dataSourceDescriptor datasource.DataSourceDescriptor
 	if err := c.Bind(&dataSourceDescriptor); err == nil {
-		dataSourceId := datasource.DataSourceId(dataSourceDescriptor.Id)
-		existingDataSource, exists := dataSources[dataSourceId]
-		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("The datasource with id '%s' does not exists", dataSourceId)})
-			return
-		}
-		existingDataSource.Close()
-
-		datasource, err := CreateDataSourceFromDescriptor(dataSourceDescriptor, false)
+		_, err := registry.Replace(dataSourceDescriptor)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		} else {
-			// Replace the existing data source.
-			DataSourcesHeaders[datasource.Id] = datasource
-			log.Printf("Current data sources: %v \n", DataSourcesHeaders)
+			log.Printf("Current data sources: %v \n", registry.Headers())
 			c.Status(http.StatusNoContent)
 			c.Writer.WriteHeaderNow()
 		}
@@ -96,62 +157,31 @@ func UpdateDataSource(c *gin.Context) {
 }
 
 func GetDataSources(c *gin.Context) {
-	log.Printf("Current data sources: %v \n", DataSourcesHeaders)
-	datasources := []DataSourceHeader{}
-	for _, v := range DataSourcesHeaders {
-		datasources = append(datasources, v)
-	}
-	sort.Slice(datasources, func(i, j int) bool {
-		return datasources[i].Name < datasources[j].Name
-	})
+	datasources := registry.Headers()
+	log.Printf("Current data sources: %v \n", datasources)
 	c.JSON(http.StatusOK, gin.H{"datasources": datasources})
 }
 
 func DeleteDataSource(c *gin.Context) {
 	datasourceId := datasource.DataSourceId(c.Params.ByName("DataSourceId"))
-	ds, ok := dataSources[datasourceId]
-	if ok {
-		ds.Close()
-		delete(dataSources, datasourceId)
-		delete(DataSourcesHeaders, datasourceId)
+	if registry.Remove(datasourceId) {
 		c.JSON(http.StatusOK, gin.H{"message": "Data source was closed and removed"})
 	} else {
 		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The datasource with id '%s' does not exists", datasourceId)})
 	}
 }
 
-func CreateDataSourceFromDescriptor(dataSourceDescriptor datasource.DataSourceDescriptor, new bool) (DataSourceHeader, error) {
-	log.Printf("Creating data source %v\n", dataSourceDescriptor)
-	dataSource, err := datasource.CreateDataSource(&dataSourceDescriptor)
-
-	if err == nil {
-		var dataSourceId datasource.DataSourceId
-		if dataSourceDescriptor.Id != "" {
-			dataSourceId = datasource.DataSourceId(dataSourceDescriptor.Id)
-			if _, exists := dataSources[dataSourceId]; exists && new {
-				return DataSourceHeader{}, errors.New(fmt.Sprintf("The datasource with id '%s' already exists", dataSourceId))
-			}
-		} else {
-			dataSourceId = datasource.DataSourceId(uuid.NewV4().String())
-		}
-		dataSources[dataSourceId] = dataSource
-
-		dsInfos := DataSourceHeader{
-			Id:          dataSourceId,
-			Vendor:      dataSourceDescriptor.Vendor,
-			Name:        dataSourceDescriptor.Name,
-			Description: dataSourceDescriptor.Description,
-			ReadOnly:    dataSourceDescriptor.ReadOnly,
-		}
-		return dsInfos, nil
-	}
-	return DataSourceHeader{}, err
+// CreateDataSourceFromDescriptor creates, opens and registers the data source, and fails if its ID is already used.
+func CreateDataSourceFromDescriptor(dataSourceDescriptor datasource.DataSourceDescriptor) (DataSourceHeader, error) {
+	return registry.Open(dataSourceDescriptor)
 }
 
 func GetInfos(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
-		infos, err := ds.GetInfos()
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		infos, err := ds.GetInfos(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
@@ -164,7 +194,9 @@ func GetInfos(c *gin.Context) {
 func GetState(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
-		status, err := ds.GetStatus()
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		status, err := ds.GetStatus(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
@@ -177,32 +209,141 @@ func GetState(c *gin.Context) {
 func ListEntryPoints(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
-		filter := getFilter(c)
+		filter, err := getFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		minLevel := getMinLevel(c)
 		maxLevel := getMaxLevel(c)
 		entrypointsChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
-		status, err := ds.ListEntryPoints(filter, entrypointsChannel, minLevel, maxLevel)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		} else if status == datasource.Moved {
-			wsUuid := uuid.NewV4().String()
-			webSocketChannels[wsUuid] = entrypointsChannel
-			c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
+		ctx, cancel := operationContext(c)
+		status, err := ds.ListEntryPoints(ctx, filter, entrypointsChannel, minLevel, maxLevel)
+		sendDataChannel(c, status, err, entrypointsChannel, ctx, cancel, nil)
+	}
+}
+
+// sendDataChannel returns the unique batch of a completed action in the response, or a link to
+// the web-socket to read the data from when the action moved to asynchronous mode.
+// The operation is cancelled with the web-socket in the latter case, immediately otherwise.
+func sendDataChannel(c *gin.Context, status datasource.ActionStatus, err error, dataChannel chan datasource.DataBatch, ctx context.Context, cancel context.CancelFunc, decode func(datasource.DataBatch) datasource.DataBatch) {
+	if err != nil {
+		cancel()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	} else if status == datasource.Moved {
+		if decode != nil {
+			dataChannel, cancel = decodeDataChannel(dataChannel, cancel, decode)
+		}
+		wsUuid := registry.AddWebSocket(getDataSourceId(c), c.Request.URL.Path, dataChannel, nil, ctx, cancel)
+		c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
+
+	} else if status == datasource.Completed {
+		cancel()
+		dataBatch := <-dataChannel
+		close(dataChannel)
+		if decode != nil {
+			dataBatch = decode(dataBatch)
+		}
+		c.JSON(http.StatusOK, gin.H{"size": dataBatch.Size, "data": dataBatch.Data})
+	} else {
+		cancel()
+	}
+}
+
+// decodeDataChannel returns a channel receiving the decoded batches of dataChannel, and the function cancelling
+// the operation and the decoding.
+func decodeDataChannel(dataChannel chan datasource.DataBatch, cancel context.CancelFunc, decode func(datasource.DataBatch) datasource.DataBatch) (chan datasource.DataBatch, context.CancelFunc) {
+	decoded := make(chan datasource.DataBatch, cap(dataChannel))
+	stopped := make(chan struct{})
+	stop := sync.Once{}
+	go func() {
+		defer close(decoded)
+		for batch := range dataChannel {
+			select {
+			case decoded <- decode(batch):
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	return decoded, func() {
+		cancel()
+		stop.Do(func() { close(stopped) })
+	}
+}
+
+// contentDecoder returns the function decoding the values of the content of the entry point, with the decoders
+// of the query parameter decoders if set, otherwise the ones configured for the data source and prefix of the key.
+func contentDecoder(c *gin.Context, entrypoint datasource.EntryPoint) (func(datasource.DataBatch) datasource.DataBatch, error) {
+	decoders := registry.Decoders(getDataSourceId(c))
+	pipeline := decoders.PipelineOf(string(entrypoint))
+	if spec, exists := c.GetQuery("decoders"); exists {
+		var err error
+		if pipeline, err = decoders.Parse(spec); err != nil {
+			return nil, err
+		}
+	}
+	return pipeline.DecodeBatch, nil
+}
+
+// getFilter builds the filter from the query parameters:
+// filter (glob pattern), regex, valueMin, valueMax, scoreMin, scoreMax and field (as field=value glob patterns, repeatable).
+func getFilter(c *gin.Context) (datasource.Filter, error) {
+	filter := datasource.Filter{
+		Glob: "*",
+	}
+
+	glob, exists := c.GetQuery("filter")
+	if exists {
+		filter.Glob = glob
+	}
+	regex, exists := c.GetQuery("regex")
+	if exists {
+		filter.Regex = regex
+	}
+
+	valueMin, minExists := c.GetQuery("valueMin")
+	valueMax, maxExists := c.GetQuery("valueMax")
+	if minExists || maxExists {
+		filter.ValueRange = &datasource.ValueRange{Min: valueMin, Max: valueMax}
+	}
 
-		} else if status == datasource.Completed {
-			dataBatch := <-entrypointsChannel
-			close(entrypointsChannel)
-			c.JSON(http.StatusOK, gin.H{"size": dataBatch.Size, "data": dataBatch.Data})
+	scoreMin, err := getFloatQuery(c, "scoreMin")
+	if err != nil {
+		return filter, err
+	}
+	scoreMax, err := getFloatQuery(c, "scoreMax")
+	if err != nil {
+		return filter, err
+	}
+	if scoreMin != nil || scoreMax != nil {
+		filter.ScoreRange = datasource.NewScoreRange(scoreMin, scoreMax)
+	}
+
+	for _, field := range c.QueryArray("field") {
+		tokens := strings.SplitN(field, "=", 2)
+		fieldFilter := datasource.FieldFilter{Field: tokens[0], Value: "*"}
+		if len(tokens) > 1 {
+			fieldFilter.Value = tokens[1]
 		}
+		filter.Fields = append(filter.Fields, fieldFilter)
 	}
+
+	// Validates the patterns.
+	_, err = datasource.NewMatcher(filter)
+	return filter, err
 }
 
-func getFilter(c *gin.Context) string {
-	filter, exists := c.GetQuery("filter")
-	if !exists {
-		filter = "*"
+func getFloatQuery(c *gin.Context, name string) (*float64, error) {
+	param, exists := c.GetQuery(name)
+	if exists {
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the parameter %s is not a valid number: %s", name, param)
+		}
+		return &value, nil
 	}
-	return filter
+	return nil, nil
 }
 
 func getMinLevel(c *gin.Context) uint {
@@ -231,9 +372,30 @@ func GetEntryPointInfos(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
 		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
-		infos, err := ds.GetEntryPointInfos(entrypoint)
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		infos, err := ds.GetEntryPointInfos(ctx, entrypoint)
 		if err == nil {
-			c.JSON(http.StatusOK, gin.H{"type": datasource.EntryPointTypesAsString[infos.Type], "length": infos.Length, "timeToLive": infos.TimeToLive / time.Millisecond})
+			response := gin.H{"type": datasource.EntryPointTypesAsString[infos.Type], "length": infos.Length, "timeToLive": infos.TimeToLive / time.Millisecond}
+			if infos.Stream != nil {
+				response["stream"] = infos.Stream
+			}
+			if infos.Partitions != nil {
+				response["partitions"] = infos.Partitions
+			}
+			if infos.Queue != nil {
+				response["queue"] = infos.Queue
+			}
+			if infos.Exchange != nil {
+				response["exchange"] = infos.Exchange
+			}
+			if infos.Object != nil {
+				response["object"] = infos.Object
+			}
+			if infos.Location != nil {
+				response["location"] = infos.Location
+			}
+			c.JSON(http.StatusOK, response)
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -244,29 +406,189 @@ func GetEntryPointContent(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
 		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
-		filter := getFilter(c)
+		filter, err := getFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		page, paged, err := getPage(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		decode, err := contentDecoder(c, entrypoint)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if paged {
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			content, err := ds.GetContentPage(ctx, entrypoint, filter, page)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else {
+				content.Data = decode(datasource.DataBatch{Size: content.Size, Data: content.Data}).Data
+				c.JSON(http.StatusOK, content)
+			}
+			return
+		}
+		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+		ctx, cancel := operationContext(c)
+		status, err := ds.GetContent(ctx, entrypoint, filter, dataChannel)
+		sendDataChannel(c, status, err, dataChannel, ctx, cancel, decode)
+	}
+}
+
+// getPage builds the page of content from the query parameters cursor and count. The content is only paged
+// when one of them is set.
+func getPage(c *gin.Context) (datasource.Page, bool, error) {
+	page := datasource.Page{}
+	cursor, cursorExists := c.GetQuery("cursor")
+	countParam, countExists := c.GetQuery("count")
+	if cursorExists {
+		page.Cursor = cursor
+	}
+	if countExists {
+		count, err := strconv.ParseInt(countParam, 10, 64)
+		if err != nil || count <= 0 {
+			return page, true, fmt.Errorf("the count '%s' is not valid", countParam)
+		}
+		page.Count = count
+	}
+	return page, cursorExists || countExists, nil
+}
+
+func GetEntryPointStreamRange(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		streamRange, err := getStreamRange(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		decode, err := contentDecoder(c, entrypoint)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+		ctx, cancel := operationContext(c)
+		status, err := ds.GetStreamRange(ctx, entrypoint, streamRange, dataChannel)
+		sendDataChannel(c, status, err, dataChannel, ctx, cancel, decode)
+	}
+}
+
+// GetSortedSetRange returns the members of a sorted set by rank, score or lexicographic order, see getSortedSetRange.
+func GetSortedSetRange(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		sortedSetRange, err := getSortedSetRange(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		decode, err := contentDecoder(c, entrypoint)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
-		status, err := ds.GetContent(entrypoint, filter, dataChannel)
+		ctx, cancel := operationContext(c)
+		status, err := ds.GetSortedSetRange(ctx, entrypoint, sortedSetRange, dataChannel)
+		sendDataChannel(c, status, err, dataChannel, ctx, cancel, decode)
+	}
+}
+
+// getSortedSetRange builds the range of a sorted set from the query parameters:
+// by (rank, score or lex), start, stop, reverse, offset and count.
+func getSortedSetRange(c *gin.Context) (datasource.SortedSetRange, error) {
+	sortedSetRange := datasource.SortedSetRange{
+		By:    c.DefaultQuery("by", datasource.ByRank),
+		Start: c.Query("start"),
+		Stop:  c.Query("stop"),
+	}
+	switch sortedSetRange.By {
+	case datasource.ByRank, datasource.ByScore, datasource.ByLex:
+	default:
+		return sortedSetRange, fmt.Errorf("the kind of range '%s' is not valid", sortedSetRange.By)
+	}
+	if reverseParam, exists := c.GetQuery("reverse"); exists {
+		reverse, err := strconv.ParseBool(reverseParam)
 		if err != nil {
+			return sortedSetRange, fmt.Errorf("the parameter reverse is not a valid boolean: %s", reverseParam)
+		}
+		sortedSetRange.Reverse = reverse
+	}
+	for name, value := range map[string]*int64{"offset": &sortedSetRange.Offset, "count": &sortedSetRange.Count} {
+		if param, exists := c.GetQuery(name); exists {
+			number, err := strconv.ParseInt(param, 10, 64)
+			if err != nil || number < 0 {
+				return sortedSetRange, fmt.Errorf("the parameter %s is not a valid number: %s", name, param)
+			}
+			*value = number
+		}
+	}
+	return sortedSetRange, nil
+}
+
+// GetEntryPointStreamInfos returns the details of a stream, its consumer groups and their pending entries.
+func GetEntryPointStreamInfos(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		pendingEntriesCount := int64(defaultPendingEntriesCount)
+		if countParam, exists := c.GetQuery("count"); exists {
+			count, err := strconv.ParseInt(countParam, 10, 64)
+			if err != nil || count < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the parameter count is not a valid number: %s", countParam)})
+				return
+			}
+			pendingEntriesCount = count
+		}
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		infos, err := ds.GetStreamInfos(ctx, entrypoint, pendingEntriesCount)
+		if err == nil {
+			c.JSON(http.StatusOK, gin.H{"stream": infos})
+		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		} else if status == datasource.Moved {
-			wsUuid := uuid.NewV4().String()
-			webSocketChannels[wsUuid] = dataChannel
-			c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
+		}
+	}
+}
 
-		} else if status == datasource.Completed {
-			dataBatch := <-dataChannel
-			close(dataChannel)
-			c.JSON(http.StatusOK, gin.H{"size": dataBatch.Size, "data": dataBatch.Data})
+// getStreamRange builds the range of a stream from the query parameters: start, end, count and reverse.
+func getStreamRange(c *gin.Context) (datasource.StreamRange, error) {
+	streamRange := datasource.StreamRange{
+		Start: c.Query("start"),
+		End:   c.Query("end"),
+	}
+	if countParam, exists := c.GetQuery("count"); exists {
+		count, err := strconv.ParseInt(countParam, 10, 64)
+		if err != nil || count < 0 {
+			return streamRange, fmt.Errorf("the parameter count is not a valid number: %s", countParam)
+		}
+		streamRange.Count = count
+	}
+	if reverseParam, exists := c.GetQuery("reverse"); exists {
+		reverse, err := strconv.ParseBool(reverseParam)
+		if err != nil {
+			return streamRange, fmt.Errorf("the parameter reverse is not a valid boolean: %s", reverseParam)
 		}
+		streamRange.Reverse = reverse
 	}
+	return streamRange, nil
 }
 
 func DeleteEntryPoint(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
 		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
-		err := ds.DeleteEntrypoint(entrypoint)
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		err := ds.DeleteEntrypoint(ctx, entrypoint)
 		if err == nil {
 			c.JSON(http.StatusOK, gin.H{"message": "Entry point was deleted"})
 		} else {
@@ -275,103 +597,708 @@ func DeleteEntryPoint(c *gin.Context) {
 	}
 }
 
-func DeleteEntryPointChildren(c *gin.Context) {
+// PreviewEntryPointChildrenDeletion describes the children of the entry point to delete, and returns the token
+// to confirm the deletion with.
+func PreviewEntryPointChildrenDeletion(c *gin.Context) {
 	ds, ok := findDataSource(c)
 	if ok {
 		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
-		errorChannel := make(chan error, datasource.SwitchToWsBarrier)
-		_, err := ds.DeleteEntrypointChildren(entrypoint, errorChannel)
+		sampleSize := defaultDeletionSampleSize
+		if sampleParam, exists := c.GetQuery("sample"); exists {
+			sample, error := strconv.Atoi(sampleParam)
+			if error != nil || sample < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The sample size '%s' is not valid", sampleParam)})
+				return
+			}
+			sampleSize = sample
+		}
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		preview, err := ds.PreviewEntrypointChildrenDeletion(ctx, entrypoint, sampleSize)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		} else {
-			wsUuid := uuid.NewV4().String()
-			webSocketErrorChannels[wsUuid] = errorChannel
-			c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
+			return
 		}
+
+		token := uuid.NewV4().String()
+		expiresAt := time.Now().Add(deletionConfirmationValidity)
+		deletionConfirmationsMutex.Lock()
+		deletionConfirmations[token] = deletionConfirmation{
+			dataSourceId: datasource.DataSourceId(c.Params.ByName("DataSourceId")),
+			entrypoint:   entrypoint,
+			expiresAt:    expiresAt,
+		}
+		deletionConfirmationsMutex.Unlock()
+		c.JSON(http.StatusOK, gin.H{"count": preview.Count, "sample": preview.Sample, "memory": preview.Memory, "token": token, "expiresAt": expiresAt})
 	}
 }
 
-func findDataSource(c *gin.Context) (datasource.DataSource, bool) {
-	datasourceId := datasource.DataSourceId(c.Params.ByName("DataSourceId"))
-	ds, ok := dataSources[datasourceId]
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("DataSource with UUID %s was not found", datasourceId)})
+// confirmDeletion locks the confirmation token of the deletion of the children of the entry point until
+// releaseDeletionConfirmation is called, and returns false when it is missing, expired, already in use or issued
+// for another deletion.
+func confirmDeletion(c *gin.Context, entrypoint datasource.EntryPoint) bool {
+	token := c.Query("token")
+	deletionConfirmationsMutex.Lock()
+	defer deletionConfirmationsMutex.Unlock()
+	now := time.Now()
+	for t, confirmation := range deletionConfirmations {
+		if confirmation.expiresAt.Before(now) {
+			delete(deletionConfirmations, t)
+		}
 	}
-	return ds, ok
+	confirmation, exists := deletionConfirmations[token]
+	if !exists || confirmation.inUse || confirmation.dataSourceId != datasource.DataSourceId(c.Params.ByName("DataSourceId")) || confirmation.entrypoint != entrypoint {
+		return false
+	}
+	confirmation.inUse = true
+	deletionConfirmations[token] = confirmation
+	return true
 }
 
-func ReadChannelContentAndSendToWebSocket(c *gin.Context) {
-	var (
-		errorChannel chan error
-	)
+// releaseDeletionConfirmation removes the confirmation token once the deletion started, or keeps it valid
+// for another attempt when the deletion could not start.
+func releaseDeletionConfirmation(c *gin.Context, started bool) {
+	token := c.Query("token")
+	deletionConfirmationsMutex.Lock()
+	defer deletionConfirmationsMutex.Unlock()
+	if started {
+		delete(deletionConfirmations, token)
+	} else if confirmation, exists := deletionConfirmations[token]; exists {
+		confirmation.inUse = false
+		deletionConfirmations[token] = confirmation
+	}
+}
 
-	wsUuid := c.Params.ByName("wsUuid")
-	dataChannel, ok := webSocketChannels[wsUuid]
-	if !ok {
-		errorChannel, ok = webSocketErrorChannels[wsUuid]
-		if !ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Web socket channel wuth UUID %s was not found", wsUuid)})
+func DeleteEntryPointChildren(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		rateLimit := uint64(0)
+		if rateParam, exists := c.GetQuery("rate"); exists {
+			var error error
+			rateLimit, error = strconv.ParseUint(rateParam, 10, 32)
+			if error != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The rate limit '%s' is not valid", rateParam)})
+				return
+			}
+		}
+		if !confirmDeletion(c, entrypoint) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A valid confirmation token from the preview of the deletion is required"})
 			return
+		}
+		errorChannel := make(chan error, datasource.SwitchToWsBarrier)
+		// The operation is not limited by the timeout of the data source, but stops when the client of the web-socket leaves.
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := ds.DeleteEntrypointChildren(ctx, entrypoint, uint(rateLimit), errorChannel)
+		releaseDeletionConfirmation(c, err == nil)
+		if err != nil {
+			cancel()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		} else {
-			delete(webSocketErrorChannels, wsUuid)
+			wsUuid := registry.AddMutationWebSocket(getDataSourceId(c), c.Request.URL.Path, nil, errorChannel, ctx, cancel)
+			c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
 		}
-	} else {
-		delete(webSocketChannels, wsUuid)
-	}
-	upgrader := websocket.Upgrader{
-		ReadBufferSize:    1024,
-		WriteBufferSize:   1024,
-		EnableCompression: true,
-		CheckOrigin:       func(r *http.Request) bool { return true },
 	}
+}
 
-	// Force headers for calls behind reverse-proxy.
-	c.Request.Header.Set("Connection", "upgrade")
-	c.Request.Header.Set("Upgrade", "websocket")
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		log.Printf("Failed to set websocket upgrade: %v\n", err)
-		return
+func ListTrashEntries(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		entries, err := ds.ListTrashEntries(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusOK, gin.H{"entries": entries})
+		}
 	}
-	conn.SetCloseHandler(func(code int, text string) error {
-		log.Printf("Websocket %v closed with code %v and reason %v\n", wsUuid, code, text)
-		return nil
-	})
+}
 
-	defer func() {
-		go func() {
-			time.Sleep(10 * time.Second)
-			log.Printf("Websocket %v expired\n", wsUuid)
-			conn.Close()
-		}()
-	}()
+// RestoreTrashEntry restores an entry point from the trash, the existing entry point is replaced only if
+// the query parameter replace is true.
+func RestoreTrashEntry(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		replace := false
+		if replaceParam, exists := c.GetQuery("replace"); exists {
+			replace, _ = strconv.ParseBool(replaceParam)
+		}
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		err := ds.RestoreTrashEntry(ctx, c.Params.ByName("trashEntryId"), replace)
+		sendMessage(c, "Entry point was restored", err)
+	}
+}
 
-	log.Printf("Reading channel data for %s\n", wsUuid)
-	if dataChannel != nil {
-		for data := range dataChannel {
-			for _, dataItem := range splitBatch(data) {
-				err = conn.WriteJSON(dataItem)
-				if err != nil {
-					log.Printf("ERROR while converting %v: %s\n", dataItem, err.Error())
-				}
-			}
+func RenameEntryPoint(c *gin.Context) {
+	var moveRequest MoveRequest
+	if c.Bind(&moveRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			err := ds.RenameEntrypoint(ctx, entrypoint, datasource.EntryPoint(moveRequest.Target))
+			sendMessage(c, "Entry point was renamed", err)
 		}
-		err = conn.WriteJSON(datasource.DataBatch{})
 	}
+}
 
-	if errorChannel != nil {
-		for error := range errorChannel {
-			err = conn.WriteJSON(error.Error())
+// MoveEntryPointTree moves the entry point and its children, the planned moves are only listed in dry-run mode.
+func MoveEntryPointTree(c *gin.Context) {
+	var moveRequest MoveRequest
+	if c.Bind(&moveRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			progressChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+			// The operation is not limited by the timeout of the data source, but stops when the client of the web-socket leaves.
+			ctx, cancel := context.WithCancel(context.Background())
+			_, err := ds.MoveEntrypointTree(ctx, entrypoint, datasource.EntryPoint(moveRequest.Target), moveRequest.DryRun, progressChannel)
 			if err != nil {
-				log.Printf("ERROR while sending %v\n", err.Error())
+				cancel()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else if moveRequest.DryRun {
+				wsUuid := registry.AddWebSocket(getDataSourceId(c), c.Request.URL.Path, progressChannel, nil, ctx, cancel)
+				c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
+			} else {
+				wsUuid := registry.AddMutationWebSocket(getDataSourceId(c), c.Request.URL.Path, progressChannel, nil, ctx, cancel)
+				c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
 			}
 		}
 	}
-	log.Printf("Stop reading channel data for %s\n", wsUuid)
 }
 
-func splitBatch(dataToSplit datasource.DataBatch) []datasource.DataBatch {
-	if dataToSplit.Size <= webSocketBatchSize {
+func SetEntryPointExpiry(c *gin.Context) {
+	expiry, ok := getExpiry(c)
+	if ok {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			err := ds.SetExpiry(ctx, entrypoint, expiry)
+			sendMessage(c, "Expiry was set", err)
+		}
+	}
+}
+
+func SetEntryPointChildrenExpiry(c *gin.Context) {
+	expiry, ok := getExpiry(c)
+	if ok {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			progressChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+			// The operation is not limited by the timeout of the data source, but stops when the client of the web-socket leaves.
+			ctx, cancel := context.WithCancel(context.Background())
+			_, err := ds.SetChildrenExpiry(ctx, entrypoint, expiry, progressChannel)
+			if err != nil {
+				cancel()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else {
+				wsUuid := registry.AddMutationWebSocket(getDataSourceId(c), c.Request.URL.Path, progressChannel, nil, ctx, cancel)
+				c.JSON(http.StatusAccepted, gin.H{"link": fmt.Sprintf("/ws/%s", wsUuid)})
+			}
+		}
+	}
+}
+
+func getExpiry(c *gin.Context) (datasource.Expiry, bool) {
+	var (
+		expiryRequest ExpiryRequest
+		expiry        datasource.Expiry
+	)
+	if c.Bind(&expiryRequest) != nil {
+		return expiry, false
+	}
+	if expiryRequest.TimeToLive != nil && expiryRequest.ExpireAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only one of timeToLive and expireAt can be set"})
+		return expiry, false
+	}
+	if expiryRequest.TimeToLive != nil {
+		if *expiryRequest.TimeToLive <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The time to live should be positive"})
+			return expiry, false
+		}
+		expiry.TimeToLive = time.Duration(*expiryRequest.TimeToLive) * time.Millisecond
+	}
+	if expiryRequest.ExpireAt != nil {
+		expiry.ExpireAt = *expiryRequest.ExpireAt
+	}
+	if err := expiry.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return expiry, false
+	}
+	return expiry, true
+}
+
+func SetEntryPointValue(c *gin.Context) {
+	var valueRequest ValueRequest
+	if c.Bind(&valueRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			err := ds.SetValue(ctx, entrypoint, valueRequest.Value)
+			sendMessage(c, "Value was set", err)
+		}
+	}
+}
+
+func AddSetMembers(c *gin.Context) {
+	var membersRequest MembersRequest
+	if c.Bind(&membersRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			count, err := ds.AddSetMembers(ctx, entrypoint, membersRequest.Members)
+			sendCount(c, count, err)
+		}
+	}
+}
+
+func RemoveSetMembers(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		members, ok := getRequiredQueryArray(c, "member")
+		if ok {
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			count, err := ds.RemoveSetMembers(ctx, entrypoint, members)
+			sendCount(c, count, err)
+		}
+	}
+}
+
+func AddSortedSetMembers(c *gin.Context) {
+	var scoredMembersRequest ScoredMembersRequest
+	if c.Bind(&scoredMembersRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			count, err := ds.AddSortedSetMembers(ctx, entrypoint, scoredMembersRequest.Members)
+			sendCount(c, count, err)
+		}
+	}
+}
+
+func SetHashFields(c *gin.Context) {
+	var hashFieldsRequest HashFieldsRequest
+	if c.Bind(&hashFieldsRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			err := ds.SetHashFields(ctx, entrypoint, hashFieldsRequest.Fields)
+			sendMessage(c, "Fields were set", err)
+		}
+	}
+}
+
+func DeleteHashFields(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		fields, ok := getRequiredQueryArray(c, "field")
+		if ok {
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			count, err := ds.DeleteHashFields(ctx, entrypoint, fields)
+			sendCount(c, count, err)
+		}
+	}
+}
+
+func PushListValues(c *gin.Context) {
+	var listValuesRequest ListValuesRequest
+	if c.Bind(&listValuesRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			length, err := ds.PushListValues(ctx, entrypoint, listValuesRequest.Values, listValuesRequest.Head)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusOK, gin.H{"length": length})
+			}
+		}
+	}
+}
+
+func SetListValue(c *gin.Context) {
+	index, error := strconv.ParseInt(c.Params.ByName("index"), 10, 64)
+	if error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The index '%s' is not valid", c.Params.ByName("index"))})
+		return
+	}
+	var valueRequest ValueRequest
+	if c.Bind(&valueRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			err := ds.SetListValue(ctx, entrypoint, index, valueRequest.Value)
+			sendMessage(c, "Value was set", err)
+		}
+	}
+}
+
+func RemoveListValue(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		value, exists := c.GetQuery("value")
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The query parameter 'value' is required"})
+			return
+		}
+		count := int64(0)
+		if countParam, exists := c.GetQuery("count"); exists {
+			var error error
+			count, error = strconv.ParseInt(countParam, 10, 64)
+			if error != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The count '%s' is not valid", countParam)})
+				return
+			}
+		}
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		removed, err := ds.RemoveListValue(ctx, entrypoint, value, count)
+		sendCount(c, removed, err)
+	}
+}
+
+func AddStreamMessage(c *gin.Context) {
+	var streamMessageRequest StreamMessageRequest
+	if c.Bind(&streamMessageRequest) == nil {
+		ds, ok := findDataSource(c)
+		if ok {
+			entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			id, err := ds.AddStreamMessage(ctx, entrypoint, streamMessageRequest.Id, streamMessageRequest.Fields)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusOK, gin.H{"id": id})
+			}
+		}
+	}
+}
+
+func DeleteStreamMessages(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		ids, ok := getRequiredQueryArray(c, "id")
+		if ok {
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			count, err := ds.DeleteStreamMessages(ctx, entrypoint, ids)
+			sendCount(c, count, err)
+		}
+	}
+}
+
+// getRequiredQueryArray returns the values of a repeated query parameter, and replies with a bad request when there is none.
+func getRequiredQueryArray(c *gin.Context, name string) ([]string, bool) {
+	values := c.QueryArray(name)
+	if len(values) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At least one query parameter '%s' is required", name)})
+		return nil, false
+	}
+	return values, true
+}
+
+func sendMessage(c *gin.Context, message string, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": message})
+	}
+}
+
+func sendCount(c *gin.Context, count int64, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"count": count})
+	}
+}
+
+// requestContext returns the context of a synchronous operation on the data source, cancelled when the client
+// leaves or after the timeout of the data source.
+func requestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(c, c.Request.Context())
+}
+
+// operationContext returns the context of an operation on the data source whose result can be read later
+// from a web-socket, cancelled after the timeout of the data source or when the client of the web-socket leaves.
+func operationContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(c, context.Background())
+}
+
+func withTimeout(c *gin.Context, parent context.Context) (context.Context, context.CancelFunc) {
+	timeout := registry.Timeout(getDataSourceId(c))
+	if timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+func getDataSourceId(c *gin.Context) datasource.DataSourceId {
+	return datasource.DataSourceId(c.Params.ByName("DataSourceId"))
+}
+
+func findDataSource(c *gin.Context) (datasource.DataSource, bool) {
+	datasourceId := getDataSourceId(c)
+	ds, ok := registry.Get(datasourceId)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("DataSource with UUID %s was not found", datasourceId)})
+	}
+	return ds, ok
+}
+
+func ReadChannelContentAndSendToWebSocket(c *gin.Context) {
+	wsUuid := c.Params.ByName("wsUuid")
+	webSocket, ok := registry.TakeWebSocket(wsUuid)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Web socket channel wuth UUID %s was not found", wsUuid)})
+		return
+	}
+	defer registry.RemoveWebSocket(wsUuid)
+	dataChannel := webSocket.dataChannel
+	errorChannel := webSocket.errorChannel
+	ctx := webSocket.ctx
+	cancel := webSocket.cancel
+	// The operation stops once all its data were sent or when the client leaves.
+	defer cancel()
+
+	conn, err := upgradeToWebSocket(c)
+	if err != nil {
+		return
+	}
+	conn.SetCloseHandler(func(code int, text string) error {
+		log.Printf("Websocket %v closed with code %v and reason %v\n", wsUuid, code, text)
+		return nil
+	})
+
+	// The messages from the client have to be read to be notified of the closing of the web-socket.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	defer func() {
+		go func() {
+			time.Sleep(10 * time.Second)
+			log.Printf("Websocket %v expired\n", wsUuid)
+			conn.Close()
+		}()
+	}()
+
+	log.Printf("Reading channel data for %s\n", wsUuid)
+	frames := frameWriter{conn: conn}
+	if dataChannel != nil {
+		for data := range dataChannel {
+			frames.sendBatch(data)
+		}
+	}
+
+	if errorChannel != nil {
+		for err := range errorChannel {
+			frames.sendError(err)
+		}
+	}
+	// The content is incomplete when the operation was stopped by the timeout or a cancellation,
+	// even if the data source did not report it.
+	if ctx != nil && ctx.Err() != nil && frames.errors == 0 {
+		frames.sendError(ctx.Err())
+	}
+	frames.complete()
+	log.Printf("Stop reading channel data for %s\n", wsUuid)
+}
+
+func upgradeToWebSocket(c *gin.Context) (*websocket.Conn, error) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:    1024,
+		WriteBufferSize:   1024,
+		EnableCompression: true,
+		CheckOrigin:       func(r *http.Request) bool { return true },
+	}
+
+	// Force headers for calls behind reverse-proxy.
+	c.Request.Header.Set("Connection", "upgrade")
+	c.Request.Header.Set("Upgrade", "websocket")
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to set websocket upgrade: %v\n", err)
+	}
+	return conn, err
+}
+
+// ConsumeEntryPoint opens a web-socket and pushes the values of the stream or topic as they arrive,
+// until the client closes the web-socket.
+func ConsumeEntryPoint(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		fromBeginning := getFromBeginning(c)
+		filter, err := getFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		decode, err := contentDecoder(c, entrypoint)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+		_, err = ds.Consume(ctx, entrypoint, dataChannel, filter, fromBeginning)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		streamToWebSocket(c, string(entrypoint), cancel, dataChannel, decode)
+	}
+}
+
+// WatchEntryPoint opens a web-socket and pushes the changes of the entry point and of its children,
+// until the client closes the web-socket.
+func WatchEntryPoint(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		entrypoint := datasource.EntryPoint(c.Params.ByName("entrypoint"))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+		_, err := ds.Watch(ctx, entrypoint, dataChannel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		streamToWebSocket(c, string(entrypoint), cancel, dataChannel, nil)
+	}
+}
+
+// streamToWebSocket upgrades the connection to a web-socket and writes the batches of the data channel until it is closed.
+// The cancel function is called when the client closes the web-socket or when a write fails.
+// The values of the batches are decoded by decode when it is not nil.
+func streamToWebSocket(c *gin.Context, name string, cancel context.CancelFunc, dataChannel chan datasource.DataBatch, decode func(datasource.DataBatch) datasource.DataBatch) {
+	conn, err := upgradeToWebSocket(c)
+	if err != nil {
+		cancel()
+		return
+	}
+	defer conn.Close()
+	wsUuid := registry.AddActiveWebSocket(getDataSourceId(c), c.Request.URL.Path, cancel)
+	defer registry.RemoveWebSocket(wsUuid)
+
+	// The messages from the client have to be read to be notified of the closing of the web-socket.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				log.Printf("Stop streaming %s: %s\n", name, err.Error())
+				return
+			}
+		}
+	}()
+
+	// The channel is closed by the data source once the context is cancelled.
+	frames := frameWriter{conn: conn}
+	for data := range dataChannel {
+		if decode != nil {
+			data = decode(data)
+		}
+		if frames.sendBatch(data) != nil {
+			cancel()
+		}
+	}
+	frames.complete()
+}
+
+// ListChannels returns the active publish/subscribe channels of the data source.
+func ListChannels(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		filter, err := getFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+		ctx, cancel := operationContext(c)
+		status, err := ds.ListChannels(ctx, filter, dataChannel)
+		sendDataChannel(c, status, err, dataChannel, ctx, cancel, nil)
+	}
+}
+
+// SubscribeChannel opens a web-socket and pushes the messages published to the channel,
+// until the client closes the web-socket. The channel is a glob pattern when the query parameter pattern is true.
+func SubscribeChannel(c *gin.Context) {
+	ds, ok := findDataSource(c)
+	if ok {
+		channel := datasource.EntryPoint(c.Params.ByName("channel"))
+		pattern := false
+		if patternParam, exists := c.GetQuery("pattern"); exists {
+			var err error
+			pattern, err = strconv.ParseBool(patternParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the parameter pattern is not a valid boolean: %s", patternParam)})
+				return
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dataChannel := make(chan datasource.DataBatch, datasource.SwitchToWsBarrier)
+		_, err := ds.Subscribe(ctx, channel, pattern, dataChannel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		streamToWebSocket(c, string(channel), cancel, dataChannel, nil)
+	}
+}
+
+func getFromBeginning(c *gin.Context) bool {
+	fromBeginningParam, exists := c.GetQuery("fromBeginning")
+	if exists {
+		fromBeginning, error := strconv.ParseBool(fromBeginningParam)
+		if error == nil {
+			return fromBeginning
+		}
+	}
+	return false
+}
+
+func splitBatch(dataToSplit datasource.DataBatch) []datasource.DataBatch {
+	if dataToSplit.Size <= webSocketBatchSize {
 		return []datasource.DataBatch{dataToSplit}
 	} else {
 		var result []datasource.DataBatch
@@ -393,7 +1320,9 @@ func ExecuteCommand(c *gin.Context) {
 	if c.Bind(&commandRequest) == nil {
 		ds, ok := findDataSource(c)
 		if ok {
-			message, err := ds.ExecuteCommand(commandRequest.Args, commandRequest.NodeID)
+			ctx, cancel := requestContext(c)
+			defer cancel()
+			message, err := ds.ExecuteCommand(ctx, commandRequest.Args, commandRequest.NodeID)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			} else {
@@ -402,3 +1331,102 @@ func ExecuteCommand(c *gin.Context) {
 		}
 	}
 }
+
+// StartAnalysis starts the analysis of the entry points of the data source in background, with the options
+// of the optional body, and returns it.
+func StartAnalysis(c *gin.Context) {
+	var options datasource.AnalysisOptions
+	if c.Request.ContentLength != 0 && c.Bind(&options) != nil {
+		return
+	}
+	ds, ok := findDataSource(c)
+	if ok {
+		c.JSON(http.StatusAccepted, registry.StartAnalysis(getDataSourceId(c), ds, options))
+	}
+}
+
+// ListAnalyses returns the running and finished analyses of the data source.
+func ListAnalyses(c *gin.Context) {
+	if _, ok := findDataSource(c); ok {
+		c.JSON(http.StatusOK, gin.H{"analyses": registry.Analyses(getDataSourceId(c))})
+	}
+}
+
+// GetAnalysis returns the analysis with its current report.
+func GetAnalysis(c *gin.Context) {
+	if job, ok := findAnalysis(c); ok {
+		c.JSON(http.StatusOK, job.snapshot())
+	}
+}
+
+// CancelAnalysis cancels the analysis if it is running, or removes it once finished.
+func CancelAnalysis(c *gin.Context) {
+	if _, ok := findAnalysis(c); ok {
+		registry.CancelAnalysis(getDataSourceId(c), c.Params.ByName("analysisId"))
+		c.Status(http.StatusAccepted)
+	}
+}
+
+// StreamAnalysis opens a web-socket and sends the report of the analysis as progress frames while it is running,
+// then as a data frame once finished. Closing the web-socket does not stop the analysis.
+func StreamAnalysis(c *gin.Context) {
+	job, ok := findAnalysis(c)
+	if !ok {
+		return
+	}
+	conn, err := upgradeToWebSocket(c)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wsUuid := registry.AddActiveWebSocket(getDataSourceId(c), c.Request.URL.Path, cancel)
+	defer registry.RemoveWebSocket(wsUuid)
+
+	// The messages from the client have to be read to be notified of the closing of the web-socket.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	frames := frameWriter{conn: conn}
+	ticker := time.NewTicker(analysisProgressInterval)
+	defer ticker.Stop()
+	for job.isRunning() {
+		if frames.write(WebSocketFrame{Kind: ProgressFrame, Progress: job.snapshot()}) != nil {
+			return
+		}
+		select {
+		case <-job.done:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	result := job.snapshot()
+	if frames.sendBatch(datasource.DataBatch{Size: 1, Data: []interface{}{result}}) != nil {
+		return
+	}
+	if result.Status == AnalysisFailed {
+		frames.sendError(job.err)
+	}
+	frames.complete()
+}
+
+func findAnalysis(c *gin.Context) (*analysisJob, bool) {
+	if _, ok := findDataSource(c); !ok {
+		return nil, false
+	}
+	analysisId := c.Params.ByName("analysisId")
+	job, ok := registry.Analysis(getDataSourceId(c), analysisId)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The analysis %s was not found", analysisId)})
+	}
+	return job, ok
+}
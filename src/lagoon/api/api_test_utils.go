@@ -1,8 +1,8 @@
 package api
 
-import "lagoon/datasource"
-
+// For test purpose only: closes and unregisters all the data sources.
 func ClearDatasources() {
-	dataSources = make(map[datasource.DataSourceId]datasource.DataSource)
-	DataSourcesHeaders = make(map[datasource.DataSourceId]DataSourceHeader)
+	registry.CloseAll()
+	registry = NewRegistry()
+	deletionConfirmations = make(map[string]deletionConfirmation)
 }
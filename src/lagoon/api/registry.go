@@ -0,0 +1,476 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/twinj/uuid"
+	"lagoon/datasource"
+	"lagoon/datasource/decoding"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultPendingWebSocketExpiry is the duration after which the web-sockets whose client did not connect expire.
+const DefaultPendingWebSocketExpiry = time.Minute
+
+// DataSourceHooks are notified of the lifecycle of the data sources of a registry, all of them are optional.
+// They are called after the change, outside of the lock of the registry.
+type DataSourceHooks struct {
+	// OnOpen is called once a new data source was opened and registered.
+	OnOpen func(header DataSourceHeader, dataSource datasource.DataSource)
+	// OnReplace is called once a data source replaced the previous one with the same ID, before the previous one is closed.
+	OnReplace func(previous DataSourceHeader, header DataSourceHeader, dataSource datasource.DataSource)
+	// OnClose is called once a data source was removed and closed.
+	OnClose func(header DataSourceHeader)
+}
+
+// Registry keeps the opened data sources, the streams of their operations to web-sockets, pending until
+// their client connects, then active, and their analyses. All its functions can be called concurrently.
+type Registry struct {
+	mutex       sync.RWMutex
+	dataSources map[datasource.DataSourceId]registeredDataSource
+	webSockets  map[string]*webSocketChannel
+	analyses    map[string]*analysisJob
+	hooks       []DataSourceHooks
+	// pendingExpiry is the duration after which the pending web-sockets are removed and their operations cancelled.
+	pendingExpiry time.Duration
+}
+
+type registeredDataSource struct {
+	header     DataSourceHeader
+	dataSource datasource.DataSource
+	timeout    time.Duration
+	decoders   *decoding.Decoders
+}
+
+// webSocketChannel is the channel of data or of errors of an operation, to read from a web-socket.
+// Its context is the one of the operation producing the content, which its cancel function stops.
+type webSocketChannel struct {
+	dataSourceId datasource.DataSourceId
+	path         string
+	dataChannel  chan datasource.DataBatch
+	errorChannel chan error
+	ctx          context.Context
+	cancel       context.CancelFunc
+	createdAt    time.Time
+	// connectedAt is zero until the client connects to the web-socket.
+	connectedAt time.Time
+	expiration  *time.Timer
+	// mutation is true when the operation changes the data source: it is not cancelled when the web-socket
+	// expires, to not leave it half-done, and its progress and errors are then logged.
+	mutation bool
+}
+
+// WebSocketStream describes a stream of the registry to a web-socket.
+type WebSocketStream struct {
+	Id           string                  `json:"id"`
+	DataSourceId datasource.DataSourceId `json:"dataSourceId"`
+	// Path is the path of the request which started the stream.
+	Path      string    `json:"path"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Age is the duration since the creation in milliseconds.
+	Age int64 `json:"age"`
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		dataSources:   make(map[datasource.DataSourceId]registeredDataSource),
+		webSockets:    make(map[string]*webSocketChannel),
+		analyses:      make(map[string]*analysisJob),
+		pendingExpiry: DefaultPendingWebSocketExpiry,
+	}
+}
+
+// SetPendingExpiry sets the duration after which the web-sockets whose client did not connect are removed,
+// and their operations cancelled. 0 disables the expiration of the web-sockets registered afterwards.
+func (r *Registry) SetPendingExpiry(expiry time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pendingExpiry = expiry
+}
+
+// AddHooks registers hooks to be notified of the lifecycle of the data sources.
+func (r *Registry) AddHooks(hooks DataSourceHooks) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.hooks = append(r.hooks, hooks)
+}
+
+func (r *Registry) currentHooks() []DataSourceHooks {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.hooks
+}
+
+// Open creates and opens the data source of the descriptor, then registers it.
+// A random ID is generated when the descriptor has none, and the creation fails if the ID is already used.
+func (r *Registry) Open(descriptor datasource.DataSourceDescriptor) (DataSourceHeader, error) {
+	if descriptor.Id == "" {
+		descriptor.Id = uuid.NewV4().String()
+	} else if _, exists := r.Get(datasource.DataSourceId(descriptor.Id)); exists {
+		return DataSourceHeader{}, errors.New(fmt.Sprintf("The datasource with id '%s' already exists", descriptor.Id))
+	}
+	registered, err := createDataSource(descriptor)
+	if err != nil {
+		return DataSourceHeader{}, err
+	}
+
+	r.mutex.Lock()
+	if _, exists := r.dataSources[registered.header.Id]; exists {
+		// Another data source with the same ID was registered meanwhile.
+		r.mutex.Unlock()
+		registered.dataSource.Close()
+		return DataSourceHeader{}, errors.New(fmt.Sprintf("The datasource with id '%s' already exists", descriptor.Id))
+	}
+	r.dataSources[registered.header.Id] = registered
+	r.mutex.Unlock()
+
+	for _, hooks := range r.currentHooks() {
+		if hooks.OnOpen != nil {
+			hooks.OnOpen(registered.header, registered.dataSource)
+		}
+	}
+	return registered.header, nil
+}
+
+// Replace creates and opens the data source of the descriptor, then replaces the registered one with the same ID,
+// which is closed afterwards. The registered data source is kept unchanged when the new one cannot be created.
+func (r *Registry) Replace(descriptor datasource.DataSourceDescriptor) (DataSourceHeader, error) {
+	dataSourceId := datasource.DataSourceId(descriptor.Id)
+	if _, exists := r.Get(dataSourceId); !exists {
+		return DataSourceHeader{}, errors.New(fmt.Sprintf("The datasource with id '%s' does not exists", dataSourceId))
+	}
+	registered, err := createDataSource(descriptor)
+	if err != nil {
+		return DataSourceHeader{}, err
+	}
+
+	r.mutex.Lock()
+	previous, exists := r.dataSources[dataSourceId]
+	if !exists {
+		// The data source was removed meanwhile.
+		r.mutex.Unlock()
+		registered.dataSource.Close()
+		return DataSourceHeader{}, errors.New(fmt.Sprintf("The datasource with id '%s' does not exists", dataSourceId))
+	}
+	r.dataSources[dataSourceId] = registered
+	webSockets := r.removeWebSockets(dataSourceId)
+	analyses := r.removeAnalyses(dataSourceId)
+	r.mutex.Unlock()
+
+	for _, hooks := range r.currentHooks() {
+		if hooks.OnReplace != nil {
+			hooks.OnReplace(previous.header, registered.header, registered.dataSource)
+		}
+	}
+	for _, webSocket := range webSockets {
+		webSocket.stop()
+	}
+	for _, analysis := range analyses {
+		analysis.cancel()
+	}
+	previous.dataSource.Close()
+	carryOverTrash(previous.dataSource, registered.dataSource)
+	return registered.header, nil
+}
+
+// carryOverTrash moves the backups of the replaced data source to the trash of the new one,
+// so that they can still be restored after a change of the configuration.
+func carryOverTrash(previous datasource.DataSource, replacement datasource.DataSource) {
+	previousKeeper, ok := previous.(datasource.TrashKeeper)
+	if !ok || previousKeeper.Trash() == nil {
+		return
+	}
+	replacementKeeper, ok := replacement.(datasource.TrashKeeper)
+	if !ok || replacementKeeper.Trash() == nil {
+		log.Printf("The trash of the data source is disabled, its previous backups are dropped\n")
+		return
+	}
+	replacementKeeper.Trash().TakeOver(previousKeeper.Trash())
+}
+
+// Remove unregisters and closes the data source, and returns false if it does not exist.
+func (r *Registry) Remove(dataSourceId datasource.DataSourceId) bool {
+	r.mutex.Lock()
+	registered, exists := r.dataSources[dataSourceId]
+	if !exists {
+		r.mutex.Unlock()
+		return false
+	}
+	delete(r.dataSources, dataSourceId)
+	webSockets := r.removeWebSockets(dataSourceId)
+	analyses := r.removeAnalyses(dataSourceId)
+	r.mutex.Unlock()
+
+	r.close(registered, webSockets, analyses)
+	return true
+}
+
+// CloseAll unregisters and closes all the data sources.
+func (r *Registry) CloseAll() {
+	r.mutex.Lock()
+	dataSources := r.dataSources
+	webSockets := r.webSockets
+	analyses := r.analyses
+	r.dataSources = make(map[datasource.DataSourceId]registeredDataSource)
+	r.webSockets = make(map[string]*webSocketChannel)
+	r.analyses = make(map[string]*analysisJob)
+	r.mutex.Unlock()
+
+	for _, webSocket := range webSockets {
+		webSocket.stop()
+	}
+	for _, analysis := range analyses {
+		analysis.cancel()
+	}
+	for _, registered := range dataSources {
+		r.close(registered, nil, nil)
+	}
+}
+
+func (r *Registry) close(registered registeredDataSource, webSockets []*webSocketChannel, analyses []*analysisJob) {
+	for _, webSocket := range webSockets {
+		webSocket.stop()
+	}
+	for _, analysis := range analyses {
+		analysis.cancel()
+	}
+	registered.dataSource.Close()
+	for _, hooks := range r.currentHooks() {
+		if hooks.OnClose != nil {
+			hooks.OnClose(registered.header)
+		}
+	}
+}
+
+// Get returns the registered data source.
+func (r *Registry) Get(dataSourceId datasource.DataSourceId) (datasource.DataSource, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	registered, exists := r.dataSources[dataSourceId]
+	return registered.dataSource, exists
+}
+
+// Timeout returns the duration after which the operations on the data source are cancelled, 0 when they are not.
+func (r *Registry) Timeout(dataSourceId datasource.DataSourceId) time.Duration {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.dataSources[dataSourceId].timeout
+}
+
+// Decoders returns the decoders of the values of the data source.
+func (r *Registry) Decoders(dataSourceId datasource.DataSourceId) *decoding.Decoders {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.dataSources[dataSourceId].decoders
+}
+
+// Headers returns the headers of the registered data sources, sorted by name.
+func (r *Registry) Headers() []DataSourceHeader {
+	r.mutex.RLock()
+	headers := []DataSourceHeader{}
+	for _, registered := range r.dataSources {
+		headers = append(headers, registered.header)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Name < headers[j].Name
+	})
+	return headers
+}
+
+// AddWebSocket registers the channel of data or of errors of an operation on the data source, started by the request
+// with the path and running with the context, and returns the UUID of the pending web-socket to read it from.
+func (r *Registry) AddWebSocket(dataSourceId datasource.DataSourceId, path string, dataChannel chan datasource.DataBatch, errorChannel chan error, ctx context.Context, cancel context.CancelFunc) string {
+	return r.addPendingWebSocket(&webSocketChannel{
+		dataSourceId: dataSourceId,
+		path:         path,
+		dataChannel:  dataChannel,
+		errorChannel: errorChannel,
+		ctx:          ctx,
+		cancel:       cancel,
+	})
+}
+
+// AddMutationWebSocket registers the channel of progress or of errors of an operation changing the data source,
+// like AddWebSocket. The operation is not cancelled if the pending web-socket expires, but keeps running until
+// it is complete, it is only cancelled when the client of the web-socket leaves or the data source is closed.
+func (r *Registry) AddMutationWebSocket(dataSourceId datasource.DataSourceId, path string, progressChannel chan datasource.DataBatch, errorChannel chan error, ctx context.Context, cancel context.CancelFunc) string {
+	return r.addPendingWebSocket(&webSocketChannel{
+		dataSourceId: dataSourceId,
+		path:         path,
+		dataChannel:  progressChannel,
+		errorChannel: errorChannel,
+		ctx:          ctx,
+		cancel:       cancel,
+		mutation:     true,
+	})
+}
+
+func (r *Registry) addPendingWebSocket(webSocket *webSocketChannel) string {
+	wsUuid := uuid.NewV4().String()
+	webSocket.createdAt = time.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.pendingExpiry > 0 {
+		webSocket.expiration = time.AfterFunc(r.pendingExpiry, func() {
+			r.expire(wsUuid)
+		})
+	}
+	r.webSockets[wsUuid] = webSocket
+	return wsUuid
+}
+
+// AddActiveWebSocket registers the stream of an operation on the data source, whose client is already connected,
+// and returns its UUID.
+func (r *Registry) AddActiveWebSocket(dataSourceId datasource.DataSourceId, path string, cancel context.CancelFunc) string {
+	wsUuid := uuid.NewV4().String()
+	now := time.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.webSockets[wsUuid] = &webSocketChannel{
+		dataSourceId: dataSourceId,
+		path:         path,
+		cancel:       cancel,
+		createdAt:    now,
+		connectedAt:  now,
+	}
+	return wsUuid
+}
+
+// TakeWebSocket marks the pending web-socket as active and returns its channel, which can be read only once.
+func (r *Registry) TakeWebSocket(wsUuid string) (webSocketChannel, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	webSocket, exists := r.webSockets[wsUuid]
+	if !exists || !webSocket.connectedAt.IsZero() {
+		return webSocketChannel{}, false
+	}
+	if webSocket.expiration != nil {
+		webSocket.expiration.Stop()
+	}
+	webSocket.connectedAt = time.Now()
+	return *webSocket, true
+}
+
+// RemoveWebSocket unregisters the web-socket once its stream is over.
+func (r *Registry) RemoveWebSocket(wsUuid string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.webSockets, wsUuid)
+}
+
+// WebSockets returns the pending and active streams to web-sockets, from the oldest to the newest.
+func (r *Registry) WebSockets() []WebSocketStream {
+	now := time.Now()
+	streams := []WebSocketStream{}
+	r.mutex.RLock()
+	for wsUuid, webSocket := range r.webSockets {
+		streams = append(streams, WebSocketStream{
+			Id:           wsUuid,
+			DataSourceId: webSocket.dataSourceId,
+			Path:         webSocket.path,
+			Active:       !webSocket.connectedAt.IsZero(),
+			CreatedAt:    webSocket.createdAt,
+			Age:          int64(now.Sub(webSocket.createdAt) / time.Millisecond),
+		})
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].CreatedAt.Before(streams[j].CreatedAt)
+	})
+	return streams
+}
+
+// expire removes the web-socket if its client did not connect yet, and cancels its operation unless it is a mutation.
+func (r *Registry) expire(wsUuid string) {
+	r.mutex.Lock()
+	webSocket, exists := r.webSockets[wsUuid]
+	if !exists || !webSocket.connectedAt.IsZero() {
+		r.mutex.Unlock()
+		return
+	}
+	delete(r.webSockets, wsUuid)
+	r.mutex.Unlock()
+
+	if webSocket.mutation {
+		log.Printf("Web socket %s of the data source %s expired before its client connected, the operation %s goes on\n", wsUuid, webSocket.dataSourceId, webSocket.path)
+		go webSocket.drain(wsUuid)
+		return
+	}
+	log.Printf("Web socket %s of the data source %s expired before its client connected\n", wsUuid, webSocket.dataSourceId)
+	webSocket.cancel()
+}
+
+// drain reads the channels of the operation until it is complete, so that it is not blocked, and logs its errors.
+func (w *webSocketChannel) drain(wsUuid string) {
+	if w.dataChannel != nil {
+		for batch := range w.dataChannel {
+			if batch.Error != nil {
+				log.Printf("ERROR in the operation of the expired web socket %s: %s\n", wsUuid, batch.Error.Error())
+			}
+		}
+	}
+	if w.errorChannel != nil {
+		for err := range w.errorChannel {
+			log.Printf("ERROR in the operation of the expired web socket %s: %s\n", wsUuid, err.Error())
+		}
+	}
+	log.Printf("The operation %s of the expired web socket %s is complete\n", w.path, wsUuid)
+	w.cancel()
+}
+
+// stop cancels the operation of the web-socket and its expiration.
+func (w *webSocketChannel) stop() {
+	if w.expiration != nil {
+		w.expiration.Stop()
+	}
+	w.cancel()
+}
+
+// removeWebSockets unregisters the pending and active web-sockets of the data source, and returns them
+// to be stopped. The lock has to be held by the caller.
+func (r *Registry) removeWebSockets(dataSourceId datasource.DataSourceId) []*webSocketChannel {
+	var webSockets []*webSocketChannel
+	for wsUuid, webSocket := range r.webSockets {
+		if webSocket.dataSourceId == dataSourceId {
+			webSockets = append(webSockets, webSocket)
+			delete(r.webSockets, wsUuid)
+		}
+	}
+	return webSockets
+}
+
+func createDataSource(descriptor datasource.DataSourceDescriptor) (registeredDataSource, error) {
+	log.Printf("Creating data source %v\n", descriptor)
+	timeout, err := datasource.TimeoutFromConfiguration(descriptor.Configuration)
+	if err != nil {
+		return registeredDataSource{}, err
+	}
+	decoders, err := decoding.FromConfiguration(descriptor.Configuration)
+	if err != nil {
+		return registeredDataSource{}, err
+	}
+	dataSource, err := datasource.CreateDataSource(&descriptor)
+	if err != nil {
+		return registeredDataSource{}, err
+	}
+	return registeredDataSource{
+		header: DataSourceHeader{
+			Id:          datasource.DataSourceId(descriptor.Id),
+			Vendor:      descriptor.Vendor,
+			Name:        descriptor.Name,
+			Description: descriptor.Description,
+			ReadOnly:    descriptor.ReadOnly,
+		},
+		dataSource: dataSource,
+		timeout:    timeout,
+		decoders:   decoders,
+	}, nil
+}
@@ -0,0 +1,363 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"lagoon/datasource"
+	"sync"
+	"testing"
+	"time"
+)
+
+func declareMockVendor(ctrl *gomock.Controller, dataSources ...datasource.DataSource) {
+	vendor := datasource.NewMockVendor(ctrl)
+	vendor.EXPECT().Accept(gomock.Any()).Return(true).AnyTimes()
+	for _, ds := range dataSources {
+		vendor.EXPECT().CreateDataSource(gomock.Any()).Return(ds, nil).Times(1)
+	}
+	datasource.DeclareImplementation(vendor)
+}
+
+func TestRegistry_Open(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer func() {
+		datasource.ClearVendors()
+		ctrl.Finish()
+	}()
+	ds := datasource.NewMockDataSource(ctrl)
+	ds.EXPECT().Open().Return(nil).Times(1)
+	declareMockVendor(ctrl, ds)
+	registry := NewRegistry()
+	var opened []DataSourceHeader
+	registry.AddHooks(DataSourceHooks{OnOpen: func(header DataSourceHeader, dataSource datasource.DataSource) {
+		opened = append(opened, header)
+	}})
+
+	// when
+	header, err := registry.Open(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock", Configuration: map[string]string{"timeout": "1m"}})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, DataSourceHeader{Id: "my-datasource", Vendor: "mock", Name: "test-mock"}, header)
+	assert.Equal(t, []DataSourceHeader{header}, opened)
+	assert.Equal(t, []DataSourceHeader{header}, registry.Headers())
+	result, exists := registry.Get("my-datasource")
+	assert.True(t, exists)
+	assert.True(t, result == ds)
+	assert.Equal(t, "1m0s", registry.Timeout("my-datasource").String())
+
+	// when
+	_, err = registry.Open(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "other-mock"})
+
+	// then
+	assert.EqualError(t, err, "The datasource with id 'my-datasource' already exists")
+	assert.Len(t, opened, 1)
+}
+
+func TestRegistry_Replace(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer func() {
+		datasource.ClearVendors()
+		ctrl.Finish()
+	}()
+	previous := datasource.NewMockDataSource(ctrl)
+	replacement := datasource.NewMockDataSource(ctrl)
+	// The previous data source is only closed once the replacement was opened.
+	gomock.InOrder(
+		previous.EXPECT().Open().Return(nil).Times(1),
+		replacement.EXPECT().Open().Return(nil).Times(1),
+		previous.EXPECT().Close().Times(1),
+	)
+	declareMockVendor(ctrl, previous, replacement)
+	registry := NewRegistry()
+	_, err := registry.Open(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock"})
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	wsUuid := registry.AddWebSocket("my-datasource", "/data/my-datasource/entrypoint", make(chan datasource.DataBatch), nil, ctx, cancel)
+	var replaced []DataSourceHeader
+	registry.AddHooks(DataSourceHooks{OnReplace: func(previous DataSourceHeader, header DataSourceHeader, dataSource datasource.DataSource) {
+		replaced = append(replaced, previous, header)
+	}})
+
+	// when
+	header, err := registry.Replace(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "new-mock"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []DataSourceHeader{{Id: "my-datasource", Vendor: "mock", Name: "test-mock"}, header}, replaced)
+	result, _ := registry.Get("my-datasource")
+	assert.True(t, result == replacement)
+	// The operations on the previous data source which were not read yet are cancelled.
+	assert.NotNil(t, ctx.Err())
+	_, exists := registry.TakeWebSocket(wsUuid)
+	assert.False(t, exists)
+}
+
+func TestRegistry_ReplaceWhenTheNewDataSourceCannotBeOpened(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer func() {
+		datasource.ClearVendors()
+		ctrl.Finish()
+	}()
+	previous := datasource.NewMockDataSource(ctrl)
+	replacement := datasource.NewMockDataSource(ctrl)
+	previous.EXPECT().Open().Return(nil).Times(1)
+	previous.EXPECT().Close().Times(0)
+	replacement.EXPECT().Open().Return(errors.New("connection refused")).Times(1)
+	declareMockVendor(ctrl, previous, replacement)
+	registry := NewRegistry()
+	_, err := registry.Open(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock"})
+	assert.Nil(t, err)
+
+	// when
+	_, err = registry.Replace(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "new-mock"})
+
+	// then
+	assert.EqualError(t, err, "connection refused")
+	result, _ := registry.Get("my-datasource")
+	assert.True(t, result == previous)
+	assert.Equal(t, "test-mock", registry.Headers()[0].Name)
+
+	// when
+	_, err = registry.Replace(datasource.DataSourceDescriptor{Id: "other-datasource", Vendor: "mock", Name: "new-mock"})
+
+	// then
+	assert.EqualError(t, err, "The datasource with id 'other-datasource' does not exists")
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer func() {
+		datasource.ClearVendors()
+		ctrl.Finish()
+	}()
+	ds := datasource.NewMockDataSource(ctrl)
+	ds.EXPECT().Open().Return(nil).Times(1)
+	ds.EXPECT().Close().Times(1)
+	declareMockVendor(ctrl, ds)
+	registry := NewRegistry()
+	header, _ := registry.Open(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock"})
+	var closed []DataSourceHeader
+	registry.AddHooks(DataSourceHooks{OnClose: func(header DataSourceHeader) {
+		closed = append(closed, header)
+	}})
+
+	// when
+	removed := registry.Remove("my-datasource")
+
+	// then
+	assert.True(t, removed)
+	assert.Equal(t, []DataSourceHeader{header}, closed)
+	assert.Empty(t, registry.Headers())
+	assert.False(t, registry.Remove("my-datasource"))
+}
+
+func TestRegistry_ConcurrentAccesses(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer func() {
+		datasource.ClearVendors()
+		ctrl.Finish()
+	}()
+	var dataSources []datasource.DataSource
+	for i := 0; i < 20; i++ {
+		ds := datasource.NewMockDataSource(ctrl)
+		ds.EXPECT().Open().Return(nil).Times(1)
+		ds.EXPECT().Close().Times(1)
+		dataSources = append(dataSources, ds)
+	}
+	declareMockVendor(ctrl, dataSources...)
+	registry := NewRegistry()
+
+	// when
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("datasource-%d", i)
+			_, err := registry.Open(datasource.DataSourceDescriptor{Id: id, Vendor: "mock", Name: id})
+			assert.Nil(t, err)
+			wsUuid := registry.AddWebSocket(datasource.DataSourceId(id), "/data/"+id+"/entrypoint", make(chan datasource.DataBatch), nil, context.Background(), func() {})
+			registry.Headers()
+			registry.WebSockets()
+			_, exists := registry.TakeWebSocket(wsUuid)
+			assert.True(t, exists)
+			registry.RemoveWebSocket(wsUuid)
+			_, exists = registry.Get(datasource.DataSourceId(id))
+			assert.True(t, exists)
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	assert.Len(t, registry.Headers(), 20)
+	registry.CloseAll()
+	assert.Empty(t, registry.Headers())
+}
+
+func TestRegistry_WebSockets(t *testing.T) {
+	// given
+	registry := NewRegistry()
+	pendingCtx, pendingCancel := context.WithCancel(context.Background())
+	pendingUuid := registry.AddWebSocket("my-datasource", "/data/my-datasource/entrypoint", make(chan datasource.DataBatch), nil, pendingCtx, pendingCancel)
+	activeUuid := registry.AddActiveWebSocket("other-datasource", "/data/other-datasource/entrypoint/my-stream/consume", func() {})
+
+	// when
+	streams := registry.WebSockets()
+
+	// then
+	assert.Len(t, streams, 2)
+	assert.Equal(t, pendingUuid, streams[0].Id)
+	assert.Equal(t, datasource.DataSourceId("my-datasource"), streams[0].DataSourceId)
+	assert.Equal(t, "/data/my-datasource/entrypoint", streams[0].Path)
+	assert.False(t, streams[0].Active)
+	assert.Equal(t, activeUuid, streams[1].Id)
+	assert.Equal(t, datasource.DataSourceId("other-datasource"), streams[1].DataSourceId)
+	assert.True(t, streams[1].Active)
+
+	// when
+	_, taken := registry.TakeWebSocket(pendingUuid)
+	_, takenTwice := registry.TakeWebSocket(pendingUuid)
+
+	// then
+	assert.True(t, taken)
+	assert.False(t, takenTwice)
+	assert.True(t, registry.WebSockets()[0].Active)
+	assert.Nil(t, pendingCtx.Err())
+
+	// when
+	registry.RemoveWebSocket(pendingUuid)
+	registry.RemoveWebSocket(activeUuid)
+
+	// then
+	assert.Empty(t, registry.WebSockets())
+}
+
+func TestRegistry_PendingWebSocketExpires(t *testing.T) {
+	// given
+	registry := NewRegistry()
+	registry.SetPendingExpiry(50 * time.Millisecond)
+	expiredCtx, expiredCancel := context.WithCancel(context.Background())
+	connectedCtx, connectedCancel := context.WithCancel(context.Background())
+	defer connectedCancel()
+	expiredUuid := registry.AddWebSocket("my-datasource", "/data/my-datasource/entrypoint", make(chan datasource.DataBatch), nil, expiredCtx, expiredCancel)
+	connectedUuid := registry.AddWebSocket("my-datasource", "/data/my-datasource/entrypoint", make(chan datasource.DataBatch), nil, connectedCtx, connectedCancel)
+	_, taken := registry.TakeWebSocket(connectedUuid)
+	assert.True(t, taken)
+
+	// when
+	<-expiredCtx.Done()
+
+	// then
+	_, exists := registry.TakeWebSocket(expiredUuid)
+	assert.False(t, exists)
+	streams := registry.WebSockets()
+	assert.Len(t, streams, 1)
+	assert.Equal(t, connectedUuid, streams[0].Id)
+	assert.Nil(t, connectedCtx.Err())
+}
+
+func TestRegistry_PendingMutationWebSocketExpiresWithoutCancellingTheOperation(t *testing.T) {
+	// given
+	registry := NewRegistry()
+	registry.SetPendingExpiry(50 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	progressChannel := make(chan datasource.DataBatch)
+	completed := make(chan int)
+	// The mutation sends its progress without buffer, and stops if it is cancelled.
+	go func() {
+		defer close(progressChannel)
+		steps := 0
+		for ; steps < 5; steps++ {
+			select {
+			case <-ctx.Done():
+				completed <- steps
+				return
+			case progressChannel <- datasource.DataBatch{Progress: steps}:
+				time.Sleep(20 * time.Millisecond)
+			}
+		}
+		completed <- steps
+	}()
+
+	// when
+	wsUuid := registry.AddMutationWebSocket("my-datasource", "/data/my-datasource/entrypoint/my-entrypoint/children", progressChannel, nil, ctx, cancel)
+
+	// then
+	assert.Equal(t, 5, <-completed)
+	_, exists := registry.TakeWebSocket(wsUuid)
+	assert.False(t, exists)
+	assert.Empty(t, registry.WebSockets())
+}
+
+func TestRegistry_Analyses(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer func() {
+		datasource.ClearVendors()
+		ctrl.Finish()
+	}()
+	ds := datasource.NewMockDataSource(ctrl)
+	ds.EXPECT().Open().Return(nil).Times(1)
+	ds.EXPECT().Close().Times(1)
+	started := make(chan struct{})
+	ds.EXPECT().AnalyzeEntryPoints(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, analysis *datasource.Analysis) error {
+		analysis.Add(datasource.KeySample{Key: "my-key", Type: "VALUE", MemoryUsage: 64}, []string{"my-key"}, ":")
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}).Times(1)
+	ds.EXPECT().AnalyzeEntryPoints(gomock.Any(), gomock.Any()).Return(errors.New("the analysis failed")).Times(1)
+	declareMockVendor(ctrl, ds)
+	registry := NewRegistry()
+	registry.Open(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock"})
+
+	// when
+	running := registry.StartAnalysis("my-datasource", ds, datasource.AnalysisOptions{Top: 5})
+	<-started
+
+	// then
+	assert.Equal(t, AnalysisRunning, running.Status)
+	assert.Equal(t, 5, running.Options.Top)
+	job, exists := registry.Analysis("my-datasource", running.Id)
+	assert.True(t, exists)
+	assert.Equal(t, uint64(1), job.snapshot().Report.Total.Keys)
+	_, exists = registry.Analysis("other-datasource", running.Id)
+	assert.False(t, exists)
+
+	// when
+	failed := registry.StartAnalysis("my-datasource", ds, datasource.AnalysisOptions{})
+	failedJob, _ := registry.Analysis("my-datasource", failed.Id)
+	<-failedJob.done
+
+	// then
+	analyses := registry.Analyses("my-datasource")
+	assert.Len(t, analyses, 2)
+	assert.Equal(t, running.Id, analyses[0].Id)
+	assert.Equal(t, AnalysisFailed, analyses[1].Status)
+	assert.Equal(t, "the analysis failed", analyses[1].Error)
+	assert.NotNil(t, analyses[1].FinishedAt)
+
+	// when
+	assert.True(t, registry.CancelAnalysis("my-datasource", failed.Id))
+
+	// then
+	assert.Len(t, registry.Analyses("my-datasource"), 1)
+
+	// when
+	registry.Remove("my-datasource")
+
+	// then
+	<-job.done
+	assert.Equal(t, AnalysisCancelled, job.snapshot().Status)
+	assert.Empty(t, registry.Analyses("my-datasource"))
+	assert.False(t, registry.CancelAnalysis("my-datasource", running.Id))
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/gorilla/websocket"
+	"lagoon/datasource"
+	"log"
+)
+
+// Kinds of the frames sent to the web-sockets.
+const (
+	DataFrame       = "data"
+	ProgressFrame   = "progress"
+	ErrorFrame      = "error"
+	CompletionFrame = "completion"
+)
+
+// WebSocketFrame is the message sent to a web-socket. Its kind tells which of the other fields is set.
+type WebSocketFrame struct {
+	Kind       string               `json:"kind"`
+	Size       uint64               `json:"size,omitempty"`
+	Data       []interface{}        `json:"data,omitempty"`
+	Progress   interface{}          `json:"progress,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Completion *WebSocketCompletion `json:"completion,omitempty"`
+}
+
+// WebSocketCompletion is the last frame of a web-socket, with the totals of the operation.
+type WebSocketCompletion struct {
+	Total  uint64 `json:"total"`
+	Errors int    `json:"errors"`
+}
+
+// frameWriter sends the frames to a web-socket and counts the values and errors for the completion.
+type frameWriter struct {
+	conn   *websocket.Conn
+	total  uint64
+	errors int
+}
+
+// sendBatch sends a batch of a data channel as one or several frames, depending on its content and size.
+func (w *frameWriter) sendBatch(batch datasource.DataBatch) error {
+	if batch.Error != nil {
+		return w.sendError(batch.Error)
+	}
+	if batch.Progress != nil {
+		return w.write(WebSocketFrame{Kind: ProgressFrame, Progress: batch.Progress})
+	}
+	for _, dataItem := range splitBatch(batch) {
+		w.total += dataItem.Size
+		if err := w.write(WebSocketFrame{Kind: DataFrame, Size: dataItem.Size, Data: dataItem.Data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *frameWriter) sendError(err error) error {
+	w.errors++
+	return w.write(WebSocketFrame{Kind: ErrorFrame, Error: err.Error()})
+}
+
+// complete sends the last frame, once all the data and errors were sent.
+func (w *frameWriter) complete() error {
+	return w.write(WebSocketFrame{Kind: CompletionFrame, Completion: &WebSocketCompletion{Total: w.total, Errors: w.errors}})
+}
+
+func (w *frameWriter) write(frame WebSocketFrame) error {
+	err := w.conn.WriteJSON(frame)
+	if err != nil {
+		log.Printf("ERROR while sending a frame of kind %s: %s\n", frame.Kind, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,289 @@
+package datasource
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Default count of largest keys and prefixes kept in the report of an analysis.
+const DefaultAnalysisTop = 20
+
+// Default count of levels of the tree making the prefixes of an analysis.
+const DefaultAnalysisPrefixLevels = 1
+
+// Maximal count of prefixes aggregated by an analysis, above which the smallest half of them is dropped.
+const MaxAnalysisPrefixes = 10000
+
+// AnalysisOptions configures the analysis of the entry points of a data source.
+type AnalysisOptions struct {
+	// Filter is the glob pattern of the analyzed keys, all of them by default.
+	Filter string `json:"filter"`
+	// Top is the count of largest keys and prefixes kept in the report.
+	Top int `json:"top"`
+	// PrefixLevels is the count of levels of the tree whose keys are aggregated as a prefix.
+	PrefixLevels uint `json:"prefixLevels"`
+	// Samples is the count of elements of the collections sampled to estimate their memory usage,
+	// 0 keeping the default of the server.
+	Samples int `json:"samples"`
+}
+
+// KeySample is an entry point measured by an analysis.
+type KeySample struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	MemoryUsage int64  `json:"memoryUsage"`
+	Length      uint64 `json:"length"`
+	// HasTimeToLive is false when the entry point does not expire.
+	HasTimeToLive bool `json:"hasTimeToLive"`
+}
+
+// KeysUsage is the count of keys of a group and the memory they use together.
+type KeysUsage struct {
+	Keys        uint64 `json:"keys"`
+	MemoryUsage int64  `json:"memoryUsage"`
+}
+
+// PrefixUsage is the usage of the keys starting with a prefix.
+type PrefixUsage struct {
+	Prefix string `json:"prefix"`
+	KeysUsage
+}
+
+// AnalysisReport is the result of an analysis, complete or not.
+type AnalysisReport struct {
+	Progress ScanProgress `json:"progress"`
+	Total    KeysUsage    `json:"total"`
+	// LargestKeys are the keys using the most memory, the largest first.
+	LargestKeys []KeySample `json:"largestKeys"`
+	// LargestPrefixes are the prefixes whose keys use the most memory, the largest first.
+	LargestPrefixes []PrefixUsage `json:"largestPrefixes"`
+	// PrefixesTruncated is true when there were too many prefixes to aggregate them all: the smallest ones
+	// were dropped on the way, so the usage of the largest prefixes may be underestimated.
+	PrefixesTruncated bool `json:"prefixesTruncated,omitempty"`
+	// Types is the usage of the keys by type.
+	Types map[string]KeysUsage `json:"types"`
+	// WithoutTimeToLive is the usage of the keys which do not expire.
+	WithoutTimeToLive KeysUsage `json:"withoutTimeToLive"`
+	// LargestKeysWithoutTimeToLive are the keys which do not expire and use the most memory, the largest first.
+	LargestKeysWithoutTimeToLive []KeySample `json:"largestKeysWithoutTimeToLive"`
+}
+
+// Analysis aggregates the samples of the keys of a data source into a report, which can be read
+// while the data source is being analyzed. All its functions can be called concurrently.
+type Analysis struct {
+	options                      AnalysisOptions
+	progress                     ScanProgress
+	total                        KeysUsage
+	largestKeys                  []KeySample
+	prefixes                     map[string]*KeysUsage
+	maxPrefixes                  int
+	prefixesTruncated            bool
+	types                        map[string]*KeysUsage
+	withoutTimeToLive            KeysUsage
+	largestKeysWithoutTimeToLive []KeySample
+	// final is the report of the finished analysis, whose aggregations were dropped.
+	final *AnalysisReport
+	mutex sync.Mutex
+}
+
+// NewAnalysis creates an empty analysis, with the default values of the options which are not set.
+func NewAnalysis(options AnalysisOptions) *Analysis {
+	if options.Filter == "" {
+		options.Filter = "*"
+	}
+	if options.Top <= 0 {
+		options.Top = DefaultAnalysisTop
+	}
+	if options.PrefixLevels == 0 {
+		options.PrefixLevels = DefaultAnalysisPrefixLevels
+	}
+	if options.Samples < 0 {
+		options.Samples = 0
+	}
+	return &Analysis{
+		options:     options,
+		prefixes:    make(map[string]*KeysUsage),
+		maxPrefixes: MaxAnalysisPrefixes,
+		types:       make(map[string]*KeysUsage),
+		progress:    ScanProgress{Nodes: 1},
+	}
+}
+
+// Options returns the options of the analysis, with their default values.
+func (a *Analysis) Options() AnalysisOptions {
+	return a.options
+}
+
+// SetNodes sets the count of nodes to analyze.
+func (a *Analysis) SetNodes(nodes int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.progress.Nodes = nodes
+}
+
+// Scanned records the count of keys scanned on a node, and whether the node was completely analyzed.
+func (a *Analysis) Scanned(count int, nodeDone bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.progress.ScannedKeys += uint64(count)
+	if nodeDone {
+		a.progress.ScannedNodes++
+	}
+}
+
+// Add aggregates the sample of a key, whose levels in the tree of the entry points are tokens.
+func (a *Analysis) Add(sample KeySample, tokens []string, separator string) {
+	prefix := prefixOf(tokens, a.options.PrefixLevels, separator)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.final != nil {
+		return
+	}
+	a.total.add(sample)
+	a.largestKeys = insertLargest(a.largestKeys, sample, a.options.Top)
+	if prefix != "" {
+		usage, exists := a.prefixes[prefix]
+		if !exists {
+			if len(a.prefixes) >= a.maxPrefixes {
+				a.dropSmallestPrefixes()
+			}
+			usage = &KeysUsage{}
+			a.prefixes[prefix] = usage
+		}
+		usage.add(sample)
+	}
+	usage, exists := a.types[sample.Type]
+	if !exists {
+		usage = &KeysUsage{}
+		a.types[sample.Type] = usage
+	}
+	usage.add(sample)
+	if !sample.HasTimeToLive {
+		a.withoutTimeToLive.add(sample)
+		a.largestKeysWithoutTimeToLive = insertLargest(a.largestKeysWithoutTimeToLive, sample, a.options.Top)
+	}
+}
+
+// Report returns the current state of the analysis.
+func (a *Analysis) Report() AnalysisReport {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.final != nil {
+		return a.final.copy()
+	}
+	return a.report()
+}
+
+// Finish builds the last report of the analysis, once no more samples are added, and drops the aggregations
+// which are no longer needed.
+func (a *Analysis) Finish() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.final != nil {
+		return
+	}
+	report := a.report()
+	a.final = &report
+	a.prefixes = nil
+	a.types = nil
+}
+
+// report builds the report from the aggregations. The mutex has to be acquired by the caller.
+func (a *Analysis) report() AnalysisReport {
+	report := AnalysisReport{
+		Progress:                     a.progress,
+		Total:                        a.total,
+		LargestKeys:                  append([]KeySample{}, a.largestKeys...),
+		LargestPrefixes:              sortedPrefixes(a.prefixes),
+		PrefixesTruncated:            a.prefixesTruncated,
+		Types:                        make(map[string]KeysUsage),
+		WithoutTimeToLive:            a.withoutTimeToLive,
+		LargestKeysWithoutTimeToLive: append([]KeySample{}, a.largestKeysWithoutTimeToLive...),
+	}
+	if len(report.LargestPrefixes) > a.options.Top {
+		report.LargestPrefixes = report.LargestPrefixes[:a.options.Top]
+	}
+	for entryPointType, usage := range a.types {
+		report.Types[entryPointType] = *usage
+	}
+	return report
+}
+
+// dropSmallestPrefixes removes the half of the prefixes using the least memory, to bound the memory of the analysis.
+// The mutex has to be acquired by the caller.
+func (a *Analysis) dropSmallestPrefixes() {
+	prefixes := sortedPrefixes(a.prefixes)
+	for _, prefix := range prefixes[len(prefixes)-len(prefixes)/2:] {
+		delete(a.prefixes, prefix.Prefix)
+	}
+	a.prefixesTruncated = true
+}
+
+// sortedPrefixes returns the usages of the prefixes, the largest first.
+func sortedPrefixes(prefixes map[string]*KeysUsage) []PrefixUsage {
+	result := []PrefixUsage{}
+	for prefix, usage := range prefixes {
+		result = append(result, PrefixUsage{Prefix: prefix, KeysUsage: *usage})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MemoryUsage != result[j].MemoryUsage {
+			return result[i].MemoryUsage > result[j].MemoryUsage
+		}
+		return result[i].Prefix < result[j].Prefix
+	})
+	return result
+}
+
+// copy returns a copy of the report, which can be changed without changing this one.
+func (r AnalysisReport) copy() AnalysisReport {
+	result := r
+	result.LargestKeys = append([]KeySample{}, r.LargestKeys...)
+	result.LargestPrefixes = append([]PrefixUsage{}, r.LargestPrefixes...)
+	result.LargestKeysWithoutTimeToLive = append([]KeySample{}, r.LargestKeysWithoutTimeToLive...)
+	result.Types = make(map[string]KeysUsage)
+	for entryPointType, usage := range r.Types {
+		result.Types[entryPointType] = usage
+	}
+	return result
+}
+
+func (u *KeysUsage) add(sample KeySample) {
+	u.Keys++
+	u.MemoryUsage += sample.MemoryUsage
+}
+
+// insertLargest inserts the sample in the keys sorted from the largest, keeping at most top of them.
+func insertLargest(keys []KeySample, sample KeySample, top int) []KeySample {
+	index := sort.Search(len(keys), func(i int) bool {
+		return keys[i].MemoryUsage < sample.MemoryUsage
+	})
+	if index >= top {
+		return keys
+	}
+	keys = append(keys, KeySample{})
+	copy(keys[index+1:], keys[index:])
+	keys[index] = sample
+	if len(keys) > top {
+		keys = keys[:top]
+	}
+	return keys
+}
+
+// prefixOf returns the first levels of the key, followed by the separator, or an empty string
+// when the key has no parent.
+func prefixOf(tokens []string, levels uint, separator string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	if uint(len(tokens)) <= levels {
+		levels = uint(len(tokens)) - 1
+	}
+	if levels == 0 {
+		return ""
+	}
+	return strings.Join(tokens[:levels], separator) + separator
+}
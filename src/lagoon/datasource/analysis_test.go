@@ -0,0 +1,114 @@
+package datasource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewAnalysisWithDefaultOptions(t *testing.T) {
+	// when
+	analysis := NewAnalysis(AnalysisOptions{Samples: -1})
+
+	// then
+	assert.Equal(t, AnalysisOptions{Filter: "*", Top: DefaultAnalysisTop, PrefixLevels: DefaultAnalysisPrefixLevels}, analysis.Options())
+	assert.Equal(t, ScanProgress{Nodes: 1}, analysis.Report().Progress)
+}
+
+func TestAnalysisReport(t *testing.T) {
+	// given
+	analysis := NewAnalysis(AnalysisOptions{Top: 2, PrefixLevels: 2})
+	analysis.SetNodes(2)
+	add := func(key string, entryPointType string, memoryUsage int64, hasTimeToLive bool) {
+		analysis.Add(KeySample{Key: key, Type: entryPointType, MemoryUsage: memoryUsage, Length: 1, HasTimeToLive: hasTimeToLive},
+			strings.Split(key, ":"), ":")
+	}
+
+	// when
+	add("users:1:name", "VALUE", 10, false)
+	add("users:1:roles", "SET", 50, true)
+	add("users:2:name", "VALUE", 30, false)
+	add("sessions:1", "HASH", 100, true)
+	add("counter", "VALUE", 5, false)
+	analysis.Scanned(3, true)
+	analysis.Scanned(2, false)
+
+	// then
+	report := analysis.Report()
+	assert.Equal(t, ScanProgress{ScannedKeys: 5, ScannedNodes: 1, Nodes: 2}, report.Progress)
+	assert.Equal(t, KeysUsage{Keys: 5, MemoryUsage: 195}, report.Total)
+	assert.Equal(t, []KeySample{
+		{Key: "sessions:1", Type: "HASH", MemoryUsage: 100, Length: 1, HasTimeToLive: true},
+		{Key: "users:1:roles", Type: "SET", MemoryUsage: 50, Length: 1, HasTimeToLive: true},
+	}, report.LargestKeys)
+	assert.Equal(t, []PrefixUsage{
+		{Prefix: "sessions:", KeysUsage: KeysUsage{Keys: 1, MemoryUsage: 100}},
+		{Prefix: "users:1:", KeysUsage: KeysUsage{Keys: 2, MemoryUsage: 60}},
+	}, report.LargestPrefixes)
+	assert.Equal(t, map[string]KeysUsage{
+		"VALUE": {Keys: 3, MemoryUsage: 45},
+		"SET":   {Keys: 1, MemoryUsage: 50},
+		"HASH":  {Keys: 1, MemoryUsage: 100},
+	}, report.Types)
+	assert.Equal(t, KeysUsage{Keys: 3, MemoryUsage: 45}, report.WithoutTimeToLive)
+	assert.Equal(t, []string{"users:2:name", "users:1:name"}, keysOf(report.LargestKeysWithoutTimeToLive))
+}
+
+func TestAnalysisDropsTheSmallestPrefixes(t *testing.T) {
+	// given
+	analysis := NewAnalysis(AnalysisOptions{Top: 2})
+	analysis.maxPrefixes = 4
+	add := func(key string, memoryUsage int64) {
+		analysis.Add(KeySample{Key: key, Type: "VALUE", MemoryUsage: memoryUsage}, strings.Split(key, ":"), ":")
+	}
+
+	// when
+	add("a:1", 40)
+	add("b:1", 30)
+	add("c:1", 20)
+	add("d:1", 10)
+	add("e:1", 5)
+	add("a:2", 1)
+
+	// then
+	assert.Len(t, analysis.prefixes, 3)
+	report := analysis.Report()
+	assert.True(t, report.PrefixesTruncated)
+	assert.Equal(t, KeysUsage{Keys: 6, MemoryUsage: 106}, report.Total)
+	assert.Equal(t, []PrefixUsage{
+		{Prefix: "a:", KeysUsage: KeysUsage{Keys: 2, MemoryUsage: 41}},
+		{Prefix: "b:", KeysUsage: KeysUsage{Keys: 1, MemoryUsage: 30}},
+	}, report.LargestPrefixes)
+}
+
+func TestAnalysisFinish(t *testing.T) {
+	// given
+	analysis := NewAnalysis(AnalysisOptions{})
+	analysis.Add(KeySample{Key: "users:1", Type: "VALUE", MemoryUsage: 10}, []string{"users", "1"}, ":")
+	expected := analysis.Report()
+
+	// when
+	analysis.Finish()
+
+	// then
+	assert.Nil(t, analysis.prefixes)
+	assert.Nil(t, analysis.types)
+	assert.Equal(t, expected, analysis.Report())
+}
+
+func TestPrefixOf(t *testing.T) {
+	assert.Equal(t, "users:", prefixOf([]string{"users", "1", "name"}, 1, ":"))
+	assert.Equal(t, "users:1:", prefixOf([]string{"users", "1", "name"}, 2, ":"))
+	// The key itself is not its own prefix.
+	assert.Equal(t, "users:1:", prefixOf([]string{"users", "1", "name"}, 5, ":"))
+	assert.Equal(t, "", prefixOf([]string{"counter"}, 1, ":"))
+	assert.Equal(t, "", prefixOf(nil, 1, ":"))
+}
+
+func keysOf(samples []KeySample) []string {
+	var keys []string
+	for _, sample := range samples {
+		keys = append(keys, sample.Key)
+	}
+	return keys
+}
@@ -0,0 +1,703 @@
+// Package conformance provides the test suite that every vendor runs against its DataSource, in order to behave
+// the same way in the API and the UI: trees of entry points, content of each type, infos, deletions, read-only
+// data sources and the semantics of the ActionStatus and of the closing of the channels.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"lagoon/datasource"
+	"testing"
+	"time"
+)
+
+// Maximal time to wait for the data of a Moved action.
+const timeout = 10 * time.Second
+
+// Suite describes the vendor under test.
+type Suite struct {
+	// Open creates and opens a data source without any entry point, which can be only read when readOnly is true.
+	// It registers the closing of the data source and the removal of its entry points with t.Cleanup.
+	Open func(t *testing.T, readOnly bool) datasource.DataSource
+	// Types lists the types of entry points the vendor can create with the writing methods of the DataSource,
+	// all of them when empty.
+	Types []datasource.EntryPointType
+}
+
+// Run runs all the tests of the suite as subtests of t.
+func Run(t *testing.T, suite Suite) {
+	if len(suite.Types) == 0 {
+		suite.Types = []datasource.EntryPointType{datasource.Value, datasource.Set, datasource.ScoredSet,
+			datasource.List, datasource.Hash, datasource.Stream}
+	}
+	t.Run("ListEntryPoints", suite.testListEntryPoints)
+	t.Run("ListEntryPointsWithFilter", suite.testListEntryPointsWithFilter)
+	t.Run("GetContent", suite.testGetContent)
+	t.Run("GetContentOfMissingEntryPoint", suite.testGetContentOfMissingEntryPoint)
+	t.Run("GetContentPage", suite.testGetContentPage)
+	if suite.supports(datasource.ScoredSet) {
+		t.Run("GetSortedSetRange", suite.testGetSortedSetRange)
+	}
+	t.Run("GetEntryPointInfos", suite.testGetEntryPointInfos)
+	t.Run("GetEntryPointInfosOfMissingEntryPoint", suite.testGetEntryPointInfosOfMissingEntryPoint)
+	t.Run("SetExpiry", suite.testSetExpiry)
+	t.Run("DeleteEntrypoint", suite.testDeleteEntrypoint)
+	t.Run("DeleteEntrypointChildren", suite.testDeleteEntrypointChildren)
+	t.Run("SetChildrenExpiry", suite.testSetChildrenExpiry)
+	t.Run("MoveEntrypointTree", suite.testMoveEntrypointTree)
+	t.Run("AnalyzeEntryPoints", suite.testAnalyzeEntryPoints)
+	t.Run("ReadOnly", suite.testReadOnly)
+	if suite.supports(datasource.Stream) {
+		t.Run("Consume", suite.testConsume)
+	}
+}
+
+func (s Suite) supports(entryPointType datasource.EntryPointType) bool {
+	for _, supported := range s.Types {
+		if supported == entryPointType {
+			return true
+		}
+	}
+	return false
+}
+
+// seedValues creates the entry points as values equal to their key.
+func seedValues(t *testing.T, ds datasource.DataSource, keys ...string) {
+	for _, key := range keys {
+		if err := ds.SetValue(context.Background(), datasource.EntryPoint(key), key); err != nil {
+			t.Fatalf("the entry point %s cannot be created: %s", key, err.Error())
+		}
+	}
+}
+
+// seed creates the entry point of the type, with the content expected by the tests.
+func seed(t *testing.T, ds datasource.DataSource, key string, entryPointType datasource.EntryPointType) {
+	entryPoint := datasource.EntryPoint(key)
+	var err error
+	switch entryPointType {
+	case datasource.Value:
+		err = ds.SetValue(context.Background(), entryPoint, "my-value")
+	case datasource.Set:
+		_, err = ds.AddSetMembers(context.Background(), entryPoint, []string{"b", "c", "a"})
+	case datasource.ScoredSet:
+		_, err = ds.AddSortedSetMembers(context.Background(), entryPoint, []datasource.ScoredMember{{Member: "c", Score: 1}, {Member: "b", Score: 2}, {Member: "a", Score: 1}})
+	case datasource.List:
+		_, err = ds.PushListValues(context.Background(), entryPoint, []string{"a", "b", "c"}, false)
+	case datasource.Hash:
+		err = ds.SetHashFields(context.Background(), entryPoint, map[string]string{"field-2": "value-2", "field-1": "value-1"})
+	case datasource.Stream:
+		if _, err = ds.AddStreamMessage(context.Background(), entryPoint, "1-1", map[string]interface{}{"index": "1"}); err == nil {
+			_, err = ds.AddStreamMessage(context.Background(), entryPoint, "2-1", map[string]interface{}{"index": "2"})
+		}
+	}
+	if err != nil {
+		t.Fatalf("the entry point %s cannot be created: %s", key, err.Error())
+	}
+}
+
+// expectedContent is the content of the entry points created by seed, as JSON. The timestamps of the messages of streams are ignored.
+var expectedContent = map[datasource.EntryPointType]string{
+	datasource.Value:     `["my-value"]`,
+	datasource.Set:       `["a","b","c"]`,
+	datasource.ScoredSet: `[{"score":1,"values":["a","c"]},{"score":2,"values":["b"]}]`,
+	datasource.List:      `["a","b","c"]`,
+	datasource.Hash:      `[{"key":"field-1","value":"value-1"},{"key":"field-2","value":"value-2"}]`,
+	datasource.Stream:    `[{"fields":{"index":"1"},"id":"1-1"},{"fields":{"index":"2"},"id":"2-1"}]`,
+}
+
+// expectedLength is the length in the infos of the entry points created by seed.
+var expectedLength = map[datasource.EntryPointType]uint64{
+	datasource.Value:     uint64(len("my-value")),
+	datasource.Set:       3,
+	datasource.ScoredSet: 3,
+	datasource.List:      3,
+	datasource.Hash:      2,
+	datasource.Stream:    2,
+}
+
+// collectBatches returns the batches of the action depending on its status: a Completed action provides all its data
+// immediately and leaves the channel open, which is closed by the caller, a Moved action closes the channel
+// once all its data was sent.
+func collectBatches(t *testing.T, status datasource.ActionStatus, channel chan datasource.DataBatch) []datasource.DataBatch {
+	var batches []datasource.DataBatch
+	switch status {
+	case datasource.Completed:
+		for len(channel) > 0 {
+			batches = append(batches, <-channel)
+		}
+		assertOpen(t, channel)
+	case datasource.Moved:
+		deadline := time.After(timeout)
+		for {
+			select {
+			case batch, open := <-channel:
+				if !open {
+					return batches
+				}
+				batches = append(batches, batch)
+			case <-deadline:
+				t.Fatal("the channel of a Moved action was not closed")
+			}
+		}
+	default:
+		t.Fatalf("the status %d of a successful action is neither Completed nor Moved", status)
+	}
+	return batches
+}
+
+// assertOpen checks the data source did not close the channel, by closing it.
+func assertOpen(t *testing.T, channel chan datasource.DataBatch) {
+	defer func() {
+		if recover() != nil {
+			t.Error("the channel of a Completed action was closed by the data source")
+		}
+	}()
+	close(channel)
+}
+
+// collectErrors returns the errors of a Moved action, once the data source closed the channel.
+// collect returns the data of the action, which must not report any error.
+func collect(t *testing.T, status datasource.ActionStatus, channel chan datasource.DataBatch) []interface{} {
+	var data []interface{}
+	for _, batch := range collectBatches(t, status, channel) {
+		if batch.Error != nil {
+			t.Errorf("the action reported the error %s", batch.Error.Error())
+		}
+		data = append(data, batch.Data...)
+	}
+	return data
+}
+
+// collectProgress returns the progress reported by the action.
+func collectProgress(t *testing.T, status datasource.ActionStatus, channel chan datasource.DataBatch) []interface{} {
+	var progress []interface{}
+	for _, batch := range collectBatches(t, status, channel) {
+		if batch.Progress != nil {
+			progress = append(progress, batch.Progress)
+		}
+	}
+	return progress
+}
+
+func collectErrors(t *testing.T, errorChannel chan error) []error {
+	var errs []error
+	deadline := time.After(timeout)
+	for {
+		select {
+		case err, open := <-errorChannel:
+			if !open {
+				return errs
+			}
+			errs = append(errs, err)
+		case <-deadline:
+			t.Fatal("the channel of a Moved action was not closed")
+		}
+	}
+}
+
+// asJson returns the data as JSON, in order to compare the values of the different vendors. The timestamps
+// of the messages of streams are removed.
+func asJson(t *testing.T, data []interface{}) string {
+	content, err := json.Marshal(data)
+	assert.Nil(t, err)
+	var values []interface{}
+	assert.Nil(t, json.Unmarshal(content, &values))
+	for _, value := range values {
+		if object, ok := value.(map[string]interface{}); ok {
+			delete(object, "timestamp")
+		}
+	}
+	content, err = json.Marshal(values)
+	assert.Nil(t, err)
+	return string(content)
+}
+
+// nodes returns the nodes of the tree of entry points, which can be listed as values or pointers.
+func nodes(data []interface{}) []datasource.EntryPointNode {
+	var result []datasource.EntryPointNode
+	for _, value := range data {
+		switch node := value.(type) {
+		case datasource.EntryPointNode:
+			result = append(result, node)
+		case *datasource.EntryPointNode:
+			result = append(result, *node)
+		}
+	}
+	return result
+}
+
+func listEntryPoints(t *testing.T, ds datasource.DataSource, filter datasource.Filter, minTreeLevel uint, maxTreeLevel uint) []datasource.EntryPointNode {
+	channel := make(chan datasource.DataBatch, 100)
+	status, err := ds.ListEntryPoints(context.Background(), filter, channel, minTreeLevel, maxTreeLevel)
+	assert.Nil(t, err)
+	return nodes(collect(t, status, channel))
+}
+
+func (s Suite) testListEntryPoints(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "tree:a:1", "tree:a:2", "tree:b", "tree:b:1", "single")
+
+	// when
+	roots := listEntryPoints(t, ds, datasource.Filter{Glob: "*"}, 0, 0)
+
+	// then
+	assert.Equal(t, []datasource.EntryPointNode{
+		{Path: "single", HasContent: true},
+		{Path: "tree", Length: 4},
+	}, roots)
+
+	// when
+	twoLevels := listEntryPoints(t, ds, datasource.Filter{Glob: "*"}, 0, 1)
+
+	// then
+	assert.Equal(t, []datasource.EntryPointNode{
+		{Path: "single", HasContent: true},
+		{Path: "tree", Length: 4},
+		{Path: "tree:a", Length: 2},
+		{Path: "tree:b", Length: 1, HasContent: true},
+	}, twoLevels)
+
+	// when
+	belowRoots := listEntryPoints(t, ds, datasource.Filter{Glob: "*"}, 1, 2)
+
+	// then
+	// The levels under the minimal one are omitted from the paths.
+	assert.Equal(t, []datasource.EntryPointNode{
+		{Path: "a", Length: 2},
+		{Path: "a:1", HasContent: true},
+		{Path: "a:2", HasContent: true},
+		{Path: "b", Length: 1, HasContent: true},
+		{Path: "b:1", HasContent: true},
+	}, belowRoots)
+}
+
+func (s Suite) testListEntryPointsWithFilter(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "tree:a:1", "tree:a:2", "tree:b:1", "other:a:1")
+
+	// when
+	filtered := listEntryPoints(t, ds, datasource.Filter{Glob: "tree:a:*"}, 0, 2)
+
+	// then
+	assert.Equal(t, []datasource.EntryPointNode{
+		{Path: "tree", Length: 2},
+		{Path: "tree:a", Length: 2},
+		{Path: "tree:a:1", HasContent: true},
+		{Path: "tree:a:2", HasContent: true},
+	}, filtered)
+
+	// when
+	none := listEntryPoints(t, ds, datasource.Filter{Glob: "unknown:*"}, 0, 2)
+
+	// then
+	assert.Empty(t, none)
+}
+
+func (s Suite) testGetContent(t *testing.T) {
+	for _, entryPointType := range s.Types {
+		t.Run(datasource.EntryPointTypesAsString[entryPointType], func(t *testing.T) {
+			// given
+			ds := s.Open(t, false)
+			seed(t, ds, "my-entry-point", entryPointType)
+			channel := make(chan datasource.DataBatch, 100)
+
+			// when
+			status, err := ds.GetContent(context.Background(), "my-entry-point", datasource.Filter{}, channel)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, expectedContent[entryPointType], asJson(t, collect(t, status, channel)))
+		})
+	}
+}
+
+func (s Suite) testGetContentOfMissingEntryPoint(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+
+	// when
+	_, err := ds.GetContent(context.Background(), "unknown", datasource.Filter{}, make(chan datasource.DataBatch, 100))
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func (s Suite) testGetContentPage(t *testing.T) {
+	for _, entryPointType := range s.Types {
+		t.Run(datasource.EntryPointTypesAsString[entryPointType], func(t *testing.T) {
+			// given
+			ds := s.Open(t, false)
+			seed(t, ds, "my-entry-point", entryPointType)
+
+			// when
+			page, err := ds.GetContentPage(context.Background(), "my-entry-point", datasource.Filter{}, datasource.Page{Count: 100})
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, expectedContent[entryPointType], asJson(t, page.Data))
+			assert.Equal(t, datasource.FirstPage, page.Cursor)
+		})
+	}
+
+	if s.supports(datasource.List) {
+		t.Run("Windows", func(t *testing.T) {
+			// given
+			ds := s.Open(t, false)
+			seed(t, ds, "my-entry-point", datasource.List)
+
+			// when
+			first, err := ds.GetContentPage(context.Background(), "my-entry-point", datasource.Filter{}, datasource.Page{Count: 2})
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{"a", "b"}, first.Data)
+			assert.NotEqual(t, datasource.FirstPage, first.Cursor)
+
+			// when
+			second, err := ds.GetContentPage(context.Background(), "my-entry-point", datasource.Filter{}, datasource.Page{Cursor: first.Cursor, Count: 2})
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{"c"}, second.Data)
+			assert.Equal(t, datasource.FirstPage, second.Cursor)
+		})
+	}
+
+	// when
+	_, err := s.Open(t, false).GetContentPage(context.Background(), "unknown", datasource.Filter{}, datasource.Page{})
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func (s Suite) testGetSortedSetRange(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seed(t, ds, "my-entry-point", datasource.ScoredSet)
+	_, err := ds.AddSortedSetMembers(context.Background(), "my-letters", []datasource.ScoredMember{{Member: "d"}, {Member: "b"}, {Member: "a"}, {Member: "c"}})
+	assert.Nil(t, err)
+	testData := []struct {
+		name           string
+		key            string
+		sortedSetRange datasource.SortedSetRange
+		expected       string
+	}{
+		{name: "ranks", key: "my-entry-point", sortedSetRange: datasource.SortedSetRange{Start: "0", Stop: "1"},
+			expected: `[{"score":1,"values":["a","c"]}]`},
+		{name: "ranks in reverse order", key: "my-entry-point", sortedSetRange: datasource.SortedSetRange{By: datasource.ByRank, Start: "0", Stop: "1", Reverse: true},
+			expected: `[{"score":2,"values":["b"]},{"score":1,"values":["c"]}]`},
+		{name: "last rank", key: "my-entry-point", sortedSetRange: datasource.SortedSetRange{Start: "-1", Stop: "-1"},
+			expected: `[{"score":2,"values":["b"]}]`},
+		{name: "exclusive score", key: "my-entry-point", sortedSetRange: datasource.SortedSetRange{By: datasource.ByScore, Start: "(1"},
+			expected: `[{"score":2,"values":["b"]}]`},
+		{name: "scores with limit", key: "my-entry-point", sortedSetRange: datasource.SortedSetRange{By: datasource.ByScore, Start: "-inf", Stop: "+inf", Offset: 1, Count: 1},
+			expected: `[{"score":1,"values":["c"]}]`},
+		{name: "lexicographic range", key: "my-letters", sortedSetRange: datasource.SortedSetRange{By: datasource.ByLex, Start: "[b", Stop: "(d"},
+			expected: `[{"score":0,"values":["b","c"]}]`},
+		{name: "lexicographic range in reverse order", key: "my-letters", sortedSetRange: datasource.SortedSetRange{By: datasource.ByLex, Start: "(a", Reverse: true, Count: 2},
+			expected: `[{"score":0,"values":["d","c"]}]`},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			channel := make(chan datasource.DataBatch, 100)
+
+			// when
+			status, err := ds.GetSortedSetRange(context.Background(), datasource.EntryPoint(data.key), data.sortedSetRange, channel)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, data.expected, asJson(t, collect(t, status, channel)))
+		})
+	}
+
+	// when
+	_, err = ds.GetSortedSetRange(context.Background(), "unknown", datasource.SortedSetRange{}, make(chan datasource.DataBatch, 100))
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func (s Suite) testGetEntryPointInfos(t *testing.T) {
+	for _, entryPointType := range s.Types {
+		t.Run(datasource.EntryPointTypesAsString[entryPointType], func(t *testing.T) {
+			// given
+			ds := s.Open(t, false)
+			seed(t, ds, "my-entry-point", entryPointType)
+
+			// when
+			infos, err := ds.GetEntryPointInfos(context.Background(), "my-entry-point")
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, entryPointType, infos.Type)
+			assert.Equal(t, expectedLength[entryPointType], infos.Length)
+			// The entry points are persistent by default.
+			assert.True(t, infos.TimeToLive < 0, "the time to live %s of a persistent entry point is not negative", infos.TimeToLive)
+			if entryPointType == datasource.Stream {
+				assert.NotNil(t, infos.Stream)
+				assert.Equal(t, "1-1", infos.Stream.FirstEntryId)
+				assert.Equal(t, "2-1", infos.Stream.LastEntryId)
+			}
+		})
+	}
+}
+
+func (s Suite) testGetEntryPointInfosOfMissingEntryPoint(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+
+	// when
+	_, err := ds.GetEntryPointInfos(context.Background(), "unknown")
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func (s Suite) testSetExpiry(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "my-entry-point")
+
+	// when
+	err := ds.SetExpiry(context.Background(), "my-entry-point", datasource.Expiry{TimeToLive: time.Minute})
+
+	// then
+	assert.Nil(t, err)
+	infos, err := ds.GetEntryPointInfos(context.Background(), "my-entry-point")
+	assert.Nil(t, err)
+	assert.True(t, infos.TimeToLive > 0 && infos.TimeToLive <= time.Minute, "the time to live %s is not the expected one", infos.TimeToLive)
+
+	// when
+	err = ds.SetExpiry(context.Background(), "my-entry-point", datasource.Expiry{})
+
+	// then
+	assert.Nil(t, err)
+	infos, err = ds.GetEntryPointInfos(context.Background(), "my-entry-point")
+	assert.Nil(t, err)
+	assert.True(t, infos.TimeToLive < 0, "the time to live %s of a persistent entry point is not negative", infos.TimeToLive)
+
+	// when
+	err = ds.SetExpiry(context.Background(), "unknown", datasource.Expiry{TimeToLive: time.Minute})
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func (s Suite) testDeleteEntrypoint(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "my-entry-point", "my-entry-point:child")
+
+	// when
+	err := ds.DeleteEntrypoint(context.Background(), "my-entry-point")
+
+	// then
+	assert.Nil(t, err)
+	_, err = ds.GetEntryPointInfos(context.Background(), "my-entry-point")
+	assert.NotNil(t, err)
+	// The children are kept.
+	_, err = ds.GetEntryPointInfos(context.Background(), "my-entry-point:child")
+	assert.Nil(t, err)
+}
+
+func (s Suite) testDeleteEntrypointChildren(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "parent", "parent:a", "parent:a:1", "parent:b", "parent-sibling:a")
+	preview, err := ds.PreviewEntrypointChildrenDeletion(context.Background(), "parent", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), preview.Count)
+	errorChannel := make(chan error, 10)
+
+	// when
+	status, err := ds.DeleteEntrypointChildren(context.Background(), "parent", 0, errorChannel)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, status)
+	assert.Empty(t, collectErrors(t, errorChannel))
+	assert.Equal(t, []datasource.EntryPointNode{
+		{Path: "parent", HasContent: true},
+		{Path: "parent-sibling", Length: 1},
+		{Path: "parent-sibling:a", HasContent: true},
+	}, listEntryPoints(t, ds, datasource.Filter{Glob: "*"}, 0, 2))
+}
+
+func (s Suite) testSetChildrenExpiry(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "parent", "parent:a", "parent:b")
+	progressChannel := make(chan datasource.DataBatch, 100)
+
+	// when
+	status, err := ds.SetChildrenExpiry(context.Background(), "parent", datasource.Expiry{TimeToLive: time.Minute}, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, status)
+	progress := collectProgress(t, status, progressChannel)
+	assert.NotEmpty(t, progress)
+	assert.Equal(t, datasource.Progress{Total: 2, Processed: 2}, progress[len(progress)-1])
+	parent, _ := ds.GetEntryPointInfos(context.Background(), "parent")
+	assert.True(t, parent.TimeToLive < 0)
+	child, _ := ds.GetEntryPointInfos(context.Background(), "parent:a")
+	assert.True(t, child.TimeToLive > 0)
+}
+
+func (s Suite) testMoveEntrypointTree(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seedValues(t, ds, "from:a", "from:b", "to:b")
+	dryRunChannel := make(chan datasource.DataBatch, 100)
+
+	// when
+	status, err := ds.MoveEntrypointTree(context.Background(), "from", "to", true, dryRunChannel)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{
+		datasource.EntryPointMove{From: "from:a", To: "to:a"},
+		datasource.EntryPointMove{From: "from:b", To: "to:b", Conflict: true},
+	}, collect(t, status, dryRunChannel))
+
+	// when
+	progressChannel := make(chan datasource.DataBatch, 100)
+	status, err = ds.MoveEntrypointTree(context.Background(), "from", "to", false, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	progress := collectProgress(t, status, progressChannel)
+	assert.NotEmpty(t, progress)
+	last := progress[len(progress)-1].(datasource.Progress)
+	assert.Equal(t, uint64(2), last.Processed)
+	// The existing entry point is not overwritten.
+	assert.Equal(t, uint64(1), last.Failed)
+	assert.Equal(t, []datasource.EntryPointNode{
+		{Path: "from", Length: 1},
+		{Path: "from:b", HasContent: true},
+		{Path: "to", Length: 2},
+		{Path: "to:a", HasContent: true},
+		{Path: "to:b", HasContent: true},
+	}, listEntryPoints(t, ds, datasource.Filter{Glob: "*"}, 0, 1))
+}
+
+func (s Suite) testAnalyzeEntryPoints(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	for _, entryPointType := range s.Types {
+		seed(t, ds, "users:"+datasource.EntryPointTypesAsString[entryPointType], entryPointType)
+	}
+	seedValues(t, ds, "sessions:1", "top-level")
+	if err := ds.SetExpiry(context.Background(), "sessions:1", datasource.Expiry{TimeToLive: time.Minute}); err != nil {
+		t.Fatalf("the expiry cannot be set: %s", err.Error())
+	}
+	analysis := datasource.NewAnalysis(datasource.AnalysisOptions{Top: 3})
+
+	// when
+	err := ds.AnalyzeEntryPoints(context.Background(), analysis)
+
+	// then
+	assert.Nil(t, err)
+	report := analysis.Report()
+	keys := uint64(len(s.Types) + 2)
+	assert.Equal(t, keys, report.Total.Keys)
+	assert.True(t, report.Total.MemoryUsage > 0, "the memory usage %d is not positive", report.Total.MemoryUsage)
+	assert.Equal(t, report.Progress.Nodes, report.Progress.ScannedNodes)
+	assert.Equal(t, keys, report.Progress.ScannedKeys)
+	for _, entryPointType := range s.Types {
+		assert.True(t, report.Types[datasource.EntryPointTypesAsString[entryPointType]].Keys > 0)
+	}
+	assert.Equal(t, keys-1, report.WithoutTimeToLive.Keys)
+	assert.Len(t, report.LargestKeys, 3)
+	for i := 1; i < len(report.LargestKeys); i++ {
+		assert.True(t, report.LargestKeys[i-1].MemoryUsage >= report.LargestKeys[i].MemoryUsage)
+	}
+	for _, sample := range report.LargestKeysWithoutTimeToLive {
+		assert.NotEqual(t, "sessions:1", sample.Key)
+	}
+	prefixes := make(map[string]uint64)
+	for _, prefix := range report.LargestPrefixes {
+		prefixes[prefix.Prefix] = prefix.Keys
+	}
+	assert.Equal(t, map[string]uint64{"users:": uint64(len(s.Types)), "sessions:": 1}, prefixes)
+
+	// given
+	analysis = datasource.NewAnalysis(datasource.AnalysisOptions{Filter: "sessions:*"})
+
+	// when
+	err = ds.AnalyzeEntryPoints(context.Background(), analysis)
+
+	// then
+	assert.Nil(t, err)
+	report = analysis.Report()
+	assert.Equal(t, uint64(1), report.Total.Keys)
+	assert.Equal(t, "sessions:1", report.LargestKeys[0].Key)
+	assert.True(t, report.LargestKeys[0].HasTimeToLive)
+	assert.Equal(t, uint64(len("sessions:1")), report.LargestKeys[0].Length)
+}
+
+func (s Suite) testReadOnly(t *testing.T) {
+	// given
+	ds := s.Open(t, true)
+
+	// when
+	setErr := ds.SetValue(context.Background(), "my-value", "value")
+	_, addErr := ds.AddSetMembers(context.Background(), "my-set", []string{"a"})
+	_, pushErr := ds.PushListValues(context.Background(), "my-list", []string{"a"}, false)
+	hashErr := ds.SetHashFields(context.Background(), "my-hash", map[string]string{"field": "value"})
+	deleteErr := ds.DeleteEntrypoint(context.Background(), "my-value")
+	_, deleteChildrenErr := ds.DeleteEntrypointChildren(context.Background(), "my-value", 0, make(chan error, 10))
+	renameErr := ds.RenameEntrypoint(context.Background(), "my-value", "other")
+	expiryErr := ds.SetExpiry(context.Background(), "my-value", datasource.Expiry{TimeToLive: time.Minute})
+	_, moveErr := ds.MoveEntrypointTree(context.Background(), "my-value", "other", false, make(chan datasource.DataBatch, 100))
+
+	// then
+	assert.NotNil(t, setErr)
+	assert.NotNil(t, addErr)
+	assert.NotNil(t, pushErr)
+	assert.NotNil(t, hashErr)
+	assert.NotNil(t, deleteErr)
+	assert.NotNil(t, deleteChildrenErr)
+	assert.NotNil(t, renameErr)
+	assert.NotNil(t, expiryErr)
+	assert.NotNil(t, moveErr)
+	assert.Empty(t, listEntryPoints(t, ds, datasource.Filter{Glob: "*"}, 0, 0))
+}
+
+func (s Suite) testConsume(t *testing.T) {
+	// given
+	ds := s.Open(t, false)
+	seed(t, ds, "my-stream", datasource.Stream)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channel := make(chan datasource.DataBatch, 100)
+
+	// when
+	status, err := ds.Consume(ctx, "my-stream", channel, datasource.Filter{}, true)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, status)
+	var received []interface{}
+	deadline := time.After(timeout)
+	for len(received) < 2 {
+		select {
+		case batch := <-channel:
+			received = append(received, batch.Data...)
+		case <-deadline:
+			t.Fatal("the messages were not consumed")
+		}
+	}
+	assert.Equal(t, expectedContent[datasource.Stream], asJson(t, received))
+
+	// when
+	cancel()
+
+	// then
+	// The consumption stops and closes the channel when its context is cancelled.
+	collect(t, status, channel)
+}
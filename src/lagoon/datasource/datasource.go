@@ -1,7 +1,9 @@
 package datasource
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 )
@@ -25,6 +27,7 @@ const (
 	List      EntryPointType = 4
 	Hash      EntryPointType = 5
 	Stream    EntryPointType = 6
+	Channel   EntryPointType = 7
 
 	// Count of data batches after which the result is returned asynchronously in a web.socket.
 	SwitchToWsBarrier uint8 = 20
@@ -37,18 +40,33 @@ var EntryPointTypesAsString = map[EntryPointType]string{
 	List:      "LIST",
 	Hash:      "HASH",
 	Stream:    "STREAM",
+	Channel:   "CHANNEL",
 }
 
 type DataBatch struct {
 	Size uint64        `json:"size" binding:"required"`
 	Data []interface{} `json:"data" binding:"required"`
+	// Progress is set, without data, when the batch reports the progress of the operation.
+	Progress interface{} `json:"-"`
+	// Error is set, without data, when the operation failed after it moved to asynchronous mode.
+	Error error `json:"-"`
+}
+
+// NewProgressBatch creates a batch reporting the progress of an operation, like a Progress or a ScanProgress.
+func NewProgressBatch(progress interface{}) DataBatch {
+	return DataBatch{Progress: progress}
+}
+
+// NewErrorBatch creates a batch reporting the failure of an operation, whose result is read asynchronously.
+func NewErrorBatch(err error) DataBatch {
+	return DataBatch{Error: err}
 }
 
 type DataSourceDescriptor struct {
 	Id            string            `json:"id" yaml:"id"`
 	Vendor        string            `json:"vendor" yaml:"vendor" binding:"required"`
 	Name          string            `json:"name" yaml:"name" binding:"required"`
-	Description   string            `json:"description"yaml:"description"`
+	Description   string            `json:"description" yaml:"description"`
 	Bootstrap     string            `json:"bootstrap" yaml:"bootstrap" binding:"required"`
 	ReadOnly      bool              `json:"readonly" yaml:"readonly"`
 	User          string            `json:"user" yaml:"user"`
@@ -56,6 +74,24 @@ type DataSourceDescriptor struct {
 	Configuration map[string]string `json:"configuration" yaml:"configuration"`
 }
 
+// DefaultTimeout is the duration after which the operations on a data source are cancelled,
+// when no timeout is configured.
+const DefaultTimeout = 5 * time.Minute
+
+// TimeoutFromConfiguration returns the duration set as timeout in the configuration of a data source,
+// after which its operations are cancelled. A timeout of 0 disables it.
+func TimeoutFromConfiguration(configuration map[string]string) (time.Duration, error) {
+	value, ok := configuration["timeout"]
+	if !ok {
+		return DefaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, errors.New(fmt.Sprintf("the timeout '%s' is not valid", value))
+	}
+	return timeout, nil
+}
+
 type EntryPoint string
 
 type EntryPointNode struct {
@@ -64,18 +100,226 @@ type EntryPointNode struct {
 	Length     uint64     `json:"length" binding:"required"`
 }
 
+// ChannelNode is a publish/subscribe channel, with its count of subscribers over all the nodes of the data source.
+type ChannelNode struct {
+	Path        EntryPoint `json:"path" binding:"required"`
+	Type        string     `json:"type" binding:"required"`
+	Subscribers uint64     `json:"subscribers" binding:"required"`
+}
+
 type EntryPointInfos struct {
 	Type       EntryPointType `json:"type" binding:"required"`
 	Length     uint64         `json:"length" binding:"required"`
 	TimeToLive time.Duration  `json:"timeToLive" binding:"required"`
+	// Stream is only set for the entry points of type Stream.
+	Stream *StreamInfos `json:"stream,omitempty"`
+	// Partitions are only set for the topics of Kafka.
+	Partitions []PartitionInfos `json:"partitions,omitempty"`
+	// Queue is only set for the queues of RabbitMQ.
+	Queue *QueueInfos `json:"queue,omitempty"`
+	// Exchange is only set for the exchanges of RabbitMQ.
+	Exchange *ExchangeInfos `json:"exchange,omitempty"`
+	// Object is only set for the entry points of Redis.
+	Object *ObjectInfos `json:"object,omitempty"`
+	// Location is only set for the entry points of a Redis cluster.
+	Location *LocationInfos `json:"location,omitempty"`
+}
+
+// ObjectInfos describes how an entry point is stored by the server. The values which the server cannot provide,
+// for instance the idle time or the frequency depending on its eviction policy, are not set.
+type ObjectInfos struct {
+	// MemoryUsage is the count of bytes used by the value and its key, estimated by sampling the large collections.
+	MemoryUsage int64 `json:"memoryUsage,omitempty"`
+	// Encoding is the internal representation of the value, like listpack, hashtable or skiplist.
+	Encoding string `json:"encoding,omitempty"`
+	// IdleTime is the time in milliseconds since the last access, when the eviction policy is not LFU.
+	IdleTime *int64 `json:"idleTime,omitempty"`
+	// Frequency is the logarithmic access counter, when the eviction policy is LFU.
+	Frequency *int64 `json:"frequency,omitempty"`
+}
+
+// LocationInfos is the hash slot of an entry point in a cluster, with the master owning the slot.
+type LocationInfos struct {
+	Slot     int      `json:"slot"`
+	NodeId   string   `json:"nodeId"`
+	Node     string   `json:"node"`
+	Replicas []string `json:"replicas,omitempty"`
 }
 
 type SingleValue interface{}
 
+// DecodableValue is implemented by the values of the contents made of several strings, like the fields
+// of a hash or a stream message, in order to decode each of them for the clients.
+type DecodableValue interface {
+	// DecodeValues returns the value for the clients, with its strings converted by decode.
+	DecodeValues(decode func(value string) interface{}) interface{}
+}
+
+// StreamInfos describes a stream and the consumer groups reading it.
 type StreamInfos struct {
+	Length          uint64          `json:"length"`
+	FirstEntryId    string          `json:"firstEntryId"`
+	LastEntryId     string          `json:"lastEntryId"`
+	LastGeneratedId string          `json:"lastGeneratedId"`
+	Groups          []ConsumerGroup `json:"groups"`
+}
+
+type ConsumerGroup struct {
+	Name            string `json:"name"`
+	LastDeliveredId string `json:"lastDeliveredId"`
+	// Lag is the number of entries not delivered yet to the group, -1 when it cannot be determined.
+	Lag            int64            `json:"lag"`
+	Pending        uint64           `json:"pending"`
+	Consumers      []StreamConsumer `json:"consumers"`
+	PendingEntries []PendingEntry   `json:"pendingEntries,omitempty"`
+}
+
+type StreamConsumer struct {
+	Name    string `json:"name"`
+	Pending uint64 `json:"pending"`
+	// Idle is the time in milliseconds since the last interaction of the consumer.
+	Idle int64 `json:"idle"`
+}
+
+// PendingEntry is a message delivered to a consumer of a group but not acknowledged yet.
+type PendingEntry struct {
+	Id       string `json:"id"`
+	Consumer string `json:"consumer"`
+	// Idle is the time in milliseconds since the last delivery of the message.
+	Idle          int64 `json:"idle"`
+	DeliveryCount int64 `json:"deliveryCount"`
+}
+
+// PartitionInfos describes a partition of a topic, with the brokers hosting it and its range of offsets.
+type PartitionInfos struct {
+	Id             int32   `json:"id"`
+	Leader         int32   `json:"leader"`
+	Replicas       []int32 `json:"replicas"`
+	InSyncReplicas []int32 `json:"inSyncReplicas"`
+	// OldestOffset is the offset of the first available message, NewestOffset the one of the next message to be produced.
+	OldestOffset int64 `json:"oldestOffset"`
+	NewestOffset int64 `json:"newestOffset"`
+}
+
+// QueueInfos describes the depth of a queue and its consumers.
+type QueueInfos struct {
+	Messages       uint64 `json:"messages"`
+	Ready          uint64 `json:"ready"`
+	Unacknowledged uint64 `json:"unacknowledged"`
+	Consumers      uint64 `json:"consumers"`
+}
+
+// ExchangeInfos describes the kind of routing of an exchange and the count of its bindings.
+type ExchangeInfos struct {
+	Type     string `json:"type"`
+	Bindings uint64 `json:"bindings"`
+}
+
+// Expiry defines when an entry point expires: at ExpireAt if set, otherwise after TimeToLive if positive,
+// and never when both are zero.
+type Expiry struct {
+	TimeToLive time.Duration
+	ExpireAt   time.Time
+}
+
+// IsPersistent returns true when the expiry removes the time to live of the entry point.
+func (e Expiry) IsPersistent() bool {
+	return e.ExpireAt.IsZero() && e.TimeToLive <= 0
+}
+
+// Validate returns an error when the expiry is already reached, since it would delete the entry points
+// without any backup nor confirmation.
+func (e Expiry) Validate() error {
+	if !e.ExpireAt.IsZero() && !e.ExpireAt.After(time.Now()) {
+		return errors.New(fmt.Sprintf("the expiry %s is not in the future", e.ExpireAt.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// Progress reports the advancement of an operation applied to several entry points.
+type Progress struct {
+	Total     uint64 `json:"total"`
+	Processed uint64 `json:"processed"`
+	Failed    uint64 `json:"failed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ScanProgress reports the advancement of the scan of the nodes of a data source.
+type ScanProgress struct {
+	ScannedKeys  uint64 `json:"scannedKeys"`
+	ScannedNodes int    `json:"scannedNodes"`
+	Nodes        int    `json:"nodes"`
+}
+
+// DeletionPreview describes the children of an entry point, which would be deleted.
+type DeletionPreview struct {
+	Count  uint64       `json:"count"`
+	Sample []EntryPoint `json:"sample"`
+	// Total memory used by the children, in bytes.
+	Memory uint64 `json:"memory"`
 }
 
-type Filter struct {
+// EntryPointMove is a planned move of an entry point, Conflict is true when the target already exists.
+type EntryPointMove struct {
+	From     EntryPoint `json:"from"`
+	To       EntryPoint `json:"to"`
+	Conflict bool       `json:"conflict"`
+}
+
+// ScoredMember is a member of a sorted set with its score.
+type ScoredMember struct {
+	Member string  `json:"member" binding:"required"`
+	Score  float64 `json:"score"`
+}
+
+// StreamRange delimits a page of messages of a stream by their IDs, both bounds being inclusive.
+// Start and End default to the first and last messages, Count to the scan size of the vendor.
+type StreamRange struct {
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Count   int64  `json:"count"`
+	Reverse bool   `json:"reverse"`
+}
+
+// Kinds of ranges of sorted sets.
+const (
+	ByRank  = "rank"
+	ByScore = "score"
+	ByLex   = "lex"
+)
+
+// SortedSetRange delimits a window of the members of a sorted set, by rank, score or lexicographic order
+// as ZRANGE, ZRANGEBYSCORE and ZRANGEBYLEX do, ByRank by default. Start and Stop are the indexes of the ranks,
+// 0 and the end of the window of Count members by default. For the scores and the lexicographic ranges, they are
+// the lower and upper bounds, even in reverse order, in the syntax of Redis: "(1.5" or "+inf" for the scores,
+// "[a", "(a", "-" or "+" for the members, and the whole set by default. Offset and Count limit the scores
+// and lexicographic ranges, Count defaults to the scan size of the vendor.
+type SortedSetRange struct {
+	By      string `json:"by"`
+	Start   string `json:"start"`
+	Stop    string `json:"stop"`
+	Reverse bool   `json:"reverse"`
+	Offset  int64  `json:"offset"`
+	Count   int64  `json:"count"`
+}
+
+// FirstPage is the cursor of the first page of a content, which is also returned with the last page.
+const FirstPage = "0"
+
+// Page requests a window of the content of an entry point. Cursor is the one returned with the previous page,
+// FirstPage or empty to start, and Count the number of values, which defaults to the scan size of the vendor.
+// As for SCAN, the count is only a hint for the sets, sorted sets and hashes.
+type Page struct {
+	Cursor string `json:"cursor"`
+	Count  int64  `json:"count"`
+}
+
+// ContentPage is a window of the content of an entry point, with the cursor of the next page.
+// The cursor is FirstPage once the complete content was read.
+type ContentPage struct {
+	Size   uint64        `json:"size"`
+	Data   []interface{} `json:"data"`
+	Cursor string        `json:"cursor"`
 }
 
 type ClusterNode struct {
@@ -139,6 +383,13 @@ func CreateDataSource(source *DataSourceDescriptor) (DataSource, error) {
 	return dataSource, err
 }
 
+// TrashKeeper is implemented by the data sources keeping the backups of their entry points in a trash,
+// which is carried over to the data source replacing them.
+type TrashKeeper interface {
+	// Trash returns the trash of the data source, nil when it is disabled.
+	Trash() *Trash
+}
+
 // Vendor represents a parent interface for providers of data sources.
 type Vendor interface {
 	// Accept checks if the vendor is able to create a data source for the descriptor passed as parameter.
@@ -158,28 +409,120 @@ type DataSource interface {
 	Close()
 
 	// ListEntryPoints provides the full list of Redis keys, Kafka and RabbitMQ topics in the channel entrypoints.
-	// In order to provide a more flexible way of listing them, a pattern can be passed, with * and ? als wildcards.
-	ListEntryPoints(filter string, entrypoints chan<- DataBatch, minTreeLevel uint, maxTreeLevel uint) (ActionStatus, error)
+	// In order to provide a more flexible way of listing them, the glob pattern and regular expression of the filter apply to their names.
+	ListEntryPoints(ctx context.Context, filter Filter, entrypoints chan<- DataBatch, minTreeLevel uint, maxTreeLevel uint) (ActionStatus, error)
 
 	// GetEntryPointInfos returns the available details of the entrypoint: type, size...
-	GetEntryPointInfos(entryPointValue EntryPoint) (EntryPointInfos, error)
+	GetEntryPointInfos(ctx context.Context, entryPointValue EntryPoint) (EntryPointInfos, error)
 
 	// GetValue returns the unique value when entryPointValue is attached to only one value, like string values in Redis.
-	GetContent(entryPointValue EntryPoint, filter string, content chan<- DataBatch) (ActionStatus, error)
+	GetContent(ctx context.Context, entryPointValue EntryPoint, filter Filter, content chan<- DataBatch) (ActionStatus, error)
+
+	// GetContentPage returns a window of the content of the entry point, starting at the cursor of the page,
+	// in order to browse the large collections incrementally.
+	GetContentPage(ctx context.Context, entryPointValue EntryPoint, filter Filter, page Page) (ContentPage, error)
+
+	// GetStreamInfos returns the details of a stream and of its consumer groups, with at most pendingEntriesCount pending entries for each group.
+	GetStreamInfos(ctx context.Context, entryPointValue EntryPoint, pendingEntriesCount int64) (StreamInfos, error)
+
+	// GetStreamRange returns a page of the messages of a stream, in reverse order if specified in the range.
+	GetStreamRange(ctx context.Context, entryPointValue EntryPoint, streamRange StreamRange, content chan<- DataBatch) (ActionStatus, error)
+
+	// GetSortedSetRange returns a window of the members of a sorted set, in the order of the server, the consecutive
+	// members with the same score being grouped.
+	GetSortedSetRange(ctx context.Context, entryPointValue EntryPoint, sortedSetRange SortedSetRange, content chan<- DataBatch) (ActionStatus, error)
+
+	// AnalyzeEntryPoints walks all the entry points matching the filter of the analysis on all the nodes,
+	// and adds their samples to the analysis. It returns once the analysis is complete or cancelled.
+	AnalyzeEntryPoints(ctx context.Context, analysis *Analysis) error
+
+	DeleteEntrypoint(ctx context.Context, entryPointValue EntryPoint) error
+
+	// DeleteEntrypointChildren deletes all the children of the entry point, at most rateLimit keys per second
+	// if it is positive. The errors are added to the channel, which is closed once completed.
+	DeleteEntrypointChildren(ctx context.Context, entryPointValue EntryPoint, rateLimit uint, errorChannel chan<- error) (ActionStatus, error)
+
+	// PreviewEntrypointChildrenDeletion describes the children that DeleteEntrypointChildren would delete,
+	// with at most sampleSize of them.
+	PreviewEntrypointChildrenDeletion(ctx context.Context, entryPointValue EntryPoint, sampleSize int) (DeletionPreview, error)
+
+	// ListTrashEntries returns the backups of the entry points saved before they were deleted or changed.
+	ListTrashEntries(ctx context.Context) ([]TrashEntry, error)
+
+	// RestoreTrashEntry restores the entry point saved in the trash, replacing the current one only if replace is true.
+	RestoreTrashEntry(ctx context.Context, id string, replace bool) error
+
+	// RenameEntrypoint renames an entry point, the new entry point should not exist.
+	RenameEntrypoint(ctx context.Context, entryPointValue EntryPoint, newEntryPointValue EntryPoint) error
+
+	// MoveEntrypointTree moves the entry point and all its children under the new entry point.
+	// When dryRun is true, the planned EntryPointMove are added to the channel without any change, otherwise the Progress
+	// of the operation is added. The channel is closed once completed.
+	MoveEntrypointTree(ctx context.Context, entryPointValue EntryPoint, newEntryPointValue EntryPoint, dryRun bool, progress chan<- DataBatch) (ActionStatus, error)
+
+	// SetExpiry sets or removes the expiry of an existing entry point.
+	SetExpiry(ctx context.Context, entryPointValue EntryPoint, expiry Expiry) error
+
+	// SetChildrenExpiry sets or removes the expiry of all the children of the entry point
+	// and adds the Progress of the operation to the channel, which is closed once completed.
+	SetChildrenExpiry(ctx context.Context, entryPointValue EntryPoint, expiry Expiry, progress chan<- DataBatch) (ActionStatus, error)
+
+	// SetValue sets the value of a single-value entry point, creating it if required.
+	SetValue(ctx context.Context, entryPointValue EntryPoint, value string) error
+
+	// AddSetMembers adds the members to a set and returns the count of the new ones.
+	AddSetMembers(ctx context.Context, entryPointValue EntryPoint, members []string) (int64, error)
+
+	// RemoveSetMembers removes the members from a set and returns the count of the removed ones.
+	RemoveSetMembers(ctx context.Context, entryPointValue EntryPoint, members []string) (int64, error)
+
+	// AddSortedSetMembers adds the members to a sorted set or updates their scores, and returns the count of the new ones.
+	AddSortedSetMembers(ctx context.Context, entryPointValue EntryPoint, members []ScoredMember) (int64, error)
+
+	// SetHashFields sets the values of the fields of a hash.
+	SetHashFields(ctx context.Context, entryPointValue EntryPoint, fields map[string]string) error
+
+	// DeleteHashFields deletes the fields of a hash and returns the count of the deleted ones.
+	DeleteHashFields(ctx context.Context, entryPointValue EntryPoint, fields []string) (int64, error)
+
+	// PushListValues adds the values at the head or the tail of a list and returns its new length.
+	PushListValues(ctx context.Context, entryPointValue EntryPoint, values []string, head bool) (int64, error)
+
+	// SetListValue sets the value of the element of a list at the index.
+	SetListValue(ctx context.Context, entryPointValue EntryPoint, index int64, value string) error
+
+	// RemoveListValue removes count occurrences of the value from a list, all of them when count is 0,
+	// starting from the tail when count is negative. It returns the count of removed elements.
+	RemoveListValue(ctx context.Context, entryPointValue EntryPoint, value string, count int64) (int64, error)
+
+	// AddStreamMessage appends a message to a stream and returns its ID, the ID is generated when empty.
+	AddStreamMessage(ctx context.Context, entryPointValue EntryPoint, id string, fields map[string]interface{}) (string, error)
+
+	// DeleteStreamMessages deletes the messages of a stream and returns the count of the deleted ones.
+	DeleteStreamMessages(ctx context.Context, entryPointValue EntryPoint, ids []string) (int64, error)
+
+	// Consume consumes a stream or topic and add the accepted values to the channel, until the context is cancelled.
+	// The channel is closed when the consumption stops.
+	Consume(ctx context.Context, entryPointValue EntryPoint, values chan<- DataBatch, filter Filter, fromBeginning bool) (ActionStatus, error)
 
-	DeleteEntrypoint(entryPointValue EntryPoint) error
+	// ListChannels provides the active publish/subscribe channels, whose names match the filter, as ChannelNode.
+	ListChannels(ctx context.Context, filter Filter, channels chan<- DataBatch) (ActionStatus, error)
 
-	DeleteEntrypointChildren(entryPointValue EntryPoint, errorChannel chan<- error) (ActionStatus, error)
+	// Subscribe adds the messages published to the channel to the values, until the context is cancelled.
+	// When pattern is true, the channel is a glob pattern to subscribe to all the matching channels.
+	// The channel of values is closed when the subscription stops.
+	Subscribe(ctx context.Context, channel EntryPoint, pattern bool, values chan<- DataBatch) (ActionStatus, error)
 
-	// OpenStream consumes a stream or topic and add the accepted values to the channel.
-	Consume(entryPointValue EntryPoint, values chan<- DataBatch, filter Filter, fromBeginning bool) (ActionStatus, error)
+	// Watch adds the changes of the entry point and of its children to the events, until the context is cancelled.
+	// The channel of events is closed when the watch stops.
+	Watch(ctx context.Context, entryPointValue EntryPoint, events chan<- DataBatch) (ActionStatus, error)
 
 	// ExecuteCommand executes a native command and returns the result.
-	ExecuteCommand(args []interface{}, nodeID string) (interface{}, error)
+	ExecuteCommand(ctx context.Context, args []interface{}, nodeID string) (interface{}, error)
 
 	// GetInfos provides essential information about the data source.
-	GetInfos() (Cluster, error)
+	GetInfos(ctx context.Context) (Cluster, error)
 
 	// GetStatus provides essential status and health information about the data source.
-	GetStatus() (ClusterState, error)
+	GetStatus(ctx context.Context) (ClusterState, error)
 }
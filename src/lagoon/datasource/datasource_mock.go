@@ -5,6 +5,7 @@
 package datasource
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -32,6 +33,43 @@ func (m *MockSingleValue) EXPECT() *MockSingleValueMockRecorder {
 	return m.recorder
 }
 
+// MockDecodableValue is a mock of DecodableValue interface
+type MockDecodableValue struct {
+	ctrl     *gomock.Controller
+	recorder *MockDecodableValueMockRecorder
+}
+
+// MockDecodableValueMockRecorder is the mock recorder for MockDecodableValue
+type MockDecodableValueMockRecorder struct {
+	mock *MockDecodableValue
+}
+
+// NewMockDecodableValue creates a new mock instance
+func NewMockDecodableValue(ctrl *gomock.Controller) *MockDecodableValue {
+	mock := &MockDecodableValue{ctrl: ctrl}
+	mock.recorder = &MockDecodableValueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDecodableValue) EXPECT() *MockDecodableValueMockRecorder {
+	return m.recorder
+}
+
+// DecodeValues mocks base method
+func (m *MockDecodableValue) DecodeValues(decode func(value string) interface{}) interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeValues", decode)
+	ret0, _ := ret[0].(interface{})
+	return ret0
+}
+
+// DecodeValues indicates an expected call of DecodeValues
+func (mr *MockDecodableValueMockRecorder) DecodeValues(decode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeValues", reflect.TypeOf((*MockDecodableValue)(nil).DecodeValues), decode)
+}
+
 // MockVendor is a mock of Vendor interface
 type MockVendor struct {
 	ctrl     *gomock.Controller
@@ -134,135 +172,518 @@ func (mr *MockDataSourceMockRecorder) Close() *gomock.Call {
 }
 
 // ListEntryPoints mocks base method
-func (m *MockDataSource) ListEntryPoints(filter string, entrypoints chan<- DataBatch, minTreeLevel, maxTreeLevel uint) (ActionStatus, error) {
+func (m *MockDataSource) ListEntryPoints(ctx context.Context, filter Filter, entrypoints chan<- DataBatch, minTreeLevel, maxTreeLevel uint) (ActionStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntryPoints", filter, entrypoints, minTreeLevel, maxTreeLevel)
+	ret := m.ctrl.Call(m, "ListEntryPoints", ctx, filter, entrypoints, minTreeLevel, maxTreeLevel)
 	ret0, _ := ret[0].(ActionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListEntryPoints indicates an expected call of ListEntryPoints
-func (mr *MockDataSourceMockRecorder) ListEntryPoints(filter, entrypoints, minTreeLevel, maxTreeLevel interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) ListEntryPoints(ctx, filter, entrypoints, minTreeLevel, maxTreeLevel interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntryPoints", reflect.TypeOf((*MockDataSource)(nil).ListEntryPoints), filter, entrypoints, minTreeLevel, maxTreeLevel)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntryPoints", reflect.TypeOf((*MockDataSource)(nil).ListEntryPoints), ctx, filter, entrypoints, minTreeLevel, maxTreeLevel)
 }
 
 // GetEntryPointInfos mocks base method
-func (m *MockDataSource) GetEntryPointInfos(entryPointValue EntryPoint) (EntryPointInfos, error) {
+func (m *MockDataSource) GetEntryPointInfos(ctx context.Context, entryPointValue EntryPoint) (EntryPointInfos, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntryPointInfos", entryPointValue)
+	ret := m.ctrl.Call(m, "GetEntryPointInfos", ctx, entryPointValue)
 	ret0, _ := ret[0].(EntryPointInfos)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetEntryPointInfos indicates an expected call of GetEntryPointInfos
-func (mr *MockDataSourceMockRecorder) GetEntryPointInfos(entryPointValue interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) GetEntryPointInfos(ctx, entryPointValue interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryPointInfos", reflect.TypeOf((*MockDataSource)(nil).GetEntryPointInfos), entryPointValue)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryPointInfos", reflect.TypeOf((*MockDataSource)(nil).GetEntryPointInfos), ctx, entryPointValue)
 }
 
 // GetContent mocks base method
-func (m *MockDataSource) GetContent(entryPointValue EntryPoint, filter string, content chan<- DataBatch) (ActionStatus, error) {
+func (m *MockDataSource) GetContent(ctx context.Context, entryPointValue EntryPoint, filter Filter, content chan<- DataBatch) (ActionStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetContent", entryPointValue, filter, content)
+	ret := m.ctrl.Call(m, "GetContent", ctx, entryPointValue, filter, content)
 	ret0, _ := ret[0].(ActionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetContent indicates an expected call of GetContent
-func (mr *MockDataSourceMockRecorder) GetContent(entryPointValue, filter, content interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) GetContent(ctx, entryPointValue, filter, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContent", reflect.TypeOf((*MockDataSource)(nil).GetContent), ctx, entryPointValue, filter, content)
+}
+
+// GetContentPage mocks base method
+func (m *MockDataSource) GetContentPage(ctx context.Context, entryPointValue EntryPoint, filter Filter, page Page) (ContentPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContentPage", ctx, entryPointValue, filter, page)
+	ret0, _ := ret[0].(ContentPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContentPage indicates an expected call of GetContentPage
+func (mr *MockDataSourceMockRecorder) GetContentPage(ctx, entryPointValue, filter, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContentPage", reflect.TypeOf((*MockDataSource)(nil).GetContentPage), ctx, entryPointValue, filter, page)
+}
+
+// GetStreamInfos mocks base method
+func (m *MockDataSource) GetStreamInfos(ctx context.Context, entryPointValue EntryPoint, pendingEntriesCount int64) (StreamInfos, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStreamInfos", ctx, entryPointValue, pendingEntriesCount)
+	ret0, _ := ret[0].(StreamInfos)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStreamInfos indicates an expected call of GetStreamInfos
+func (mr *MockDataSourceMockRecorder) GetStreamInfos(ctx, entryPointValue, pendingEntriesCount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStreamInfos", reflect.TypeOf((*MockDataSource)(nil).GetStreamInfos), ctx, entryPointValue, pendingEntriesCount)
+}
+
+// GetStreamRange mocks base method
+func (m *MockDataSource) GetStreamRange(ctx context.Context, entryPointValue EntryPoint, streamRange StreamRange, content chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStreamRange", ctx, entryPointValue, streamRange, content)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStreamRange indicates an expected call of GetStreamRange
+func (mr *MockDataSourceMockRecorder) GetStreamRange(ctx, entryPointValue, streamRange, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStreamRange", reflect.TypeOf((*MockDataSource)(nil).GetStreamRange), ctx, entryPointValue, streamRange, content)
+}
+
+// GetSortedSetRange mocks base method
+func (m *MockDataSource) GetSortedSetRange(ctx context.Context, entryPointValue EntryPoint, sortedSetRange SortedSetRange, content chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSortedSetRange", ctx, entryPointValue, sortedSetRange, content)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSortedSetRange indicates an expected call of GetSortedSetRange
+func (mr *MockDataSourceMockRecorder) GetSortedSetRange(ctx, entryPointValue, sortedSetRange, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSortedSetRange", reflect.TypeOf((*MockDataSource)(nil).GetSortedSetRange), ctx, entryPointValue, sortedSetRange, content)
+}
+
+// AnalyzeEntryPoints mocks base method
+func (m *MockDataSource) AnalyzeEntryPoints(ctx context.Context, analysis *Analysis) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalyzeEntryPoints", ctx, analysis)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnalyzeEntryPoints indicates an expected call of AnalyzeEntryPoints
+func (mr *MockDataSourceMockRecorder) AnalyzeEntryPoints(ctx, analysis interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContent", reflect.TypeOf((*MockDataSource)(nil).GetContent), entryPointValue, filter, content)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyzeEntryPoints", reflect.TypeOf((*MockDataSource)(nil).AnalyzeEntryPoints), ctx, analysis)
 }
 
 // DeleteEntrypoint mocks base method
-func (m *MockDataSource) DeleteEntrypoint(entryPointValue EntryPoint) error {
+func (m *MockDataSource) DeleteEntrypoint(ctx context.Context, entryPointValue EntryPoint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEntrypoint", entryPointValue)
+	ret := m.ctrl.Call(m, "DeleteEntrypoint", ctx, entryPointValue)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteEntrypoint indicates an expected call of DeleteEntrypoint
-func (mr *MockDataSourceMockRecorder) DeleteEntrypoint(entryPointValue interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) DeleteEntrypoint(ctx, entryPointValue interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntrypoint", reflect.TypeOf((*MockDataSource)(nil).DeleteEntrypoint), entryPointValue)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntrypoint", reflect.TypeOf((*MockDataSource)(nil).DeleteEntrypoint), ctx, entryPointValue)
 }
 
 // DeleteEntrypointChildren mocks base method
-func (m *MockDataSource) DeleteEntrypointChildren(entryPointValue EntryPoint, errorChannel chan<- error) (ActionStatus, error) {
+func (m *MockDataSource) DeleteEntrypointChildren(ctx context.Context, entryPointValue EntryPoint, rateLimit uint, errorChannel chan<- error) (ActionStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEntrypointChildren", entryPointValue, errorChannel)
+	ret := m.ctrl.Call(m, "DeleteEntrypointChildren", ctx, entryPointValue, rateLimit, errorChannel)
 	ret0, _ := ret[0].(ActionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteEntrypointChildren indicates an expected call of DeleteEntrypointChildren
-func (mr *MockDataSourceMockRecorder) DeleteEntrypointChildren(entryPointValue, errorChannel interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) DeleteEntrypointChildren(ctx, entryPointValue, rateLimit, errorChannel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntrypointChildren", reflect.TypeOf((*MockDataSource)(nil).DeleteEntrypointChildren), ctx, entryPointValue, rateLimit, errorChannel)
+}
+
+// PreviewEntrypointChildrenDeletion mocks base method
+func (m *MockDataSource) PreviewEntrypointChildrenDeletion(ctx context.Context, entryPointValue EntryPoint, sampleSize int) (DeletionPreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewEntrypointChildrenDeletion", ctx, entryPointValue, sampleSize)
+	ret0, _ := ret[0].(DeletionPreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewEntrypointChildrenDeletion indicates an expected call of PreviewEntrypointChildrenDeletion
+func (mr *MockDataSourceMockRecorder) PreviewEntrypointChildrenDeletion(ctx, entryPointValue, sampleSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewEntrypointChildrenDeletion", reflect.TypeOf((*MockDataSource)(nil).PreviewEntrypointChildrenDeletion), ctx, entryPointValue, sampleSize)
+}
+
+// ListTrashEntries mocks base method
+func (m *MockDataSource) ListTrashEntries(ctx context.Context) ([]TrashEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrashEntries", ctx)
+	ret0, _ := ret[0].([]TrashEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrashEntries indicates an expected call of ListTrashEntries
+func (mr *MockDataSourceMockRecorder) ListTrashEntries(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntrypointChildren", reflect.TypeOf((*MockDataSource)(nil).DeleteEntrypointChildren), entryPointValue, errorChannel)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrashEntries", reflect.TypeOf((*MockDataSource)(nil).ListTrashEntries), ctx)
+}
+
+// RestoreTrashEntry mocks base method
+func (m *MockDataSource) RestoreTrashEntry(ctx context.Context, id string, replace bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTrashEntry", ctx, id, replace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTrashEntry indicates an expected call of RestoreTrashEntry
+func (mr *MockDataSourceMockRecorder) RestoreTrashEntry(ctx, id, replace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTrashEntry", reflect.TypeOf((*MockDataSource)(nil).RestoreTrashEntry), ctx, id, replace)
+}
+
+// RenameEntrypoint mocks base method
+func (m *MockDataSource) RenameEntrypoint(ctx context.Context, entryPointValue, newEntryPointValue EntryPoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameEntrypoint", ctx, entryPointValue, newEntryPointValue)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameEntrypoint indicates an expected call of RenameEntrypoint
+func (mr *MockDataSourceMockRecorder) RenameEntrypoint(ctx, entryPointValue, newEntryPointValue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameEntrypoint", reflect.TypeOf((*MockDataSource)(nil).RenameEntrypoint), ctx, entryPointValue, newEntryPointValue)
+}
+
+// MoveEntrypointTree mocks base method
+func (m *MockDataSource) MoveEntrypointTree(ctx context.Context, entryPointValue, newEntryPointValue EntryPoint, dryRun bool, progress chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveEntrypointTree", ctx, entryPointValue, newEntryPointValue, dryRun, progress)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveEntrypointTree indicates an expected call of MoveEntrypointTree
+func (mr *MockDataSourceMockRecorder) MoveEntrypointTree(ctx, entryPointValue, newEntryPointValue, dryRun, progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveEntrypointTree", reflect.TypeOf((*MockDataSource)(nil).MoveEntrypointTree), ctx, entryPointValue, newEntryPointValue, dryRun, progress)
+}
+
+// SetExpiry mocks base method
+func (m *MockDataSource) SetExpiry(ctx context.Context, entryPointValue EntryPoint, expiry Expiry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetExpiry", ctx, entryPointValue, expiry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetExpiry indicates an expected call of SetExpiry
+func (mr *MockDataSourceMockRecorder) SetExpiry(ctx, entryPointValue, expiry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExpiry", reflect.TypeOf((*MockDataSource)(nil).SetExpiry), ctx, entryPointValue, expiry)
+}
+
+// SetChildrenExpiry mocks base method
+func (m *MockDataSource) SetChildrenExpiry(ctx context.Context, entryPointValue EntryPoint, expiry Expiry, progress chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChildrenExpiry", ctx, entryPointValue, expiry, progress)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetChildrenExpiry indicates an expected call of SetChildrenExpiry
+func (mr *MockDataSourceMockRecorder) SetChildrenExpiry(ctx, entryPointValue, expiry, progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChildrenExpiry", reflect.TypeOf((*MockDataSource)(nil).SetChildrenExpiry), ctx, entryPointValue, expiry, progress)
+}
+
+// SetValue mocks base method
+func (m *MockDataSource) SetValue(ctx context.Context, entryPointValue EntryPoint, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetValue", ctx, entryPointValue, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetValue indicates an expected call of SetValue
+func (mr *MockDataSourceMockRecorder) SetValue(ctx, entryPointValue, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetValue", reflect.TypeOf((*MockDataSource)(nil).SetValue), ctx, entryPointValue, value)
+}
+
+// AddSetMembers mocks base method
+func (m *MockDataSource) AddSetMembers(ctx context.Context, entryPointValue EntryPoint, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSetMembers", ctx, entryPointValue, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSetMembers indicates an expected call of AddSetMembers
+func (mr *MockDataSourceMockRecorder) AddSetMembers(ctx, entryPointValue, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSetMembers", reflect.TypeOf((*MockDataSource)(nil).AddSetMembers), ctx, entryPointValue, members)
+}
+
+// RemoveSetMembers mocks base method
+func (m *MockDataSource) RemoveSetMembers(ctx context.Context, entryPointValue EntryPoint, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSetMembers", ctx, entryPointValue, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveSetMembers indicates an expected call of RemoveSetMembers
+func (mr *MockDataSourceMockRecorder) RemoveSetMembers(ctx, entryPointValue, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSetMembers", reflect.TypeOf((*MockDataSource)(nil).RemoveSetMembers), ctx, entryPointValue, members)
+}
+
+// AddSortedSetMembers mocks base method
+func (m *MockDataSource) AddSortedSetMembers(ctx context.Context, entryPointValue EntryPoint, members []ScoredMember) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSortedSetMembers", ctx, entryPointValue, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSortedSetMembers indicates an expected call of AddSortedSetMembers
+func (mr *MockDataSourceMockRecorder) AddSortedSetMembers(ctx, entryPointValue, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSortedSetMembers", reflect.TypeOf((*MockDataSource)(nil).AddSortedSetMembers), ctx, entryPointValue, members)
+}
+
+// SetHashFields mocks base method
+func (m *MockDataSource) SetHashFields(ctx context.Context, entryPointValue EntryPoint, fields map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHashFields", ctx, entryPointValue, fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHashFields indicates an expected call of SetHashFields
+func (mr *MockDataSourceMockRecorder) SetHashFields(ctx, entryPointValue, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHashFields", reflect.TypeOf((*MockDataSource)(nil).SetHashFields), ctx, entryPointValue, fields)
+}
+
+// DeleteHashFields mocks base method
+func (m *MockDataSource) DeleteHashFields(ctx context.Context, entryPointValue EntryPoint, fields []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHashFields", ctx, entryPointValue, fields)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteHashFields indicates an expected call of DeleteHashFields
+func (mr *MockDataSourceMockRecorder) DeleteHashFields(ctx, entryPointValue, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHashFields", reflect.TypeOf((*MockDataSource)(nil).DeleteHashFields), ctx, entryPointValue, fields)
+}
+
+// PushListValues mocks base method
+func (m *MockDataSource) PushListValues(ctx context.Context, entryPointValue EntryPoint, values []string, head bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushListValues", ctx, entryPointValue, values, head)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushListValues indicates an expected call of PushListValues
+func (mr *MockDataSourceMockRecorder) PushListValues(ctx, entryPointValue, values, head interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushListValues", reflect.TypeOf((*MockDataSource)(nil).PushListValues), ctx, entryPointValue, values, head)
+}
+
+// SetListValue mocks base method
+func (m *MockDataSource) SetListValue(ctx context.Context, entryPointValue EntryPoint, index int64, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetListValue", ctx, entryPointValue, index, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetListValue indicates an expected call of SetListValue
+func (mr *MockDataSourceMockRecorder) SetListValue(ctx, entryPointValue, index, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetListValue", reflect.TypeOf((*MockDataSource)(nil).SetListValue), ctx, entryPointValue, index, value)
+}
+
+// RemoveListValue mocks base method
+func (m *MockDataSource) RemoveListValue(ctx context.Context, entryPointValue EntryPoint, value string, count int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveListValue", ctx, entryPointValue, value, count)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveListValue indicates an expected call of RemoveListValue
+func (mr *MockDataSourceMockRecorder) RemoveListValue(ctx, entryPointValue, value, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveListValue", reflect.TypeOf((*MockDataSource)(nil).RemoveListValue), ctx, entryPointValue, value, count)
+}
+
+// AddStreamMessage mocks base method
+func (m *MockDataSource) AddStreamMessage(ctx context.Context, entryPointValue EntryPoint, id string, fields map[string]interface{}) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStreamMessage", ctx, entryPointValue, id, fields)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddStreamMessage indicates an expected call of AddStreamMessage
+func (mr *MockDataSourceMockRecorder) AddStreamMessage(ctx, entryPointValue, id, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStreamMessage", reflect.TypeOf((*MockDataSource)(nil).AddStreamMessage), ctx, entryPointValue, id, fields)
+}
+
+// DeleteStreamMessages mocks base method
+func (m *MockDataSource) DeleteStreamMessages(ctx context.Context, entryPointValue EntryPoint, ids []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStreamMessages", ctx, entryPointValue, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStreamMessages indicates an expected call of DeleteStreamMessages
+func (mr *MockDataSourceMockRecorder) DeleteStreamMessages(ctx, entryPointValue, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStreamMessages", reflect.TypeOf((*MockDataSource)(nil).DeleteStreamMessages), ctx, entryPointValue, ids)
 }
 
 // Consume mocks base method
-func (m *MockDataSource) Consume(entryPointValue EntryPoint, values chan<- DataBatch, filter Filter, fromBeginning bool) (ActionStatus, error) {
+func (m *MockDataSource) Consume(ctx context.Context, entryPointValue EntryPoint, values chan<- DataBatch, filter Filter, fromBeginning bool) (ActionStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Consume", entryPointValue, values, filter, fromBeginning)
+	ret := m.ctrl.Call(m, "Consume", ctx, entryPointValue, values, filter, fromBeginning)
 	ret0, _ := ret[0].(ActionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Consume indicates an expected call of Consume
-func (mr *MockDataSourceMockRecorder) Consume(entryPointValue, values, filter, fromBeginning interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) Consume(ctx, entryPointValue, values, filter, fromBeginning interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockDataSource)(nil).Consume), ctx, entryPointValue, values, filter, fromBeginning)
+}
+
+// ListChannels mocks base method
+func (m *MockDataSource) ListChannels(ctx context.Context, filter Filter, channels chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChannels", ctx, filter, channels)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChannels indicates an expected call of ListChannels
+func (mr *MockDataSourceMockRecorder) ListChannels(ctx, filter, channels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChannels", reflect.TypeOf((*MockDataSource)(nil).ListChannels), ctx, filter, channels)
+}
+
+// Subscribe mocks base method
+func (m *MockDataSource) Subscribe(ctx context.Context, channel EntryPoint, pattern bool, values chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, channel, pattern, values)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockDataSourceMockRecorder) Subscribe(ctx, channel, pattern, values interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockDataSource)(nil).Subscribe), ctx, channel, pattern, values)
+}
+
+// Watch mocks base method
+func (m *MockDataSource) Watch(ctx context.Context, entryPointValue EntryPoint, events chan<- DataBatch) (ActionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, entryPointValue, events)
+	ret0, _ := ret[0].(ActionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockDataSourceMockRecorder) Watch(ctx, entryPointValue, events interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockDataSource)(nil).Consume), entryPointValue, values, filter, fromBeginning)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockDataSource)(nil).Watch), ctx, entryPointValue, events)
 }
 
 // ExecuteCommand mocks base method
-func (m *MockDataSource) ExecuteCommand(args []interface{}, nodeID string) (interface{}, error) {
+func (m *MockDataSource) ExecuteCommand(ctx context.Context, args []interface{}, nodeID string) (interface{}, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecuteCommand", args, nodeID)
+	ret := m.ctrl.Call(m, "ExecuteCommand", ctx, args, nodeID)
 	ret0, _ := ret[0].(interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExecuteCommand indicates an expected call of ExecuteCommand
-func (mr *MockDataSourceMockRecorder) ExecuteCommand(args, nodeID interface{}) *gomock.Call {
+func (mr *MockDataSourceMockRecorder) ExecuteCommand(ctx, args, nodeID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCommand", reflect.TypeOf((*MockDataSource)(nil).ExecuteCommand), args, nodeID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCommand", reflect.TypeOf((*MockDataSource)(nil).ExecuteCommand), ctx, args, nodeID)
 }
 
 // GetInfos mocks base method
-func (m *MockDataSource) GetInfos() (Cluster, error) {
+func (m *MockDataSource) GetInfos(ctx context.Context) (Cluster, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInfos")
+	ret := m.ctrl.Call(m, "GetInfos", ctx)
 	ret0, _ := ret[0].(Cluster)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInfos indicates an expected call of GetInfos
-func (mr *MockDataSourceMockRecorder) GetInfos() *gomock.Call {
+func (mr *MockDataSourceMockRecorder) GetInfos(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInfos", reflect.TypeOf((*MockDataSource)(nil).GetInfos))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInfos", reflect.TypeOf((*MockDataSource)(nil).GetInfos), ctx)
 }
 
 // GetStatus mocks base method
-func (m *MockDataSource) GetStatus() (ClusterState, error) {
+func (m *MockDataSource) GetStatus(ctx context.Context) (ClusterState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetStatus")
+	ret := m.ctrl.Call(m, "GetStatus", ctx)
 	ret0, _ := ret[0].(ClusterState)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetStatus indicates an expected call of GetStatus
-func (mr *MockDataSourceMockRecorder) GetStatus() *gomock.Call {
+func (mr *MockDataSourceMockRecorder) GetStatus(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockDataSource)(nil).GetStatus))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockDataSource)(nil).GetStatus), ctx)
 }
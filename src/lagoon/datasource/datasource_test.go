@@ -4,6 +4,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestDeclareImplementation(t *testing.T) {
@@ -55,3 +56,39 @@ func TestCreateDataSourceWhenOneAccepts(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, result == datasource)
 }
+
+func TestTimeoutFromConfiguration(t *testing.T) {
+	// when
+	defaultTimeout, err := TimeoutFromConfiguration(map[string]string{})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultTimeout, defaultTimeout)
+
+	// when
+	timeout, err := TimeoutFromConfiguration(map[string]string{"timeout": "30s"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	// when
+	disabledTimeout, err := TimeoutFromConfiguration(map[string]string{"timeout": "0"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), disabledTimeout)
+
+	// when
+	_, err = TimeoutFromConfiguration(map[string]string{"timeout": "soon"})
+
+	// then
+	assert.EqualError(t, err, "the timeout 'soon' is not valid")
+}
+
+func TestExpiryValidate(t *testing.T) {
+	assert.Nil(t, Expiry{}.Validate())
+	assert.Nil(t, Expiry{TimeToLive: time.Minute}.Validate())
+	assert.Nil(t, Expiry{ExpireAt: time.Now().Add(time.Minute)}.Validate())
+	assert.EqualError(t, Expiry{ExpireAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}.Validate(), "the expiry 2020-01-01T00:00:00Z is not in the future")
+}
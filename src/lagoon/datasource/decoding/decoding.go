@@ -0,0 +1,300 @@
+// Package decoding makes the values of the contents binary-safe, by sending their raw bytes with their detected
+// encoding, and decodes them with a pipeline of decoders selected for the data source or the prefix of the keys.
+package decoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ugorji/go/codec"
+	"io/ioutil"
+	"lagoon/datasource"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Detected encodings of the raw values.
+const (
+	Text             = "text"
+	Json             = "json"
+	Gzip             = "gzip"
+	Zstd             = "zstd"
+	JavaSerialized   = "java-serialized"
+	Binary           = "binary"
+	decodersKey      = "decoders"
+	descriptorSetKey = "protobufDescriptorSet"
+)
+
+var (
+	gzipMagic           = []byte{0x1f, 0x8b}
+	zstdMagic           = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	javaSerializedMagic = []byte{0xac, 0xed, 0x00, 0x05}
+)
+
+// Value is a value of a content as sent to the clients: its raw bytes, encoded in base64 in JSON, its text
+// when the bytes are valid UTF-8, and the result of the decoders if any.
+type Value struct {
+	Raw      []byte      `json:"raw"`
+	Encoding string      `json:"encoding"`
+	Text     string      `json:"text,omitempty"`
+	Decoded  interface{} `json:"decoded,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// Detect returns the encoding of the raw value, from the magic numbers of the compression and serialization formats.
+func Detect(raw []byte) string {
+	switch {
+	case bytes.HasPrefix(raw, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(raw, zstdMagic):
+		return Zstd
+	case bytes.HasPrefix(raw, javaSerializedMagic):
+		return JavaSerialized
+	case !utf8.Valid(raw):
+		return Binary
+	case len(raw) > 0 && json.Valid(raw) && (raw[0] == '{' || raw[0] == '['):
+		return Json
+	default:
+		return Text
+	}
+}
+
+// Decoder transforms a value, either into bytes which can be decoded by the next decoder of the pipeline,
+// or into a structured value, which ends the pipeline.
+type Decoder interface {
+	Name() string
+	Decode(data []byte) (interface{}, error)
+}
+
+// Pipeline is a sequence of decoders, each of them decoding the bytes returned by the previous one.
+type Pipeline []Decoder
+
+// Decode converts the raw value with its detected encoding and the result of the pipeline.
+func (p Pipeline) Decode(raw []byte) Value {
+	value := Value{Raw: raw, Encoding: Detect(raw)}
+	if value.Encoding == Text || value.Encoding == Json {
+		value.Text = string(raw)
+	}
+	if len(p) == 0 {
+		return value
+	}
+
+	var result interface{} = raw
+	for i, decoder := range p {
+		data, ok := result.([]byte)
+		if !ok {
+			value.Error = fmt.Sprintf("the decoder %s cannot follow %s, which does not return bytes", decoder.Name(), p[i-1].Name())
+			return value
+		}
+		decoded, err := decoder.Decode(data)
+		if err != nil {
+			value.Error = fmt.Sprintf("the decoder %s failed: %s", decoder.Name(), err.Error())
+			return value
+		}
+		result = decoded
+	}
+	// The bytes returned by the last decoder are sent as text when possible, otherwise in base64.
+	if data, ok := result.([]byte); ok && utf8.Valid(data) {
+		result = string(data)
+	}
+	value.Decoded = result
+	return value
+}
+
+// DecodeBatch decodes the values of a batch of a content: the strings, and the ones of the values implementing
+// datasource.DecodableValue, the other values being kept as is.
+func (p Pipeline) DecodeBatch(batch datasource.DataBatch) datasource.DataBatch {
+	if len(batch.Data) == 0 {
+		return batch
+	}
+	decodeString := func(value string) interface{} {
+		return p.Decode([]byte(value))
+	}
+	data := make([]interface{}, len(batch.Data))
+	for i, value := range batch.Data {
+		switch v := value.(type) {
+		case string:
+			data[i] = decodeString(v)
+		case datasource.DecodableValue:
+			data[i] = v.DecodeValues(decodeString)
+		default:
+			data[i] = value
+		}
+	}
+	batch.Data = data
+	return batch
+}
+
+// Decoders selects the pipeline of the values of each entry point, by the longest prefix of its key configured
+// with decoders[<prefix>], or the one of the data source configured with decoders.
+type Decoders struct {
+	defaultPipeline Pipeline
+	prefixes        []prefixedPipeline
+	descriptors     *protobufDescriptors
+}
+
+type prefixedPipeline struct {
+	prefix   string
+	pipeline Pipeline
+}
+
+// FromConfiguration creates the decoders of a data source from its configuration:
+// decoders and decoders[<prefix>] are comma-separated lists of hex, base64, json, gzip, zstd, msgpack
+// and protobuf:<message type>, the types of messages being read from the file protobufDescriptorSet.
+func FromConfiguration(configuration map[string]string) (*Decoders, error) {
+	decoders := &Decoders{}
+	if path := configuration[descriptorSetKey]; path != "" {
+		descriptors, err := loadDescriptorSet(path)
+		if err != nil {
+			return nil, err
+		}
+		decoders.descriptors = descriptors
+	}
+
+	for key, spec := range configuration {
+		if key == decodersKey {
+			pipeline, err := decoders.Parse(spec)
+			if err != nil {
+				return nil, err
+			}
+			decoders.defaultPipeline = pipeline
+		} else if strings.HasPrefix(key, decodersKey+"[") && strings.HasSuffix(key, "]") {
+			pipeline, err := decoders.Parse(spec)
+			if err != nil {
+				return nil, err
+			}
+			prefix := key[len(decodersKey)+1 : len(key)-1]
+			decoders.prefixes = append(decoders.prefixes, prefixedPipeline{prefix: prefix, pipeline: pipeline})
+		}
+	}
+	// The longest prefixes are evaluated first.
+	sort.Slice(decoders.prefixes, func(i, j int) bool {
+		return len(decoders.prefixes[i].prefix) > len(decoders.prefixes[j].prefix)
+	})
+	return decoders, nil
+}
+
+// PipelineOf returns the pipeline applying to the values of the key.
+func (d *Decoders) PipelineOf(key string) Pipeline {
+	if d == nil {
+		return nil
+	}
+	for _, prefixed := range d.prefixes {
+		if strings.HasPrefix(key, prefixed.prefix) {
+			return prefixed.pipeline
+		}
+	}
+	return d.defaultPipeline
+}
+
+// Parse creates a pipeline from a comma-separated list of decoders.
+func (d *Decoders) Parse(spec string) (Pipeline, error) {
+	var pipeline Pipeline
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		decoder, err := d.decoder(name)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, decoder)
+	}
+	return pipeline, nil
+}
+
+func (d *Decoders) decoder(name string) (Decoder, error) {
+	switch name {
+	case "hex":
+		return funcDecoder{name: name, decode: hexDecode}, nil
+	case "base64":
+		return funcDecoder{name: name, decode: base64Decode}, nil
+	case "json":
+		return funcDecoder{name: name, decode: jsonPrettyPrint}, nil
+	case "gzip":
+		return funcDecoder{name: name, decode: gunzip}, nil
+	case "zstd":
+		return funcDecoder{name: name, decode: unzstd}, nil
+	case "msgpack":
+		return funcDecoder{name: name, decode: msgpackDecode}, nil
+	}
+	if strings.HasPrefix(name, "protobuf:") {
+		if d == nil || d.descriptors == nil {
+			return nil, errors.New(fmt.Sprintf("the decoder %s requires the configuration %s", name, descriptorSetKey))
+		}
+		return d.descriptors.decoder(strings.TrimPrefix(name, "protobuf:"))
+	}
+	return nil, errors.New(fmt.Sprintf("the decoder '%s' is unknown", name))
+}
+
+type funcDecoder struct {
+	name   string
+	decode func(data []byte) (interface{}, error)
+}
+
+func (f funcDecoder) Name() string {
+	return f.name
+}
+
+func (f funcDecoder) Decode(data []byte) (interface{}, error) {
+	return f.decode(data)
+}
+
+// hexDecode decodes the values stored as hexadecimal text.
+func hexDecode(data []byte) (interface{}, error) {
+	result := make([]byte, hex.DecodedLen(len(bytes.TrimSpace(data))))
+	_, err := hex.Decode(result, bytes.TrimSpace(data))
+	return result, err
+}
+
+// base64Decode decodes the values stored as base64 text.
+func base64Decode(data []byte) (interface{}, error) {
+	return base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+}
+
+func jsonPrettyPrint(data []byte) (interface{}, error) {
+	var result bytes.Buffer
+	if err := json.Indent(&result, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return result.String(), nil
+}
+
+func gunzip(data []byte) (interface{}, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+func unzstd(data []byte) (interface{}, error) {
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	return decoder.DecodeAll(data, nil)
+}
+
+var msgpackHandle = func() *codec.MsgpackHandle {
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return handle
+}()
+
+func msgpackDecode(data []byte) (interface{}, error) {
+	var result interface{}
+	err := codec.NewDecoderBytes(data, msgpackHandle).Decode(&result)
+	return result, err
+}
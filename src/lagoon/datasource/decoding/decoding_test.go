@@ -0,0 +1,293 @@
+package decoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"io/ioutil"
+	"lagoon/datasource"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, Text, Detect([]byte("my-value")))
+	assert.Equal(t, Text, Detect([]byte{}))
+	assert.Equal(t, Json, Detect([]byte("{\"a\":1}")))
+	assert.Equal(t, Text, Detect([]byte("12")))
+	assert.Equal(t, Gzip, Detect(gzipped(t, []byte("my-value"))))
+	assert.Equal(t, Zstd, Detect(zstdCompressed(t, []byte("my-value"))))
+	assert.Equal(t, JavaSerialized, Detect([]byte{0xac, 0xed, 0x00, 0x05, 0x73, 0x72}))
+	assert.Equal(t, Binary, Detect([]byte{0xff, 0xfe, 0x00}))
+}
+
+func TestDecodeWithoutPipeline(t *testing.T) {
+	// when
+	value := Pipeline(nil).Decode([]byte{0xff, 0x01})
+
+	// then
+	assert.Equal(t, Value{Raw: []byte{0xff, 0x01}, Encoding: Binary}, value)
+
+	// when
+	value = Pipeline(nil).Decode([]byte("my-value"))
+
+	// then
+	assert.Equal(t, Value{Raw: []byte("my-value"), Encoding: Text, Text: "my-value"}, value)
+}
+
+func TestDecodeWithPipelines(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		raw      []byte
+		encoding string
+		decoded  interface{}
+	}{
+		{"hex", "hex", []byte("6d792d76616c7565"), Text, "my-value"},
+		{"base64", "base64", []byte("bXktdmFsdWU=\n"), Text, "my-value"},
+		{"json", "json", []byte("{\"a\":1}"), Json, "{\n  \"a\": 1\n}"},
+		{"gzip", "gzip", gzipped(t, []byte("my-value")), Gzip, "my-value"},
+		{"zstd", "zstd", zstdCompressed(t, []byte("my-value")), Zstd, "my-value"},
+		{"base64 then gzip then json", "base64, gzip, json", []byte(base64.StdEncoding.EncodeToString(gzipped(t, []byte("{\"a\":1}")))), Text, "{\n  \"a\": 1\n}"},
+		{"gzip of binary", "gzip", gzipped(t, []byte{0xff, 0x01}), Gzip, []byte{0xff, 0x01}},
+		{"msgpack", "msgpack", msgpacked(t, map[string]interface{}{"name": "bob", "age": 42}), Binary,
+			map[string]interface{}{"name": "bob", "age": int64(42)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			pipeline, err := (*Decoders)(nil).Parse(tt.spec)
+			assert.Nil(t, err)
+
+			// when
+			value := pipeline.Decode(tt.raw)
+
+			// then
+			assert.Equal(t, tt.raw, value.Raw)
+			assert.Equal(t, tt.encoding, value.Encoding)
+			assert.Equal(t, "", value.Error)
+			assert.Equal(t, tt.decoded, value.Decoded)
+		})
+	}
+}
+
+func TestDecodeWithFailingPipeline(t *testing.T) {
+	// given
+	pipeline, err := (*Decoders)(nil).Parse("gzip")
+	assert.Nil(t, err)
+
+	// when
+	value := pipeline.Decode([]byte("my-value"))
+
+	// then
+	assert.Equal(t, Text, value.Encoding)
+	assert.Equal(t, "my-value", value.Text)
+	assert.Nil(t, value.Decoded)
+	assert.Contains(t, value.Error, "the decoder gzip failed")
+
+	// given
+	pipeline, err = (*Decoders)(nil).Parse("msgpack,gzip")
+	assert.Nil(t, err)
+
+	// when
+	value = pipeline.Decode(msgpacked(t, "my-value"))
+
+	// then
+	assert.Equal(t, "the decoder gzip cannot follow msgpack, which does not return bytes", value.Error)
+}
+
+func TestDecodeBatch(t *testing.T) {
+	// given
+	pipeline, _ := (*Decoders)(nil).Parse("base64")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	decodable := datasource.NewMockDecodableValue(ctrl)
+	decodable.EXPECT().DecodeValues(gomock.Any()).DoAndReturn(func(decode func(value string) interface{}) interface{} {
+		return decode("d29ybGQ=")
+	}).Times(1)
+
+	// when
+	batch := pipeline.DecodeBatch(datasource.DataBatch{Size: 3, Data: []interface{}{"aGVsbG8=", decodable, 42}})
+
+	// then
+	assert.Equal(t, uint64(3), batch.Size)
+	assert.Equal(t, "hello", batch.Data[0].(Value).Decoded)
+	assert.Equal(t, "world", batch.Data[1].(Value).Decoded)
+	assert.Equal(t, 42, batch.Data[2])
+}
+
+func TestParseUnknownDecoder(t *testing.T) {
+	// when
+	_, err := (*Decoders)(nil).Parse("hex,rot13")
+
+	// then
+	assert.EqualError(t, err, "the decoder 'rot13' is unknown")
+
+	// when
+	_, err = (*Decoders)(nil).Parse("protobuf:demo.User")
+
+	// then
+	assert.EqualError(t, err, "the decoder protobuf:demo.User requires the configuration protobufDescriptorSet")
+}
+
+func TestFromConfigurationSelectsThePipelineByPrefix(t *testing.T) {
+	// when
+	decoders, err := FromConfiguration(map[string]string{
+		"decoders":               "base64",
+		"decoders[sessions:]":    "gzip",
+		"decoders[sessions:v2:]": "zstd",
+		"other":                  "value",
+	})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "base64", names(decoders.PipelineOf("users:1")))
+	assert.Equal(t, "gzip", names(decoders.PipelineOf("sessions:1")))
+	assert.Equal(t, "zstd", names(decoders.PipelineOf("sessions:v2:1")))
+	assert.Nil(t, (*Decoders)(nil).PipelineOf("users:1"))
+
+	// when
+	_, err = FromConfiguration(map[string]string{"decoders[sessions:]": "unknown"})
+
+	// then
+	assert.EqualError(t, err, "the decoder 'unknown' is unknown")
+}
+
+func TestDecodeProtobuf(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "lagoon-decoding")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "descriptors.pb")
+	content, err := proto.Marshal(userDescriptorSet())
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(path, content, 0644))
+
+	decoders, err := FromConfiguration(map[string]string{
+		"protobufDescriptorSet": path,
+		"decoders[users:]":      "protobuf:demo.User",
+	})
+	assert.Nil(t, err)
+	message := []byte{
+		0x0a, 0x03, 'b', 'o', 'b', // name
+		0x10, 0x2a, // age
+		0x18, 0x01, // role
+		0x22, 0x01, 'a', 0x22, 0x01, 'b', // tags
+		0x2a, 0x02, 0x01, 0x02, // packed scores
+		0x32, 0x02, 0x08, 0x03, // address with its number
+		0x48, 0x07, // unknown field 9
+	}
+
+	// when
+	value := decoders.PipelineOf("users:1").Decode(message)
+
+	// then
+	assert.Equal(t, "", value.Error)
+	assert.Equal(t, map[string]interface{}{
+		"name":    "bob",
+		"age":     int32(42),
+		"role":    "ADMIN",
+		"tags":    []interface{}{"a", "b"},
+		"scores":  []interface{}{int32(1), int32(2)},
+		"address": map[string]interface{}{"number": int32(3)},
+		"9":       []interface{}{uint64(7)},
+	}, value.Decoded)
+
+	// when
+	_, err = decoders.Parse("protobuf:demo.Unknown")
+
+	// then
+	assert.EqualError(t, err, "the protobuf message type demo.Unknown is not in the descriptor set")
+}
+
+func TestFromConfigurationWithInvalidDescriptorSet(t *testing.T) {
+	// when
+	_, err := FromConfiguration(map[string]string{"protobufDescriptorSet": "/not/existing/descriptors.pb"})
+
+	// then
+	assert.Contains(t, err.Error(), "the protobuf descriptor set cannot be read")
+}
+
+func userDescriptorSet() *descriptor.FileDescriptorSet {
+	field := func(name string, number int32, fieldType descriptor.FieldDescriptorProto_Type, label descriptor.FieldDescriptorProto_Label, typeName string) *descriptor.FieldDescriptorProto {
+		f := &descriptor.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   fieldType.Enum(),
+			Label:  label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional := descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptor.FieldDescriptorProto_LABEL_REPEATED
+
+	return &descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{{
+		Name:    proto.String("user.proto"),
+		Package: proto.String("demo"),
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name: proto.String("Role"),
+			Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("GUEST"), Number: proto.Int32(0)},
+				{Name: proto.String("ADMIN"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptor.FieldDescriptorProto{
+				field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING, optional, ""),
+				field("age", 2, descriptor.FieldDescriptorProto_TYPE_INT32, optional, ""),
+				field("role", 3, descriptor.FieldDescriptorProto_TYPE_ENUM, optional, ".demo.Role"),
+				field("tags", 4, descriptor.FieldDescriptorProto_TYPE_STRING, repeated, ""),
+				field("scores", 5, descriptor.FieldDescriptorProto_TYPE_INT32, repeated, ""),
+				field("address", 6, descriptor.FieldDescriptorProto_TYPE_MESSAGE, optional, ".demo.User.Address"),
+			},
+			NestedType: []*descriptor.DescriptorProto{{
+				Name: proto.String("Address"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("number", 1, descriptor.FieldDescriptorProto_TYPE_INT32, optional, ""),
+				},
+			}},
+		}},
+	}}}
+}
+
+func names(pipeline Pipeline) string {
+	var result []string
+	for _, decoder := range pipeline {
+		result = append(result, decoder.Name())
+	}
+	return strings.Join(result, ",")
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func zstdCompressed(t *testing.T, data []byte) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	assert.Nil(t, err)
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil)
+}
+
+func msgpacked(t *testing.T, value interface{}) []byte {
+	var result []byte
+	assert.Nil(t, codec.NewEncoderBytes(&result, &codec.MsgpackHandle{}).Encode(value))
+	return result
+}
@@ -0,0 +1,263 @@
+package decoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"io/ioutil"
+	"math"
+	"strconv"
+)
+
+// Wire types of the protocol buffers.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protobufDescriptors holds the types of the messages and enumerations of a descriptor set,
+// by their fully-qualified name.
+type protobufDescriptors struct {
+	messages map[string]*descriptor.DescriptorProto
+	enums    map[string]*descriptor.EnumDescriptorProto
+}
+
+// loadDescriptorSet reads a FileDescriptorSet, as generated by protoc --include_imports --descriptor_set_out.
+func loadDescriptorSet(path string) (*protobufDescriptors, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("the protobuf descriptor set cannot be read: %s", err.Error()))
+	}
+	descriptorSet := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, descriptorSet); err != nil {
+		return nil, errors.New(fmt.Sprintf("the protobuf descriptor set %s is not valid: %s", path, err.Error()))
+	}
+	return newProtobufDescriptors(descriptorSet), nil
+}
+
+func newProtobufDescriptors(descriptorSet *descriptor.FileDescriptorSet) *protobufDescriptors {
+	descriptors := &protobufDescriptors{
+		messages: make(map[string]*descriptor.DescriptorProto),
+		enums:    make(map[string]*descriptor.EnumDescriptorProto),
+	}
+	for _, file := range descriptorSet.File {
+		scope := ""
+		if file.GetPackage() != "" {
+			scope = "." + file.GetPackage()
+		}
+		descriptors.addMessages(scope, file.MessageType)
+		for _, enum := range file.EnumType {
+			descriptors.enums[scope+"."+enum.GetName()] = enum
+		}
+	}
+	return descriptors
+}
+
+func (d *protobufDescriptors) addMessages(scope string, messages []*descriptor.DescriptorProto) {
+	for _, message := range messages {
+		name := scope + "." + message.GetName()
+		d.messages[name] = message
+		d.addMessages(name, message.NestedType)
+		for _, enum := range message.EnumType {
+			d.enums[name+"."+enum.GetName()] = enum
+		}
+	}
+}
+
+// decoder returns the decoder of the messages of the type, whose name is fully-qualified, with or without leading dot.
+func (d *protobufDescriptors) decoder(messageType string) (Decoder, error) {
+	if len(messageType) > 0 && messageType[0] != '.' {
+		messageType = "." + messageType
+	}
+	message, exists := d.messages[messageType]
+	if !exists {
+		return nil, errors.New(fmt.Sprintf("the protobuf message type %s is not in the descriptor set", messageType[1:]))
+	}
+	return funcDecoder{name: "protobuf:" + messageType[1:], decode: func(data []byte) (interface{}, error) {
+		return d.decodeMessage(data, message)
+	}}, nil
+}
+
+// decodeMessage decodes the wire format of the message into a map, whose keys are the names of the fields,
+// or their numbers when they are unknown.
+func (d *protobufDescriptors) decodeMessage(data []byte, message *descriptor.DescriptorProto) (map[string]interface{}, error) {
+	fields := make(map[int32]*descriptor.FieldDescriptorProto)
+	for _, field := range message.Field {
+		fields[field.GetNumber()] = field
+	}
+
+	result := make(map[string]interface{})
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("the key of a field is not valid")
+		}
+		data = data[n:]
+		number := int32(key >> 3)
+		wireType := int(key & 7)
+
+		var (
+			raw     uint64
+			payload []byte
+		)
+		switch wireType {
+		case wireVarint:
+			raw, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errors.New(fmt.Sprintf("the varint of the field %d is not valid", number))
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return nil, errors.New(fmt.Sprintf("the field %d is truncated", number))
+			}
+			raw = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return nil, errors.New(fmt.Sprintf("the field %d is truncated", number))
+			}
+			raw = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return nil, errors.New(fmt.Sprintf("the field %d is truncated", number))
+			}
+			payload = data[n : n+int(length)]
+			data = data[n+int(length):]
+		default:
+			return nil, errors.New(fmt.Sprintf("the wire type %d of the field %d is not supported", wireType, number))
+		}
+
+		field, known := fields[number]
+		if !known {
+			// The unknown fields are kept with their raw value.
+			if payload != nil {
+				appendField(result, strconv.Itoa(int(number)), payload, true)
+			} else {
+				appendField(result, strconv.Itoa(int(number)), raw, true)
+			}
+			continue
+		}
+		repeated := field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+		if payload != nil && wireType == wireBytes && isPackable(field.GetType()) {
+			// Packed repeated scalars.
+			values, err := d.decodePacked(payload, field)
+			if err != nil {
+				return nil, err
+			}
+			for _, value := range values {
+				appendField(result, field.GetName(), value, repeated)
+			}
+			continue
+		}
+		value, err := d.decodeField(field, raw, payload)
+		if err != nil {
+			return nil, err
+		}
+		appendField(result, field.GetName(), value, repeated)
+	}
+	return result, nil
+}
+
+func (d *protobufDescriptors) decodePacked(payload []byte, field *descriptor.FieldDescriptorProto) ([]interface{}, error) {
+	var values []interface{}
+	for len(payload) > 0 {
+		var raw uint64
+		switch field.GetType() {
+		case descriptor.FieldDescriptorProto_TYPE_DOUBLE, descriptor.FieldDescriptorProto_TYPE_FIXED64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+			if len(payload) < 8 {
+				return nil, errors.New(fmt.Sprintf("the field %s is truncated", field.GetName()))
+			}
+			raw = binary.LittleEndian.Uint64(payload)
+			payload = payload[8:]
+		case descriptor.FieldDescriptorProto_TYPE_FLOAT, descriptor.FieldDescriptorProto_TYPE_FIXED32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+			if len(payload) < 4 {
+				return nil, errors.New(fmt.Sprintf("the field %s is truncated", field.GetName()))
+			}
+			raw = uint64(binary.LittleEndian.Uint32(payload))
+			payload = payload[4:]
+		default:
+			var n int
+			raw, n = binary.Uvarint(payload)
+			if n <= 0 {
+				return nil, errors.New(fmt.Sprintf("the field %s is truncated", field.GetName()))
+			}
+			payload = payload[n:]
+		}
+		value, err := d.decodeField(field, raw, nil)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (d *protobufDescriptors) decodeField(field *descriptor.FieldDescriptorProto, raw uint64, payload []byte) (interface{}, error) {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return math.Float64frombits(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return math.Float32frombits(uint32(raw)), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return int64(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return int32(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT64, descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		return raw, nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		return uint32(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT32:
+		return int32(uint32(raw)>>1) ^ -int32(raw&1), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT64:
+		return int64(raw>>1) ^ -int64(raw&1), nil
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return raw != 0, nil
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if enum, exists := d.enums[field.GetTypeName()]; exists {
+			for _, value := range enum.Value {
+				if value.GetNumber() == int32(raw) {
+					return value.GetName(), nil
+				}
+			}
+		}
+		return int32(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		return string(payload), nil
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return payload, nil
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		message, exists := d.messages[field.GetTypeName()]
+		if !exists {
+			return nil, errors.New(fmt.Sprintf("the protobuf message type %s is not in the descriptor set", field.GetTypeName()))
+		}
+		return d.decodeMessage(payload, message)
+	default:
+		return nil, errors.New(fmt.Sprintf("the type %s of the field %s is not supported", field.GetType().String(), field.GetName()))
+	}
+}
+
+func isPackable(fieldType descriptor.FieldDescriptorProto_Type) bool {
+	switch fieldType {
+	case descriptor.FieldDescriptorProto_TYPE_STRING, descriptor.FieldDescriptorProto_TYPE_BYTES,
+		descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	}
+	return true
+}
+
+// appendField sets the value of the field, or appends it to the previous ones when the field is repeated.
+func appendField(result map[string]interface{}, name string, value interface{}, repeated bool) {
+	if !repeated {
+		result[name] = value
+		return
+	}
+	values, _ := result[name].([]interface{})
+	result[name] = append(values, value)
+}
@@ -0,0 +1,214 @@
+package datasource
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter restricts the entry points or the values returned by a data source.
+// All the predicates which are set have to be fulfilled for a value to be accepted.
+type Filter struct {
+	// Glob is a pattern with * and ? as wildcards, applying to the names of the entry points,
+	// to the members of sets and lists, and to the fields of hashes.
+	Glob string `json:"glob"`
+	// Regex is a regular expression, applying to the same elements as Glob.
+	Regex string `json:"regex"`
+	// ValueRange restricts the members of sets and lists, and the values of hashes.
+	ValueRange *ValueRange `json:"valueRange"`
+	// ScoreRange restricts the members of sorted sets by their score.
+	ScoreRange *ScoreRange `json:"scoreRange"`
+	// Fields restrict the entries of hashes and the messages of streams by their fields.
+	Fields []FieldFilter `json:"fields"`
+}
+
+// ValueRange accepts the values between Min and Max, both inclusive. An empty bound is ignored.
+// Values are compared as numbers when they and the bound are numeric, as strings otherwise.
+type ValueRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+// ScoreRange accepts the scores between Min and Max, both inclusive.
+type ScoreRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// FieldFilter accepts the hash entries or stream messages having a field matching the glob pattern Field,
+// with a value matching the glob pattern Value.
+type FieldFilter struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// NewScoreRange creates a range of scores from optional bounds, missing bounds being infinite.
+func NewScoreRange(min *float64, max *float64) *ScoreRange {
+	scoreRange := ScoreRange{Min: math.Inf(-1), Max: math.Inf(1)}
+	if min != nil {
+		scoreRange.Min = *min
+	}
+	if max != nil {
+		scoreRange.Max = *max
+	}
+	return &scoreRange
+}
+
+// Matcher evaluates a Filter on the values read from a data source, for the predicates
+// that cannot be delegated to the server.
+type Matcher struct {
+	filter Filter
+	glob   *regexp.Regexp
+	regex  *regexp.Regexp
+	fields []fieldMatcher
+}
+
+type fieldMatcher struct {
+	field *regexp.Regexp
+	value *regexp.Regexp
+}
+
+// NewMatcher compiles the patterns of the filter and returns an error if one of them is invalid.
+func NewMatcher(filter Filter) (*Matcher, error) {
+	var err error
+	matcher := Matcher{filter: filter}
+
+	if filter.Glob != "" {
+		matcher.glob = GlobToRegexp(filter.Glob)
+	}
+	if filter.Regex != "" {
+		matcher.regex, err = regexp.Compile(filter.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("the regular expression %s is invalid: %s", filter.Regex, err.Error())
+		}
+	}
+	for _, f := range filter.Fields {
+		matcher.fields = append(matcher.fields, fieldMatcher{
+			field: GlobToRegexp(f.Field),
+			value: GlobToRegexp(f.Value),
+		})
+	}
+	return &matcher, nil
+}
+
+// Filter returns the filter the matcher was created from.
+func (m *Matcher) Filter() Filter {
+	return m.filter
+}
+
+// HasPattern returns true when the filter restricts the values by glob pattern or regular expression.
+func (m *Matcher) HasPattern() bool {
+	return m.glob != nil || m.regex != nil
+}
+
+// MatchRegex checks the value against the regular expression of the filter only.
+// It is used when the glob pattern was already applied by the server.
+func (m *Matcher) MatchRegex(value string) bool {
+	return m.regex == nil || m.regex.MatchString(value)
+}
+
+// MatchPattern checks the value against the glob pattern and the regular expression of the filter.
+func (m *Matcher) MatchPattern(value string) bool {
+	return (m.glob == nil || m.glob.MatchString(value)) && m.MatchRegex(value)
+}
+
+// MatchRange checks the value against the value range of the filter.
+func (m *Matcher) MatchRange(value string) bool {
+	if m.filter.ValueRange == nil {
+		return true
+	}
+	return (m.filter.ValueRange.Min == "" || compareValues(value, m.filter.ValueRange.Min) >= 0) &&
+		(m.filter.ValueRange.Max == "" || compareValues(value, m.filter.ValueRange.Max) <= 0)
+}
+
+// MatchScore checks the score against the score range of the filter.
+func (m *Matcher) MatchScore(score float64) bool {
+	return m.filter.ScoreRange == nil || (score >= m.filter.ScoreRange.Min && score <= m.filter.ScoreRange.Max)
+}
+
+// MatchField checks a single field and its value, like an entry of a hash, against all the field filters.
+func (m *Matcher) MatchField(field string, value string) bool {
+	for _, f := range m.fields {
+		if !f.field.MatchString(field) || !f.value.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchFields checks that each field filter is fulfilled by at least one of the fields, like the ones of a stream message.
+func (m *Matcher) MatchFields(fields map[string]interface{}) bool {
+	for _, f := range m.fields {
+		matched := false
+		for field, value := range fields {
+			if f.field.MatchString(field) && f.value.MatchString(fmt.Sprint(value)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// GlobToRegexp converts a glob pattern with *, ? and [] into an anchored regular expression, whose wildcards match new lines.
+func GlobToRegexp(glob string) *regexp.Regexp {
+	var builder strings.Builder
+	builder.WriteString("(?s)^")
+	inClass := false
+	escaped := false
+	for _, r := range glob {
+		switch {
+		case escaped:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case inClass:
+			if r == ']' {
+				inClass = false
+			} else if r == '[' {
+				builder.WriteRune('\\')
+			}
+			builder.WriteRune(r)
+		case r == '*':
+			builder.WriteString(".*")
+		case r == '?':
+			builder.WriteString(".")
+		case r == '[':
+			inClass = true
+			builder.WriteRune(r)
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+	result, err := regexp.Compile(builder.String())
+	if inClass || err != nil {
+		// Unclosed or invalid classes make the pattern considered as a literal.
+		result = regexp.MustCompile("(?s)^" + regexp.QuoteMeta(glob) + "$")
+	}
+	return result
+}
+
+func compareValues(value string, bound string) int {
+	numericValue, err := strconv.ParseFloat(value, 64)
+	if err == nil {
+		numericBound, err := strconv.ParseFloat(bound, 64)
+		if err == nil {
+			switch {
+			case numericValue < numericBound:
+				return -1
+			case numericValue > numericBound:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(value, bound)
+}
@@ -0,0 +1,106 @@
+package datasource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	testData := []struct {
+		glob     string
+		value    string
+		expected bool
+	}{
+		{glob: "*", value: "any:value", expected: true},
+		{glob: "any:*", value: "any:value", expected: true},
+		{glob: "any:*", value: "other:value", expected: false},
+		{glob: "*val*", value: "any:value", expected: true},
+		{glob: "any:v?lue", value: "any:value", expected: true},
+		{glob: "any:v?lue", value: "any:vaalue", expected: false},
+		{glob: "any:[uv]alue", value: "any:value", expected: true},
+		{glob: "any:[^v]alue", value: "any:value", expected: false},
+		{glob: "any.value", value: "anyxvalue", expected: false},
+		{glob: "any\\*", value: "any*", expected: true},
+		{glob: "any\\*", value: "anything", expected: false},
+		{glob: "any[value", value: "any[value", expected: true},
+		{glob: "{*}", value: "{\n  \"key\": 1\n}", expected: true},
+	}
+
+	for _, d := range testData {
+		assert.Equal(t, d.expected, GlobToRegexp(d.glob).MatchString(d.value), "Glob %s on %s", d.glob, d.value)
+	}
+}
+
+func TestNewMatcherWithInvalidRegex(t *testing.T) {
+	// when
+	_, err := NewMatcher(Filter{Regex: "any[value"})
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestMatcher_MatchPattern(t *testing.T) {
+	// given
+	matcher, err := NewMatcher(Filter{Glob: "group-*", Regex: "bolt"})
+	assert.Nil(t, err)
+
+	// then
+	assert.True(t, matcher.MatchPattern("group-bolton"))
+	assert.False(t, matcher.MatchPattern("group-atom"))
+	assert.False(t, matcher.MatchPattern("bolton"))
+	assert.True(t, matcher.MatchRegex("bolton"))
+}
+
+func TestMatcher_MatchRange(t *testing.T) {
+	// given
+	matcher, _ := NewMatcher(Filter{ValueRange: &ValueRange{Min: "5", Max: "20"}})
+
+	// then
+	assert.True(t, matcher.MatchRange("5"))
+	assert.True(t, matcher.MatchRange("12.5"))
+	assert.True(t, matcher.MatchRange("20"))
+	assert.False(t, matcher.MatchRange("100"))
+	assert.False(t, matcher.MatchRange("4"))
+
+	// given
+	matcher, _ = NewMatcher(Filter{ValueRange: &ValueRange{Min: "b"}})
+
+	// then
+	assert.True(t, matcher.MatchRange("bolton"))
+	assert.False(t, matcher.MatchRange("atom"))
+}
+
+func TestMatcher_MatchScore(t *testing.T) {
+	// given
+	min := 1.5
+	matcher, _ := NewMatcher(Filter{ScoreRange: NewScoreRange(&min, nil)})
+
+	// then
+	assert.True(t, matcher.MatchScore(1.5))
+	assert.True(t, matcher.MatchScore(1000000))
+	assert.False(t, matcher.MatchScore(1.4))
+
+	// given
+	matcher, _ = NewMatcher(Filter{})
+
+	// then
+	assert.True(t, matcher.MatchScore(-1000000))
+}
+
+func TestMatcher_MatchFields(t *testing.T) {
+	// given
+	matcher, _ := NewMatcher(Filter{Fields: []FieldFilter{{Field: "type", Value: "order-*"}, {Field: "user*", Value: "*"}}})
+
+	// then
+	assert.True(t, matcher.MatchFields(map[string]interface{}{"type": "order-created", "userId": 12}))
+	assert.False(t, matcher.MatchFields(map[string]interface{}{"type": "order-created"}))
+	assert.False(t, matcher.MatchFields(map[string]interface{}{"type": "payment", "userId": 12}))
+	assert.False(t, matcher.MatchField("user", "order-created"))
+
+	// given
+	matcher, _ = NewMatcher(Filter{Fields: []FieldFilter{{Field: "user:*", Value: "*"}}})
+
+	// then
+	assert.True(t, matcher.MatchField("user:1", "any"))
+	assert.False(t, matcher.MatchField("order:1", "any"))
+}
@@ -0,0 +1,568 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"lagoon/datasource"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default count of the most recent messages read from each partition by GetContent.
+const defaultContentWindow = int64(100)
+
+// Maximal duration to wait for the messages of the window of a partition.
+const contentReadTimeout = 5 * time.Second
+
+const (
+	pathSeparator = "."
+	clientId      = "lagoon"
+)
+
+type KafkaVendor struct {
+}
+
+// KafkaClient is a data source for the topics of Kafka. The bootstrap lists the brokers separated by commas,
+// like kafka://broker-1:9092,broker-2:9092.
+type KafkaClient struct {
+	datasource.UnsupportedOperations
+	datasource    *datasource.DataSourceDescriptor
+	client        sarama.Client
+	contentWindow int64
+}
+
+type KafkaMessage struct {
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       string            `json:"key"`
+	Value     string            `json:"value"`
+	Timestamp time.Time         `json:"timestamp"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the value of the message.
+func (m KafkaMessage) DecodeValues(decode func(value string) interface{}) interface{} {
+	return struct {
+		KafkaMessage
+		Value interface{} `json:"value"`
+	}{m, decode(m.Value)}
+}
+
+func init() {
+	datasource.DeclareImplementation(&KafkaVendor{})
+}
+
+func (v *KafkaVendor) Accept(source *datasource.DataSourceDescriptor) bool {
+	return "kafka" == strings.TrimSpace(strings.ToLower(source.Vendor))
+}
+
+// CreateDataSource creates the client of the data source, which is opened by the caller.
+func (v *KafkaVendor) CreateDataSource(source *datasource.DataSourceDescriptor) (datasource.DataSource, error) {
+	return &KafkaClient{
+		datasource: source,
+	}, nil
+}
+
+func (c *KafkaClient) Open() error {
+	brokers, err := c.brokers()
+	if err != nil {
+		return err
+	}
+	config, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	c.contentWindow = defaultContentWindow
+	if value, ok := c.datasource.Configuration["contentWindow"]; ok {
+		window, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || window <= 0 {
+			return errors.New(fmt.Sprintf("the content window '%s' is not valid", value))
+		}
+		c.contentWindow = window
+	}
+
+	c.client, err = sarama.NewClient(brokers, config)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connected to the Kafka brokers %v\n", brokers)
+	return nil
+}
+
+// brokers extracts the addresses of the brokers from the bootstrap.
+func (c *KafkaClient) brokers() ([]string, error) {
+	bootstrap := c.datasource.Bootstrap
+	parts := strings.SplitN(bootstrap, "://", 2)
+	if len(parts) == 2 {
+		if parts[0] != "kafka" {
+			return nil, errors.New(fmt.Sprintf("Protocol %s is unkown for Kafka", parts[0]))
+		}
+		bootstrap = parts[1]
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(bootstrap, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, errors.New("no Kafka broker is specified in the bootstrap")
+	}
+	return brokers, nil
+}
+
+// config creates the configuration of the client from the descriptor, with the keys clientId, version
+// (of the protocol of Kafka) and tls.
+func (c *KafkaClient) config() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.ClientID = clientId
+	if value, ok := c.datasource.Configuration["clientId"]; ok {
+		config.ClientID = value
+	}
+	if value, ok := c.datasource.Configuration["version"]; ok {
+		version, err := sarama.ParseKafkaVersion(value)
+		if err != nil {
+			return nil, err
+		}
+		config.Version = version
+	}
+	if value, ok := c.datasource.Configuration["tls"]; ok {
+		enabled, err := strconv.ParseBool(value)
+		if err == nil {
+			config.Net.TLS.Enable = enabled
+		}
+	}
+	if c.datasource.User != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = c.datasource.User
+		config.Net.SASL.Password = c.datasource.Password
+	}
+	return config, nil
+}
+
+func (c *KafkaClient) Close() {
+	if c.client != nil {
+		c.client.Close()
+	}
+}
+
+// ListEntryPoints provides the topics as a tree, whose levels are separated by dots.
+func (c *KafkaClient) ListEntryPoints(ctx context.Context, filter datasource.Filter, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+
+	matcher, err := datasource.NewMatcher(datasource.Filter{Glob: filter.Glob, Regex: filter.Regex})
+	if err != nil {
+		return actionStatus, err
+	}
+	err = c.client.RefreshMetadata()
+	if err != nil {
+		return actionStatus, err
+	}
+	topics, err := c.client.Topics()
+	if err != nil {
+		return actionStatus, err
+	}
+
+	entrypoints := make(map[string]*datasource.EntryPointNode)
+	for _, topic := range topics {
+		if matcher.MatchPattern(topic) {
+			addToTree(topic, minTreeLevel, maxTreeLevel, entrypoints)
+		}
+	}
+
+	var orderedPaths []string
+	for path := range entrypoints {
+		orderedPaths = append(orderedPaths, path)
+	}
+	sort.Strings(orderedPaths)
+	values := make([]interface{}, len(orderedPaths))
+	for i, path := range orderedPaths {
+		values[i] = entrypoints[path]
+	}
+
+	entrypointsChannel <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+// addToTree adds the nodes of the topic between the levels minTreeLevel and maxTreeLevel to the tree,
+// the levels lower than minTreeLevel being omitted from the paths.
+func addToTree(topic string, minTreeLevel uint, maxTreeLevel uint, entrypoints map[string]*datasource.EntryPointNode) {
+	tokens := strings.Split(topic, pathSeparator)
+	tokenCount := uint(len(tokens))
+	path := ""
+	for level := minTreeLevel; level <= maxTreeLevel && level < tokenCount; level++ {
+		if path == "" {
+			path = tokens[level]
+		} else {
+			path += pathSeparator + tokens[level]
+		}
+		node, exists := entrypoints[path]
+		if !exists {
+			node = &datasource.EntryPointNode{Path: datasource.EntryPoint(path)}
+			entrypoints[path] = node
+		}
+		if level < tokenCount-1 {
+			node.Length = node.Length + 1
+		} else {
+			node.HasContent = true
+		}
+	}
+}
+
+// GetEntryPointInfos describes the topic: its partitions and their offsets, the length being the total count
+// of available messages.
+func (c *KafkaClient) GetEntryPointInfos(ctx context.Context, entryPointValue datasource.EntryPoint) (datasource.EntryPointInfos, error) {
+	infos := datasource.EntryPointInfos{Type: datasource.Stream}
+
+	topic := string(entryPointValue)
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return infos, err
+	}
+	for _, partition := range partitions {
+		partitionInfos := datasource.PartitionInfos{Id: partition, Leader: -1}
+		if leader, err := c.client.Leader(topic, partition); err == nil {
+			partitionInfos.Leader = leader.ID()
+		}
+		partitionInfos.Replicas, _ = c.client.Replicas(topic, partition)
+		partitionInfos.InSyncReplicas, _ = c.client.InSyncReplicas(topic, partition)
+		partitionInfos.OldestOffset, partitionInfos.NewestOffset, err = c.offsets(topic, partition)
+		if err != nil {
+			return infos, err
+		}
+		infos.Length += uint64(partitionInfos.NewestOffset - partitionInfos.OldestOffset)
+		infos.Partitions = append(infos.Partitions, partitionInfos)
+	}
+	return infos, nil
+}
+
+// offsets returns the offset of the oldest available message of the partition and the one of the next message.
+func (c *KafkaClient) offsets(topic string, partition int32) (int64, int64, error) {
+	oldest, err := c.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, 0, err
+	}
+	newest, err := c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, err
+	}
+	return oldest, newest, nil
+}
+
+// GetContent reads the most recent messages of each partition of the topic, at most contentWindow of them,
+// and returns the ones accepted by the filter ordered by timestamp.
+func (c *KafkaClient) GetContent(ctx context.Context, entryPointValue datasource.EntryPoint, filter datasource.Filter, content chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return actionStatus, err
+	}
+	topic := string(entryPointValue)
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return actionStatus, err
+	}
+
+	// Each operation has its own consumer, since a partition can only be consumed once by a consumer.
+	consumer, err := sarama.NewConsumerFromClient(c.client)
+	if err != nil {
+		return actionStatus, err
+	}
+	defer consumer.Close()
+
+	var messages []KafkaMessage
+	for _, partition := range partitions {
+		partitionMessages, err := c.readWindow(ctx, consumer, topic, partition, matcher)
+		if err != nil {
+			return actionStatus, err
+		}
+		messages = append(messages, partitionMessages...)
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		if !messages[i].Timestamp.Equal(messages[j].Timestamp) {
+			return messages[i].Timestamp.Before(messages[j].Timestamp)
+		}
+		if messages[i].Partition != messages[j].Partition {
+			return messages[i].Partition < messages[j].Partition
+		}
+		return messages[i].Offset < messages[j].Offset
+	})
+
+	values := make([]interface{}, len(messages))
+	for i, message := range messages {
+		values[i] = message
+	}
+	content <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+// readWindow reads the last messages of the partition, up to the newest offset known when starting.
+func (c *KafkaClient) readWindow(ctx context.Context, consumer sarama.Consumer, topic string, partition int32, matcher *datasource.Matcher) ([]KafkaMessage, error) {
+	oldest, newest, err := c.offsets(topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	start := newest - c.contentWindow
+	if start < oldest {
+		start = oldest
+	}
+	if start >= newest {
+		return nil, nil
+	}
+
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, start)
+	if err != nil {
+		return nil, err
+	}
+	defer partitionConsumer.Close()
+
+	var messages []KafkaMessage
+	timeout := time.After(contentReadTimeout)
+	for {
+		select {
+		case message, ok := <-partitionConsumer.Messages():
+			if !ok {
+				return messages, nil
+			}
+			if m := toKafkaMessage(message); matcher.MatchPattern(m.Value) && matchHeaders(matcher, m.Headers) {
+				messages = append(messages, m)
+			}
+			if message.Offset >= newest-1 {
+				return messages, nil
+			}
+		case <-timeout:
+			// Some offsets might not be readable, like the ones of the markers of transactions.
+			log.Printf("WARNING: the messages of the partition %d of %s were not all read in %s\n", partition, topic, contentReadTimeout)
+			return messages, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func toKafkaMessage(message *sarama.ConsumerMessage) KafkaMessage {
+	result := KafkaMessage{
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       string(message.Key),
+		Value:     string(message.Value),
+		Timestamp: message.Timestamp,
+	}
+	if len(message.Headers) > 0 {
+		result.Headers = make(map[string]string)
+		for _, header := range message.Headers {
+			result.Headers[string(header.Key)] = string(header.Value)
+		}
+	}
+	return result
+}
+
+// matchHeaders applies the field predicates of the filter to the headers of the message.
+func matchHeaders(matcher *datasource.Matcher, headers map[string]string) bool {
+	fields := make(map[string]interface{}, len(headers))
+	for key, value := range headers {
+		fields[key] = value
+	}
+	return matcher.MatchFields(fields)
+}
+
+// Consume tails all the partitions of the topic, from their oldest or newest offset.
+func (c *KafkaClient) Consume(ctx context.Context, entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch, filter datasource.Filter, fromBeginning bool) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return actionStatus, err
+	}
+	topic := string(entryPointValue)
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return actionStatus, err
+	}
+	offset := sarama.OffsetNewest
+	if fromBeginning {
+		offset = sarama.OffsetOldest
+	}
+
+	// Each operation has its own consumer, since a partition can only be consumed once by a consumer.
+	consumer, err := sarama.NewConsumerFromClient(c.client)
+	if err != nil {
+		return actionStatus, err
+	}
+	var partitionConsumers []sarama.PartitionConsumer
+	for _, partition := range partitions {
+		partitionConsumer, err := consumer.ConsumePartition(topic, partition, offset)
+		if err != nil {
+			for _, p := range partitionConsumers {
+				p.Close()
+			}
+			consumer.Close()
+			return actionStatus, err
+		}
+		partitionConsumers = append(partitionConsumers, partitionConsumer)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, partitionConsumer := range partitionConsumers {
+		wg.Add(1)
+		go func(partitionConsumer sarama.PartitionConsumer) {
+			defer wg.Done()
+			defer partitionConsumer.Close()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case message, ok := <-partitionConsumer.Messages():
+					if !ok {
+						return
+					}
+					m := toKafkaMessage(message)
+					if !matcher.MatchPattern(m.Value) || !matchHeaders(matcher, m.Headers) {
+						continue
+					}
+					select {
+					case target <- datasource.DataBatch{Size: 1, Data: []interface{}{m}}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(partitionConsumer)
+	}
+	go func() {
+		wg.Wait()
+		consumer.Close()
+		close(target)
+	}()
+
+	return datasource.Moved, nil
+}
+
+// GetInfos lists the brokers, the controller having the role "controller".
+func (c *KafkaClient) GetInfos(ctx context.Context) (datasource.Cluster, error) {
+	result := []datasource.ClusterNode{}
+
+	err := c.client.RefreshMetadata()
+	if err != nil {
+		return datasource.Cluster{}, err
+	}
+	controllerId := c.controllerId()
+	for _, broker := range c.sortedBrokers() {
+		role := "broker"
+		if broker.ID() == controllerId {
+			role = "controller"
+		}
+		result = append(result, datasource.ClusterNode{
+			Id:     strconv.Itoa(int(broker.ID())),
+			Server: broker.Addr(),
+			Name:   broker.Addr(),
+			Role:   role,
+		})
+	}
+	return datasource.Cluster{Nodes: result}, nil
+}
+
+// GetStatus describes the cluster and each of its brokers, with the count of partitions they lead.
+func (c *KafkaClient) GetStatus(ctx context.Context) (datasource.ClusterState, error) {
+	result := datasource.ClusterState{
+		Timestamp:     time.Now(),
+		NodeStates:    []datasource.NodeState{},
+		StateSections: []datasource.StateSection{},
+	}
+
+	err := c.client.RefreshMetadata()
+	if err != nil {
+		return result, err
+	}
+	topics, err := c.client.Topics()
+	if err != nil {
+		return result, err
+	}
+
+	partitionCount := 0
+	underReplicatedCount := 0
+	offlineCount := 0
+	leaderships := make(map[int32]int)
+	for _, topic := range topics {
+		partitions, err := c.client.Partitions(topic)
+		if err != nil {
+			return result, err
+		}
+		for _, partition := range partitions {
+			partitionCount++
+			if leader, err := c.client.Leader(topic, partition); err == nil {
+				leaderships[leader.ID()]++
+			} else {
+				offlineCount++
+			}
+			replicas, _ := c.client.Replicas(topic, partition)
+			inSyncReplicas, _ := c.client.InSyncReplicas(topic, partition)
+			if len(inSyncReplicas) < len(replicas) {
+				underReplicatedCount++
+			}
+		}
+	}
+
+	brokers := c.sortedBrokers()
+	controllerId := c.controllerId()
+	result.StateSections = append(result.StateSections, datasource.StateSection{
+		Name: "Cluster",
+		Values: map[string]interface{}{
+			"brokers":                   len(brokers),
+			"controller":                controllerId,
+			"topics":                    len(topics),
+			"partitions":                partitionCount,
+			"underReplicatedPartitions": underReplicatedCount,
+			"offlinePartitions":         offlineCount,
+		},
+	})
+	for _, broker := range brokers {
+		connected, _ := broker.Connected()
+		result.NodeStates = append(result.NodeStates, datasource.NodeState{
+			NodeId: strconv.Itoa(int(broker.ID())),
+			StateSections: []datasource.StateSection{{
+				Name: "Broker",
+				Values: map[string]interface{}{
+					"address":          broker.Addr(),
+					"rack":             broker.Rack(),
+					"connected":        connected,
+					"controller":       broker.ID() == controllerId,
+					"leaderPartitions": leaderships[broker.ID()],
+				},
+			}},
+		})
+	}
+	return result, nil
+}
+
+func (c *KafkaClient) sortedBrokers() []*sarama.Broker {
+	brokers := c.client.Brokers()
+	sort.Slice(brokers, func(i, j int) bool {
+		return brokers[i].ID() < brokers[j].ID()
+	})
+	return brokers
+}
+
+// controllerId returns the ID of the controller of the cluster, or -1 when it is unknown.
+func (c *KafkaClient) controllerId() int32 {
+	controller, err := c.client.Controller()
+	if err != nil {
+		return -1
+	}
+	return controller.ID()
+}
@@ -0,0 +1,331 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"lagoon/datasource"
+	"testing"
+	"time"
+)
+
+// startBroker starts an in-process broker hosting the topics orders.eu.created (2 partitions), orders.eu.paid
+// and payments, the partition 0 of orders.eu.created containing 3 messages and the other partitions none.
+func startBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetController(broker.BrokerID()).
+			SetLeader("orders.eu.created", 0, broker.BrokerID()).
+			SetLeader("orders.eu.created", 1, broker.BrokerID()).
+			SetLeader("orders.eu.paid", 0, broker.BrokerID()).
+			SetLeader("payments", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders.eu.created", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders.eu.created", 0, sarama.OffsetNewest, 3).
+			SetOffset("orders.eu.created", 1, sarama.OffsetOldest, 0).
+			SetOffset("orders.eu.created", 1, sarama.OffsetNewest, 0).
+			SetOffset("orders.eu.paid", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders.eu.paid", 0, sarama.OffsetNewest, 0).
+			SetOffset("payments", 0, sarama.OffsetOldest, 0).
+			SetOffset("payments", 0, sarama.OffsetNewest, 0),
+		"FetchRequest": sarama.NewMockFetchResponse(t, 10).
+			SetMessageWithKey("orders.eu.created", 0, 0, sarama.StringEncoder("order-1"), sarama.StringEncoder("first")).
+			SetMessageWithKey("orders.eu.created", 0, 1, sarama.StringEncoder("order-2"), sarama.StringEncoder("second")).
+			SetMessageWithKey("orders.eu.created", 0, 2, sarama.StringEncoder("order-3"), sarama.StringEncoder("third")).
+			SetHighWaterMark("orders.eu.created", 0, 3),
+	})
+	return broker
+}
+
+func openClient(t *testing.T, broker *sarama.MockBroker, configuration map[string]string) *KafkaClient {
+	client := KafkaClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap:     fmt.Sprintf("kafka://%s", broker.Addr()),
+			Configuration: configuration,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	return &client
+}
+
+func valuesOf(batch datasource.DataBatch) []string {
+	var values []string
+	for _, data := range batch.Data {
+		values = append(values, data.(KafkaMessage).Value)
+	}
+	return values
+}
+
+func TestKafkaVendor_Accept(t *testing.T) {
+	vendor := KafkaVendor{}
+
+	assert.True(t, vendor.Accept(&datasource.DataSourceDescriptor{Vendor: " Kafka "}))
+	assert.False(t, vendor.Accept(&datasource.DataSourceDescriptor{Vendor: "redis"}))
+}
+
+func TestKafkaClient_OpenWithUnknownProtocol(t *testing.T) {
+	// given
+	client := KafkaClient{
+		datasource: &datasource.DataSourceDescriptor{Bootstrap: "redis://localhost:9092"},
+	}
+
+	// when
+	err := client.Open()
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestKafkaClient_ListEntryPoints(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+	entrypointsChannel := make(chan datasource.DataBatch, 1)
+
+	// when
+	status, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, entrypointsChannel, 0, 1)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, status)
+	batch := <-entrypointsChannel
+	assert.Equal(t, []interface{}{
+		&datasource.EntryPointNode{Path: "orders", HasContent: false, Length: 2},
+		&datasource.EntryPointNode{Path: "orders.eu", HasContent: false, Length: 2},
+		&datasource.EntryPointNode{Path: "payments", HasContent: true, Length: 0},
+	}, batch.Data)
+}
+
+func TestKafkaClient_ListEntryPointsWithFilterAndMinLevel(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+	entrypointsChannel := make(chan datasource.DataBatch, 1)
+
+	// when
+	status, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "orders.*"}, entrypointsChannel, 2, 3)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, status)
+	batch := <-entrypointsChannel
+	assert.Equal(t, []interface{}{
+		&datasource.EntryPointNode{Path: "created", HasContent: true, Length: 0},
+		&datasource.EntryPointNode{Path: "paid", HasContent: true, Length: 0},
+	}, batch.Data)
+}
+
+func TestKafkaClient_GetEntryPointInfos(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+
+	// when
+	infos, err := client.GetEntryPointInfos(context.Background(), "orders.eu.created")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Stream, infos.Type)
+	assert.Equal(t, uint64(3), infos.Length)
+	assert.Equal(t, []datasource.PartitionInfos{
+		{Id: 0, Leader: broker.BrokerID(), Replicas: []int32{broker.BrokerID()}, InSyncReplicas: []int32{broker.BrokerID()}, OldestOffset: 0, NewestOffset: 3},
+		{Id: 1, Leader: broker.BrokerID(), Replicas: []int32{broker.BrokerID()}, InSyncReplicas: []int32{broker.BrokerID()}, OldestOffset: 0, NewestOffset: 0},
+	}, infos.Partitions)
+}
+
+func TestKafkaClient_GetEntryPointInfosOfUnknownTopic(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+
+	// when
+	_, err := client.GetEntryPointInfos(context.Background(), "unknown")
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestKafkaClient_GetContent(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+	content := make(chan datasource.DataBatch, 1)
+
+	// when
+	status, err := client.GetContent(context.Background(), "orders.eu.created", datasource.Filter{Glob: "*"}, content)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, status)
+	batch := <-content
+	assert.Equal(t, uint64(3), batch.Size)
+	assert.Equal(t, []string{"first", "second", "third"}, valuesOf(batch))
+	message := batch.Data[0].(KafkaMessage)
+	assert.Equal(t, int32(0), message.Partition)
+	assert.Equal(t, int64(0), message.Offset)
+	assert.Equal(t, "order-1", message.Key)
+}
+
+func TestKafkaClient_GetContentInWindowWithFilter(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, map[string]string{"contentWindow": "2"})
+	defer client.Close()
+	content := make(chan datasource.DataBatch, 1)
+
+	// when
+	status, err := client.GetContent(context.Background(), "orders.eu.created", datasource.Filter{Glob: "*d"}, content)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, status)
+	assert.Equal(t, []string{"second", "third"}, valuesOf(<-content))
+
+	// when
+	_, err = client.GetContent(context.Background(), "orders.eu.created", datasource.Filter{Glob: "th*"}, content)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"third"}, valuesOf(<-content))
+}
+
+func TestKafkaClient_Consume(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+	values := make(chan datasource.DataBatch, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	status, err := client.Consume(ctx, "orders.eu.created", values, datasource.Filter{Glob: "*"}, true)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, status)
+	var consumed []string
+	for len(consumed) < 3 {
+		select {
+		case batch := <-values:
+			consumed = append(consumed, valuesOf(batch)...)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "The messages were not consumed")
+			return
+		}
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, consumed)
+
+	// when
+	cancel()
+
+	// then
+	select {
+	case _, open := <-values:
+		assert.False(t, open)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The channel was not closed")
+	}
+}
+
+func TestKafkaClient_ConsumeTwiceAndGetContentMeanwhile(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := client.Consume(ctx, "orders.eu.created", make(chan datasource.DataBatch, 10), datasource.Filter{Glob: "*"}, true)
+	assert.Nil(t, err)
+
+	// when
+	_, err = client.Consume(ctx, "orders.eu.created", make(chan datasource.DataBatch, 10), datasource.Filter{Glob: "*"}, true)
+
+	// then
+	assert.Nil(t, err, "The same partitions should be consumed by several operations")
+
+	// when
+	content := make(chan datasource.DataBatch, 1)
+	_, err = client.GetContent(context.Background(), "orders.eu.created", datasource.Filter{Glob: "*"}, content)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second", "third"}, valuesOf(<-content))
+}
+
+func TestKafkaClient_GetInfos(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+
+	// when
+	infos, err := client.GetInfos(context.Background())
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []datasource.ClusterNode{
+		{Id: "1", Server: broker.Addr(), Name: broker.Addr(), Role: "controller"},
+	}, infos.Nodes)
+}
+
+func TestKafkaClient_GetStatus(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+
+	// when
+	status, err := client.GetStatus(context.Background())
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "Cluster", status.StateSections[0].Name)
+	assert.Equal(t, 1, status.StateSections[0].Values["brokers"])
+	assert.Equal(t, 3, status.StateSections[0].Values["topics"])
+	assert.Equal(t, 4, status.StateSections[0].Values["partitions"])
+	assert.Equal(t, 0, status.StateSections[0].Values["underReplicatedPartitions"])
+	assert.Len(t, status.NodeStates, 1)
+	assert.Equal(t, "1", status.NodeStates[0].NodeId)
+	assert.Equal(t, broker.Addr(), status.NodeStates[0].StateSections[0].Values["address"])
+	assert.Equal(t, 4, status.NodeStates[0].StateSections[0].Values["leaderPartitions"])
+}
+
+func TestKafkaClient_UnsupportedOperation(t *testing.T) {
+	// given
+	broker := startBroker(t)
+	defer broker.Close()
+	client := openClient(t, broker, nil)
+	defer client.Close()
+
+	// when
+	err := client.SetValue(context.Background(), "payments", "value")
+
+	// then
+	assert.Equal(t, datasource.ErrUnsupportedOperation, err)
+
+	// when
+	err = client.DeleteEntrypoint(context.Background(), "payments")
+
+	// then
+	assert.Equal(t, datasource.ErrUnsupportedOperation, err)
+}
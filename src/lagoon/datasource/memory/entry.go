@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"lagoon/datasource"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is the value of an entry point, only the fields of its type being used.
+type entry struct {
+	kind     datasource.EntryPointType
+	value    string
+	members  map[string]bool
+	scores   map[string]float64
+	values   []string
+	fields   map[string]string
+	messages []StreamMessage
+	// ID of the last message added to the stream, even if it was deleted since.
+	lastId   streamId
+	expireAt time.Time
+}
+
+// streamId is the ID of a message of a stream, made of a time in milliseconds and a sequence number.
+type streamId struct {
+	millis   uint64
+	sequence uint64
+}
+
+func newEntry(kind datasource.EntryPointType) *entry {
+	e := entry{kind: kind}
+	switch kind {
+	case datasource.Set:
+		e.members = make(map[string]bool)
+	case datasource.ScoredSet:
+		e.scores = make(map[string]float64)
+	case datasource.Hash:
+		e.fields = make(map[string]string)
+	}
+	return &e
+}
+
+func (e *entry) isExpired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// length returns the count of elements of the entry, or the length of its value.
+func (e *entry) length() uint64 {
+	switch e.kind {
+	case datasource.Value:
+		return uint64(len(e.value))
+	case datasource.Set:
+		return uint64(len(e.members))
+	case datasource.ScoredSet:
+		return uint64(len(e.scores))
+	case datasource.List:
+		return uint64(len(e.values))
+	case datasource.Hash:
+		return uint64(len(e.fields))
+	case datasource.Stream:
+		return uint64(len(e.messages))
+	}
+	return 0
+}
+
+// isEmpty returns true for the collections without element, which do not exist anymore, except the streams.
+func (e *entry) isEmpty() bool {
+	return e.kind != datasource.Value && e.kind != datasource.Stream && e.length() == 0
+}
+
+// size estimates the memory used by the entry, as the total length of its strings.
+func (e *entry) size() uint64 {
+	size := uint64(len(e.value))
+	for member := range e.members {
+		size += uint64(len(member))
+	}
+	for member := range e.scores {
+		size += uint64(len(member)) + 8
+	}
+	for _, value := range e.values {
+		size += uint64(len(value))
+	}
+	for field, value := range e.fields {
+		size += uint64(len(field) + len(value))
+	}
+	for _, message := range e.messages {
+		size += uint64(len(message.Id))
+		for field, value := range message.Fields {
+			size += uint64(len(field) + len(fmt.Sprint(value)))
+		}
+	}
+	return size
+}
+
+// addMessage appends a message to the stream and returns its ID, which is generated when empty or *.
+func (e *entry) addMessage(id string, fields map[string]interface{}) (string, error) {
+	var messageId streamId
+	if id == "" || id == "*" {
+		messageId = streamId{millis: uint64(time.Now().UnixNano() / int64(time.Millisecond))}
+		if messageId.millis <= e.lastId.millis {
+			messageId = streamId{millis: e.lastId.millis, sequence: e.lastId.sequence + 1}
+		}
+	} else {
+		var err error
+		messageId, err = parseStreamId(id)
+		if err != nil {
+			return "", err
+		}
+		if !e.lastId.less(messageId) {
+			return "", errors.New(fmt.Sprintf("the ID %s is equal or smaller than the last ID of the stream %s", id, e.lastId))
+		}
+	}
+	e.lastId = messageId
+	message := StreamMessage{
+		Id:        messageId.String(),
+		Timestamp: time.Unix(0, int64(messageId.millis)*int64(time.Millisecond)),
+		Fields:    make(map[string]interface{}, len(fields)),
+	}
+	// The values are stored as strings, like in Redis.
+	for field, value := range fields {
+		message.Fields[field] = fmt.Sprint(value)
+	}
+	e.messages = append(e.messages, message)
+	return message.Id, nil
+}
+
+// parseStreamId parses an ID like 1526919030474-55, the sequence number being optional.
+func parseStreamId(id string) (streamId, error) {
+	parts := strings.SplitN(id, "-", 2)
+	millis, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return streamId{}, errors.New(fmt.Sprintf("the ID %s of the stream message is not valid", id))
+	}
+	result := streamId{millis: millis}
+	if len(parts) == 2 {
+		result.sequence, err = strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return streamId{}, errors.New(fmt.Sprintf("the ID %s of the stream message is not valid", id))
+		}
+	}
+	return result, nil
+}
+
+func (s streamId) less(other streamId) bool {
+	return s.millis < other.millis || (s.millis == other.millis && s.sequence < other.sequence)
+}
+
+func (s streamId) String() string {
+	return fmt.Sprintf("%d-%d", s.millis, s.sequence)
+}
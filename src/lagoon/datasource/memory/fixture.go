@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"lagoon/datasource"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fixture lists the entry points seeding a data source, read from a YAML or JSON file.
+type Fixture struct {
+	Entries []FixtureEntry `json:"entries" yaml:"entries"`
+}
+
+// FixtureEntry is an entry point of a fixture, only the fields matching its type being set.
+// It is also the format of the backups of the entry points saved in the trash.
+type FixtureEntry struct {
+	Key string `json:"key" yaml:"key"`
+	// Type is one of value, set, scored_set, list, hash and stream.
+	Type          string                    `json:"type" yaml:"type"`
+	Value         string                    `json:"value,omitempty" yaml:"value,omitempty"`
+	Members       []string                  `json:"members,omitempty" yaml:"members,omitempty"`
+	ScoredMembers []datasource.ScoredMember `json:"scoredMembers,omitempty" yaml:"scoredMembers,omitempty"`
+	Values        []string                  `json:"values,omitempty" yaml:"values,omitempty"`
+	Fields        map[string]string         `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Messages      []FixtureMessage          `json:"messages,omitempty" yaml:"messages,omitempty"`
+	// TimeToLive is a duration like 10m, after which the entry point expires.
+	TimeToLive string `json:"timeToLive,omitempty" yaml:"timeToLive,omitempty"`
+}
+
+// FixtureMessage is a message of a stream, its ID being generated when empty.
+type FixtureMessage struct {
+	Id     string            `json:"id,omitempty" yaml:"id,omitempty"`
+	Fields map[string]string `json:"fields" yaml:"fields"`
+}
+
+// loadFixture reads the fixture from the file, as JSON if its extension is .json and as YAML otherwise.
+func loadFixture(path string) (Fixture, error) {
+	var fixture Fixture
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(content, &fixture)
+	} else {
+		err = yaml.Unmarshal(content, &fixture)
+	}
+	if err != nil {
+		return fixture, errors.New(fmt.Sprintf("the fixture %s is not valid: %s", path, err.Error()))
+	}
+	return fixture, nil
+}
+
+// parseType returns the type of entry point from its name, the names of the types of Redis being accepted as well.
+func parseType(name string) (datasource.EntryPointType, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch name {
+	case "STRING":
+		return datasource.Value, nil
+	case "ZSET", "SORTED_SET":
+		return datasource.ScoredSet, nil
+	}
+	for entryPointType, typeName := range datasource.EntryPointTypesAsString {
+		if typeName == name && entryPointType != datasource.Channel {
+			return entryPointType, nil
+		}
+	}
+	return 0, errors.New(fmt.Sprintf("the type %s is unsupported", name))
+}
+
+// toEntry creates the entry described by the fixture.
+func (f FixtureEntry) toEntry() (*entry, error) {
+	entryPointType, err := parseType(f.Type)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("the entry point %s cannot be created: %s", f.Key, err.Error()))
+	}
+	e := newEntry(entryPointType)
+	switch entryPointType {
+	case datasource.Value:
+		e.value = f.Value
+	case datasource.Set:
+		for _, member := range f.Members {
+			e.members[member] = true
+		}
+	case datasource.ScoredSet:
+		for _, member := range f.ScoredMembers {
+			e.scores[member.Member] = member.Score
+		}
+	case datasource.List:
+		e.values = append(e.values, f.Values...)
+	case datasource.Hash:
+		for field, value := range f.Fields {
+			e.fields[field] = value
+		}
+	case datasource.Stream:
+		for _, message := range f.Messages {
+			fields := make(map[string]interface{}, len(message.Fields))
+			for field, value := range message.Fields {
+				fields[field] = value
+			}
+			if _, err := e.addMessage(message.Id, fields); err != nil {
+				return nil, errors.New(fmt.Sprintf("the entry point %s cannot be created: %s", f.Key, err.Error()))
+			}
+		}
+	}
+	if f.TimeToLive != "" {
+		timeToLive, err := time.ParseDuration(f.TimeToLive)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("the time to live of %s is not valid: %s", f.Key, err.Error()))
+		}
+		e.expireAt = time.Now().Add(timeToLive)
+	}
+	return e, nil
+}
+
+// toFixtureEntry describes the entry, without its expiry.
+func toFixtureEntry(key string, e *entry) FixtureEntry {
+	f := FixtureEntry{
+		Key:  key,
+		Type: strings.ToLower(datasource.EntryPointTypesAsString[e.kind]),
+	}
+	switch e.kind {
+	case datasource.Value:
+		f.Value = e.value
+	case datasource.Set:
+		for member := range e.members {
+			f.Members = append(f.Members, member)
+		}
+		sort.Strings(f.Members)
+	case datasource.ScoredSet:
+		for member, score := range e.scores {
+			f.ScoredMembers = append(f.ScoredMembers, datasource.ScoredMember{Member: member, Score: score})
+		}
+		sort.Slice(f.ScoredMembers, func(i, j int) bool {
+			return f.ScoredMembers[i].Member < f.ScoredMembers[j].Member
+		})
+	case datasource.List:
+		f.Values = append(f.Values, e.values...)
+	case datasource.Hash:
+		f.Fields = make(map[string]string, len(e.fields))
+		for field, value := range e.fields {
+			f.Fields[field] = value
+		}
+	case datasource.Stream:
+		for _, message := range e.messages {
+			fields := make(map[string]string, len(message.Fields))
+			for field, value := range message.Fields {
+				fields[field] = fmt.Sprint(value)
+			}
+			f.Messages = append(f.Messages, FixtureMessage{Id: message.Id, Fields: fields})
+		}
+	}
+	return f
+}
@@ -0,0 +1,1917 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lagoon/datasource"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const scanSize = 1000
+
+// Interval between two removals of the expired entry points.
+const expirationInterval = time.Second
+
+// Maximal count of messages kept for a slow subscriber or watcher, the next ones being dropped.
+const subscriptionBufferSize = 100
+
+const (
+	pathSeparator         = rune(':')
+	pathSeparatorAsString = ":"
+	openingBracket        = rune('{')
+	closingBracket        = rune('}')
+)
+
+// Kinds of the events of a watched entry point.
+const (
+	KeyCreated = "create"
+	KeyUpdated = "update"
+	KeyExpired = "expire"
+	KeyDeleted = "delete"
+)
+
+type MemoryVendor struct {
+}
+
+// MemoryClient is a data source keeping its entry points in memory, with the same types and tree
+// of entry points as Redis. It is seeded from the fixture file set as fixture in the configuration.
+type MemoryClient struct {
+	datasource    *datasource.DataSourceDescriptor
+	entries       map[string]*entry
+	trash         *datasource.Trash
+	subscriptions map[*subscription]bool
+	watchers      map[*watcher]bool
+	// streamsChanged is closed and replaced when messages are added to a stream, to wake the consumers up.
+	streamsChanged chan struct{}
+	stop           chan struct{}
+	startTime      time.Time
+	mutex          sync.Mutex
+}
+
+type SortedSetValues struct {
+	Score  float64  `json:"score"`
+	Values []string `json:"values"`
+}
+
+type HashValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type StreamMessage struct {
+	Id        string                 `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the members.
+func (s SortedSetValues) DecodeValues(decode func(value string) interface{}) interface{} {
+	values := make([]interface{}, len(s.Values))
+	for i, value := range s.Values {
+		values[i] = decode(value)
+	}
+	return struct {
+		SortedSetValues
+		Values []interface{} `json:"values"`
+	}{s, values}
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the value of the field.
+func (h HashValue) DecodeValues(decode func(value string) interface{}) interface{} {
+	return struct {
+		HashValue
+		Value interface{} `json:"value"`
+	}{h, decode(h.Value)}
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the values of the fields.
+func (m StreamMessage) DecodeValues(decode func(value string) interface{}) interface{} {
+	fields := make(map[string]interface{}, len(m.Fields))
+	for name, value := range m.Fields {
+		if text, ok := value.(string); ok {
+			fields[name] = decode(text)
+		} else {
+			fields[name] = value
+		}
+	}
+	return struct {
+		StreamMessage
+		Fields map[string]interface{} `json:"fields"`
+	}{m, fields}
+}
+
+type ChannelMessage struct {
+	Channel   string    `json:"channel"`
+	Pattern   string    `json:"pattern,omitempty"`
+	Payload   string    `json:"payload"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type KeyspaceEvent struct {
+	Key       string    `json:"key"`
+	Event     string    `json:"event"`
+	Command   string    `json:"command"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type subscription struct {
+	channel  string
+	pattern  bool
+	matcher  *datasource.Matcher
+	messages chan ChannelMessage
+}
+
+type watcher struct {
+	entryPoint string
+	events     chan KeyspaceEvent
+}
+
+func init() {
+	datasource.DeclareImplementation(&MemoryVendor{})
+}
+
+func (v *MemoryVendor) Accept(source *datasource.DataSourceDescriptor) bool {
+	return "memory" == strings.TrimSpace(strings.ToLower(source.Vendor))
+}
+
+// CreateDataSource creates the client of the data source, which is opened by the caller.
+func (v *MemoryVendor) CreateDataSource(source *datasource.DataSourceDescriptor) (datasource.DataSource, error) {
+	return &MemoryClient{
+		datasource: source,
+	}, nil
+}
+
+// split splits the key into the levels of the tree, ignoring the separators between brackets.
+func split(key string) (uint, []string) {
+	var tokens []string
+	openBrackets := 0
+	start := 0
+	for i, r := range key {
+		if r == pathSeparator && openBrackets == 0 {
+			tokens = append(tokens, key[start:i])
+			start = i + 1
+		} else if r == openingBracket {
+			openBrackets++
+		} else if r == closingBracket && openBrackets > 0 {
+			openBrackets--
+		}
+	}
+	if start < len(key) {
+		tokens = append(tokens, key[start:])
+	}
+	return uint(len(tokens)), tokens
+}
+
+func (c *MemoryClient) Open() error {
+	trash, err := datasource.NewTrashFromConfiguration(c.datasource.Configuration)
+	if err != nil {
+		return err
+	}
+	c.trash = trash
+	c.entries = make(map[string]*entry)
+	c.subscriptions = make(map[*subscription]bool)
+	c.watchers = make(map[*watcher]bool)
+	c.streamsChanged = make(chan struct{})
+	c.startTime = time.Now()
+
+	if path, ok := c.datasource.Configuration["fixture"]; ok && path != "" {
+		fixture, err := loadFixture(path)
+		if err != nil {
+			return err
+		}
+		for _, fixtureEntry := range fixture.Entries {
+			e, err := fixtureEntry.toEntry()
+			if err != nil {
+				return err
+			}
+			c.entries[fixtureEntry.Key] = e
+		}
+		log.Printf("%d entry points were loaded from the fixture %s\n", len(fixture.Entries), path)
+	}
+
+	c.stop = make(chan struct{})
+	go c.removeExpiredEntries(c.stop)
+	return nil
+}
+
+func (c *MemoryClient) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// removeExpiredEntries periodically removes the expired entry points, until the data source is closed.
+func (c *MemoryClient) removeExpiredEntries(stop <-chan struct{}) {
+	ticker := time.NewTicker(expirationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			c.mutex.Lock()
+			for key, e := range c.entries {
+				if e.isExpired(now) {
+					delete(c.entries, key)
+					c.notify(key, "expired", KeyExpired)
+				}
+			}
+			c.mutex.Unlock()
+		}
+	}
+}
+
+// lookup returns the entry of the key, or nil if it does not exist or expired. The mutex has to be acquired by the caller.
+func (c *MemoryClient) lookup(key string) *entry {
+	e, exists := c.entries[key]
+	if !exists {
+		return nil
+	}
+	if e.isExpired(time.Now()) {
+		delete(c.entries, key)
+		c.notify(key, "expired", KeyExpired)
+		return nil
+	}
+	return e
+}
+
+// lookupType returns the entry of the key, or nil if it does not exist, and an error if it is not of the kind.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) lookupType(key string, kind datasource.EntryPointType) (*entry, error) {
+	e := c.lookup(key)
+	if e != nil && e.kind != kind {
+		return nil, errors.New(fmt.Sprintf("Entrypoint %s is not a %s but a %s", key,
+			strings.ToLower(datasource.EntryPointTypesAsString[kind]), strings.ToLower(datasource.EntryPointTypesAsString[e.kind])))
+	}
+	return e, nil
+}
+
+// writable returns the entry of the key, created if it does not exist, and true if it was created.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) writable(key string, kind datasource.EntryPointType) (*entry, bool, error) {
+	e, err := c.lookupType(key, kind)
+	if err != nil || e != nil {
+		return e, false, err
+	}
+	e = newEntry(kind)
+	c.entries[key] = e
+	return e, true, nil
+}
+
+// changed notifies the change of the entry, which is removed if it became empty. The mutex has to be acquired by the caller.
+func (c *MemoryClient) changed(key string, e *entry, command string, created bool) {
+	if e.isEmpty() {
+		delete(c.entries, key)
+		if !created {
+			c.notify(key, command, KeyDeleted)
+		}
+		return
+	}
+	if created {
+		c.notify(key, command, KeyCreated)
+	} else {
+		c.notify(key, command, KeyUpdated)
+	}
+}
+
+// notify sends the event to the watchers of the key. The mutex has to be acquired by the caller.
+func (c *MemoryClient) notify(key string, command string, event string) {
+	keyspaceEvent := KeyspaceEvent{
+		Key:       key,
+		Event:     event,
+		Command:   command,
+		Timestamp: time.Now(),
+	}
+	for w := range c.watchers {
+		if w.entryPoint == "" || key == w.entryPoint || strings.HasPrefix(key, w.entryPoint+pathSeparatorAsString) {
+			select {
+			case w.events <- keyspaceEvent:
+			default:
+				log.Printf("WARN an event of %s was dropped for a slow watcher\n", key)
+			}
+		}
+	}
+}
+
+func (c *MemoryClient) GetInfos(ctx context.Context) (datasource.Cluster, error) {
+	return datasource.Cluster{Nodes: []datasource.ClusterNode{{
+		Id:     c.datasource.Id,
+		Server: c.datasource.Bootstrap,
+		Name:   c.datasource.Name,
+		Role:   "master",
+	}}}, nil
+}
+
+func (c *MemoryClient) GetStatus(ctx context.Context) (datasource.ClusterState, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := 0
+	expires := 0
+	now := time.Now()
+	for _, e := range c.entries {
+		if !e.isExpired(now) {
+			keys++
+			if !e.expireAt.IsZero() {
+				expires++
+			}
+		}
+	}
+	return datasource.ClusterState{
+		Timestamp: now,
+		NodeStates: []datasource.NodeState{{
+			NodeId: "",
+			StateSections: []datasource.StateSection{
+				{Name: "Server", Values: map[string]interface{}{"uptime_in_seconds": int64(now.Sub(c.startTime) / time.Second)}},
+				{Name: "Keyspace", Values: map[string]interface{}{"keys": keys, "expires": expires}},
+				{Name: "Clients", Values: map[string]interface{}{"subscriptions": len(c.subscriptions), "watchers": len(c.watchers)}},
+			},
+		}},
+	}, nil
+}
+
+// sortedKeys returns the keys of the entry points which are not expired, in alphabetical order.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) sortedKeys() []string {
+	now := time.Now()
+	keys := make([]string, 0, len(c.entries))
+	for key, e := range c.entries {
+		if !e.isExpired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *MemoryClient) ListEntryPoints(ctx context.Context, filter datasource.Filter, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
+	matcher, err := datasource.NewMatcher(datasource.Filter{Glob: filter.Glob})
+	if err != nil {
+		return datasource.None, err
+	}
+	regexMatcher, err := datasource.NewMatcher(datasource.Filter{Regex: filter.Regex})
+	if err != nil {
+		return datasource.None, err
+	}
+
+	c.mutex.Lock()
+	keys := c.sortedKeys()
+	c.mutex.Unlock()
+
+	// The keys excluded by the regular expression still give content to the nodes of the same path.
+	excludedKeys := make(map[string]bool)
+	var acceptedKeys []string
+	for _, key := range keys {
+		if !matcher.MatchPattern(key) {
+			continue
+		}
+		if regexMatcher.MatchRegex(key) {
+			acceptedKeys = append(acceptedKeys, key)
+		} else {
+			excludedKeys[key] = true
+		}
+	}
+
+	entrypoints := make(map[string]*datasource.EntryPointNode)
+	for _, key := range acceptedKeys {
+		tokenCount, tokens := split(key)
+		if tokenCount <= minTreeLevel {
+			continue
+		}
+		entryPointPrefix := ""
+		if minTreeLevel > 0 {
+			entryPointPrefix = strings.Join(tokens[:minTreeLevel], pathSeparatorAsString) + pathSeparatorAsString
+		}
+		path := ""
+		for level := minTreeLevel; level <= maxTreeLevel && level < tokenCount; level++ {
+			if path == "" {
+				path = tokens[level]
+			} else {
+				path += pathSeparatorAsString + tokens[level]
+			}
+			node, exists := entrypoints[path]
+			if !exists {
+				node = &datasource.EntryPointNode{Path: datasource.EntryPoint(path), HasContent: excludedKeys[entryPointPrefix+path]}
+				entrypoints[path] = node
+			}
+			if level < tokenCount-1 {
+				node.Length = node.Length + 1
+			} else {
+				node.HasContent = true
+			}
+		}
+	}
+
+	var orderedPaths []string
+	for path := range entrypoints {
+		orderedPaths = append(orderedPaths, path)
+	}
+	sort.Strings(orderedPaths)
+	values := make([]interface{}, len(orderedPaths))
+	for i, path := range orderedPaths {
+		values[i] = entrypoints[path]
+	}
+	entrypointsChannel <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+func (c *MemoryClient) GetEntryPointInfos(ctx context.Context, entryPointValue datasource.EntryPoint) (datasource.EntryPointInfos, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e := c.lookup(string(entryPointValue))
+	if e == nil {
+		return datasource.EntryPointInfos{}, errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	}
+	infos := datasource.EntryPointInfos{
+		Type:       e.kind,
+		Length:     e.length(),
+		TimeToLive: time.Duration(-1),
+	}
+	if !e.expireAt.IsZero() {
+		infos.TimeToLive = time.Until(e.expireAt)
+	}
+	if e.kind == datasource.Stream {
+		streamInfos := streamInfosOf(e)
+		infos.Stream = &streamInfos
+	}
+	return infos, nil
+}
+
+func streamInfosOf(e *entry) datasource.StreamInfos {
+	infos := datasource.StreamInfos{
+		Length:          uint64(len(e.messages)),
+		LastGeneratedId: e.lastId.String(),
+		Groups:          []datasource.ConsumerGroup{},
+	}
+	if len(e.messages) > 0 {
+		infos.FirstEntryId = e.messages[0].Id
+		infos.LastEntryId = e.messages[len(e.messages)-1].Id
+	}
+	return infos
+}
+
+// AnalyzeEntryPoints samples the entry points of the data source, whose memory usage is estimated as the total
+// length of their key and strings.
+func (c *MemoryClient) AnalyzeEntryPoints(ctx context.Context, analysis *datasource.Analysis) error {
+	matcher, err := datasource.NewMatcher(datasource.Filter{Glob: analysis.Options().Filter})
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keys := c.sortedKeys()
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e := c.lookup(key)
+		if e == nil || !matcher.MatchPattern(key) {
+			continue
+		}
+		_, tokens := split(key)
+		analysis.Add(datasource.KeySample{
+			Key:           key,
+			Type:          datasource.EntryPointTypesAsString[e.kind],
+			MemoryUsage:   int64(len(key)) + int64(e.size()),
+			Length:        e.length(),
+			HasTimeToLive: !e.expireAt.IsZero(),
+		}, tokens, pathSeparatorAsString)
+	}
+	analysis.Scanned(len(keys), true)
+	return nil
+}
+
+func (c *MemoryClient) GetContent(ctx context.Context, entryPointValue datasource.EntryPoint, filter datasource.Filter, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return datasource.None, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e := c.lookup(string(entryPointValue))
+	if e == nil {
+		return datasource.Completed, errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	}
+	values := valuesOf(e, matcher)
+	contentChannel <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+// GetContentPage returns a window of the sorted content, whose cursor is the index of its first value.
+func (c *MemoryClient) GetContentPage(ctx context.Context, entryPointValue datasource.EntryPoint, filter datasource.Filter, page datasource.Page) (datasource.ContentPage, error) {
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return datasource.ContentPage{}, err
+	}
+	start := uint64(0)
+	if page.Cursor != "" {
+		start, err = strconv.ParseUint(page.Cursor, 10, 64)
+		if err != nil {
+			return datasource.ContentPage{}, errors.New(fmt.Sprintf("the cursor '%s' is not valid", page.Cursor))
+		}
+	}
+	count := uint64(scanSize)
+	if page.Count > 0 {
+		count = uint64(page.Count)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e := c.lookup(string(entryPointValue))
+	if e == nil {
+		return datasource.ContentPage{}, errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	}
+	var values []interface{}
+	if e.kind == datasource.Stream {
+		// All the messages of the stream are considered, not only the first scanSize ones.
+		values = streamRange(e, datasource.StreamRange{Count: math.MaxInt64}, matcher)
+	} else {
+		values = valuesOf(e, matcher)
+	}
+	result := datasource.ContentPage{Cursor: datasource.FirstPage}
+	if start < uint64(len(values)) {
+		end := start + count
+		if end < uint64(len(values)) {
+			result.Cursor = strconv.FormatUint(end, 10)
+		} else {
+			end = uint64(len(values))
+		}
+		result.Data = values[start:end]
+	}
+	result.Size = uint64(len(result.Data))
+	return result, nil
+}
+
+// valuesOf returns the values of the entry accepted by the matcher, sorted when the type has no natural order.
+func valuesOf(e *entry, matcher *datasource.Matcher) []interface{} {
+	var values []interface{}
+	switch e.kind {
+	case datasource.Value:
+		values = append(values, e.value)
+	case datasource.Set:
+		var members []string
+		for member := range e.members {
+			if matcher.MatchPattern(member) && matcher.MatchRange(member) {
+				members = append(members, member)
+			}
+		}
+		sort.Strings(members)
+		for _, member := range members {
+			values = append(values, member)
+		}
+	case datasource.ScoredSet:
+		scoredValues := make(map[float64]*SortedSetValues)
+		var scores []float64
+		for member, score := range e.scores {
+			if matcher.MatchScore(score) && matcher.MatchPattern(member) && matcher.MatchRange(member) {
+				if _, exists := scoredValues[score]; !exists {
+					scoredValues[score] = &SortedSetValues{Score: score}
+					scores = append(scores, score)
+				}
+				scoredValues[score].Values = append(scoredValues[score].Values, member)
+			}
+		}
+		sort.Float64s(scores)
+		for _, score := range scores {
+			sort.Strings(scoredValues[score].Values)
+			values = append(values, *scoredValues[score])
+		}
+	case datasource.List:
+		for _, value := range e.values {
+			if matcher.MatchPattern(value) && matcher.MatchRange(value) {
+				values = append(values, value)
+			}
+		}
+	case datasource.Hash:
+		var fields []string
+		for field, value := range e.fields {
+			// Patterns apply to the fields, ranges to the values.
+			if matcher.MatchPattern(field) && matcher.MatchRange(value) && matcher.MatchField(field, value) {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			values = append(values, HashValue{Key: field, Value: e.fields[field]})
+		}
+	case datasource.Stream:
+		// The content contains all the messages, unlike the ranges limited by default.
+		values = streamRange(e, datasource.StreamRange{Count: int64(len(e.messages))}, matcher)
+	}
+	return values
+}
+
+func (c *MemoryClient) GetStreamInfos(ctx context.Context, entryPointValue datasource.EntryPoint, pendingEntriesCount int64) (datasource.StreamInfos, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, err := c.lookupType(string(entryPointValue), datasource.Stream)
+	if err != nil {
+		return datasource.StreamInfos{}, err
+	} else if e == nil {
+		return datasource.StreamInfos{}, errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	}
+	return streamInfosOf(e), nil
+}
+
+func (c *MemoryClient) GetStreamRange(ctx context.Context, entryPointValue datasource.EntryPoint, rangeOfMessages datasource.StreamRange, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, err := c.lookupType(string(entryPointValue), datasource.Stream)
+	if err != nil {
+		return datasource.Completed, err
+	} else if e == nil {
+		return datasource.Completed, errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	}
+	values := streamRange(e, rangeOfMessages, nil)
+	contentChannel <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+func (c *MemoryClient) GetSortedSetRange(ctx context.Context, entryPointValue datasource.EntryPoint, sortedSetRange datasource.SortedSetRange, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, err := c.lookupType(string(entryPointValue), datasource.ScoredSet)
+	if err != nil {
+		return datasource.Completed, err
+	} else if e == nil {
+		return datasource.Completed, errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	}
+	values, err := sortedSetRangeOf(e, sortedSetRange)
+	if err != nil {
+		return datasource.Completed, err
+	}
+	contentChannel <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+// sortedSetRangeOf returns the members of the sorted set in the range, as Redis orders them: by score then member,
+// the consecutive members with the same score being grouped.
+func sortedSetRangeOf(e *entry, sortedSetRange datasource.SortedSetRange) ([]interface{}, error) {
+	var members []string
+	for member := range e.scores {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if e.scores[members[i]] != e.scores[members[j]] {
+			return e.scores[members[i]] < e.scores[members[j]]
+		}
+		return members[i] < members[j]
+	})
+	if sortedSetRange.Reverse {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+	count := sortedSetRange.Count
+	if count <= 0 {
+		count = scanSize
+	}
+
+	var selected []string
+	switch sortedSetRange.By {
+	case datasource.ByRank, "":
+		start, stop, err := rankWindow(sortedSetRange, count, int64(len(members)))
+		if err != nil {
+			return nil, err
+		}
+		if start <= stop {
+			selected = members[start : stop+1]
+		}
+	case datasource.ByScore, datasource.ByLex:
+		var accept func(member string) bool
+		if sortedSetRange.By == datasource.ByScore {
+			min, minExclusive, err := parseScoreBound(sortedSetRange.Start, "-inf")
+			if err != nil {
+				return nil, err
+			}
+			max, maxExclusive, err := parseScoreBound(sortedSetRange.Stop, "+inf")
+			if err != nil {
+				return nil, err
+			}
+			accept = func(member string) bool {
+				score := e.scores[member]
+				return (score > min || !minExclusive && score == min) && (score < max || !maxExclusive && score == max)
+			}
+		} else {
+			accept = func(member string) bool {
+				return matchLexBound(member, sortedSetRange.Start, true) && matchLexBound(member, sortedSetRange.Stop, false)
+			}
+		}
+		skipped := int64(0)
+		for _, member := range members {
+			if int64(len(selected)) >= count {
+				break
+			}
+			if accept(member) {
+				if skipped < sortedSetRange.Offset {
+					skipped++
+				} else {
+					selected = append(selected, member)
+				}
+			}
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("the kind of range '%s' is not valid", sortedSetRange.By))
+	}
+
+	var values []interface{}
+	for _, member := range selected {
+		score := e.scores[member]
+		if last := len(values) - 1; last >= 0 && values[last].(SortedSetValues).Score == score {
+			previous := values[last].(SortedSetValues)
+			previous.Values = append(previous.Values, member)
+			values[last] = previous
+		} else {
+			values = append(values, SortedSetValues{Score: score, Values: []string{member}})
+		}
+	}
+	return values, nil
+}
+
+// rankWindow returns the start and stop indexes of a range of ranks, within the length of the sorted set.
+// Negative indexes start from the end, as in Redis.
+func rankWindow(sortedSetRange datasource.SortedSetRange, count int64, length int64) (int64, int64, error) {
+	var (
+		start int64
+		stop  int64
+		err   error
+	)
+	if sortedSetRange.Start != "" {
+		if start, err = strconv.ParseInt(sortedSetRange.Start, 10, 64); err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("the rank '%s' is not valid", sortedSetRange.Start))
+		}
+	}
+	if sortedSetRange.Stop != "" {
+		if stop, err = strconv.ParseInt(sortedSetRange.Stop, 10, 64); err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("the rank '%s' is not valid", sortedSetRange.Stop))
+		}
+	} else if start >= 0 {
+		stop = start + count - 1
+	} else {
+		stop = -1
+	}
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	return start, stop, nil
+}
+
+// parseScoreBound parses a bound of ZRANGEBYSCORE, exclusive when prefixed by a parenthesis.
+func parseScoreBound(bound string, defaultBound string) (float64, bool, error) {
+	if bound == "" {
+		bound = defaultBound
+	}
+	exclusive := strings.HasPrefix(bound, "(")
+	score, err := strconv.ParseFloat(strings.TrimPrefix(bound, "("), 64)
+	if err != nil {
+		return 0, false, errors.New(fmt.Sprintf("the score '%s' is not valid", bound))
+	}
+	return score, exclusive, nil
+}
+
+// matchLexBound checks the member is after the lower bound or before the upper bound of ZRANGEBYLEX:
+// "-" and "+" are the infinite bounds, "[" prefixes an inclusive bound and "(" an exclusive one.
+func matchLexBound(member string, bound string, lower bool) bool {
+	switch {
+	case bound == "" || bound == "-" && lower || bound == "+" && !lower:
+		return true
+	case bound == "-" || bound == "+":
+		return false
+	case strings.HasPrefix(bound, "("):
+		if lower {
+			return member > bound[1:]
+		}
+		return member < bound[1:]
+	default:
+		value := strings.TrimPrefix(bound, "[")
+		if lower {
+			return member >= value
+		}
+		return member <= value
+	}
+}
+
+// streamRange returns the messages of the stream in the range, which are accepted by the matcher if not nil.
+func streamRange(e *entry, rangeOfMessages datasource.StreamRange, matcher *datasource.Matcher) []interface{} {
+	start := streamId{}
+	if rangeOfMessages.Start != "" && rangeOfMessages.Start != "-" {
+		start, _ = parseStreamId(rangeOfMessages.Start)
+	}
+	end := streamId{millis: math.MaxUint64, sequence: math.MaxUint64}
+	if rangeOfMessages.End != "" && rangeOfMessages.End != "+" {
+		end, _ = parseStreamId(rangeOfMessages.End)
+		if !strings.Contains(rangeOfMessages.End, "-") {
+			end.sequence = math.MaxUint64
+		}
+	}
+	count := rangeOfMessages.Count
+	if count <= 0 {
+		count = scanSize
+	}
+
+	var values []interface{}
+	for i := 0; i < len(e.messages) && int64(len(values)) < count; i++ {
+		message := e.messages[i]
+		if rangeOfMessages.Reverse {
+			message = e.messages[len(e.messages)-1-i]
+		}
+		id, _ := parseStreamId(message.Id)
+		if id.less(start) || end.less(id) {
+			continue
+		}
+		if matcher == nil || matcher.MatchFields(message.Fields) {
+			values = append(values, message)
+		}
+	}
+	return values
+}
+
+func (c *MemoryClient) DeleteEntrypoint(ctx context.Context, entryPointValue datasource.EntryPoint) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, err := c.deleteKeys([]string{string(entryPointValue)}, "delete")
+	return err
+}
+
+// deleteKeys saves the existing keys into the trash then deletes them, and returns the count of deleted keys.
+// Nothing is deleted if the keys cannot be saved. The mutex has to be acquired by the caller.
+func (c *MemoryClient) deleteKeys(keys []string, operation string) (int64, error) {
+	if err := c.saveToTrash(keys, operation); err != nil {
+		return 0, err
+	}
+	return c.removeKeys(keys), nil
+}
+
+// removeKeys deletes the keys without saving them, and returns the count of deleted keys.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) removeKeys(keys []string) int64 {
+	count := int64(0)
+	for _, key := range keys {
+		if c.lookup(key) != nil {
+			delete(c.entries, key)
+			c.notify(key, "del", KeyDeleted)
+			count++
+		}
+	}
+	return count
+}
+
+// children returns the keys of all the children of the entry point, excluding the entry point itself.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) children(entryPointValue datasource.EntryPoint) []string {
+	prefix := string(entryPointValue) + pathSeparatorAsString
+	keys := []string{}
+	for _, key := range c.sortedKeys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// sendBatch adds the batch to the target and returns false if the context was cancelled meanwhile.
+func sendBatch(ctx context.Context, batch datasource.DataBatch, target chan<- datasource.DataBatch) bool {
+	select {
+	case target <- batch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *MemoryClient) DeleteEntrypointChildren(ctx context.Context, entryPointValue datasource.EntryPoint, rateLimit uint, errorChannel chan<- error) (datasource.ActionStatus, error) {
+	if c.datasource.ReadOnly {
+		return datasource.None, errors.New("the data source can be only read")
+	}
+
+	c.mutex.Lock()
+	keys := c.children(entryPointValue)
+	// The size of all the backups is reserved in the trash, so that none of them is evicted by the others.
+	var reservation *datasource.TrashReservation
+	if c.trash != nil && len(keys) > 0 {
+		size := 0
+		for _, key := range keys {
+			size += len(c.serialize(key, c.entries[key]))
+		}
+		var err error
+		reservation, err = c.trash.Reserve(size)
+		if err != nil {
+			c.mutex.Unlock()
+			return datasource.None, errors.New(fmt.Sprintf("the children of %s cannot be kept in the trash, the deletion is cancelled: %s", entryPointValue, err.Error()))
+		}
+	}
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(errorChannel)
+		if reservation != nil {
+			defer reservation.Release()
+		}
+
+		chunkSize := scanSize
+		if rateLimit > 0 && int(rateLimit) < chunkSize {
+			chunkSize = int(rateLimit)
+		}
+		total := int64(0)
+		startTime := time.Now()
+		for start := 0; start < len(keys) && ctx.Err() == nil; start += chunkSize {
+			end := start + chunkSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			c.mutex.Lock()
+			count := int64(0)
+			err := c.backupKeys(keys[start:end], "delete children", reservation)
+			if err == nil {
+				count = c.removeKeys(keys[start:end])
+			}
+			c.mutex.Unlock()
+			total += count
+			if err != nil {
+				select {
+				case errorChannel <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			if rateLimit > 0 {
+				// Waits until the rate of deleted keys is under the limit.
+				expectedDuration := time.Duration(end) * time.Second / time.Duration(rateLimit)
+				if elapsed := time.Since(startTime); elapsed < expectedDuration {
+					select {
+					case <-time.After(expectedDuration - elapsed):
+					case <-ctx.Done():
+					}
+				}
+			}
+		}
+		log.Printf("A total of %d entries were deleted\n", total)
+	}()
+	return datasource.Moved, nil
+}
+
+func (c *MemoryClient) PreviewEntrypointChildrenDeletion(ctx context.Context, entryPointValue datasource.EntryPoint, sampleSize int) (datasource.DeletionPreview, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	preview := datasource.DeletionPreview{Sample: []datasource.EntryPoint{}}
+	keys := c.children(entryPointValue)
+	preview.Count = uint64(len(keys))
+	for i, key := range keys {
+		if i < sampleSize {
+			preview.Sample = append(preview.Sample, datasource.EntryPoint(key))
+		}
+		preview.Memory += c.entries[key].size()
+	}
+	return preview, nil
+}
+
+// Trash returns the trash of the data source, nil when it is disabled.
+func (c *MemoryClient) Trash() *datasource.Trash {
+	return c.trash
+}
+
+func (c *MemoryClient) ListTrashEntries(ctx context.Context) ([]datasource.TrashEntry, error) {
+	if c.trash == nil {
+		return []datasource.TrashEntry{}, nil
+	}
+	return c.trash.List(), nil
+}
+
+func (c *MemoryClient) RestoreTrashEntry(ctx context.Context, id string, replace bool) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if c.trash == nil {
+		return errors.New("the trash is disabled for the data source")
+	}
+	trashEntry, exists := c.trash.Get(id)
+	if !exists {
+		return errors.New(fmt.Sprintf("the entry %s was not found in the trash", id))
+	}
+	if trashEntry.ExpireAt != nil && !time.Now().Before(*trashEntry.ExpireAt) {
+		return errors.New(fmt.Sprintf("the entry point %s would be already expired", trashEntry.EntryPoint))
+	}
+	var fixtureEntry FixtureEntry
+	err := json.Unmarshal(trashEntry.Payload, &fixtureEntry)
+	if err != nil {
+		return err
+	}
+	e, err := fixtureEntry.toEntry()
+	if err != nil {
+		return err
+	}
+	if trashEntry.ExpireAt != nil {
+		e.expireAt = *trashEntry.ExpireAt
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(trashEntry.EntryPoint)
+	if c.lookup(key) != nil {
+		if !replace {
+			return errors.New(fmt.Sprintf("the entry point %s already exists", key))
+		}
+		// The current value is itself saved before being replaced.
+		if err := c.saveToTrash([]string{key}, "restore"); err != nil {
+			return err
+		}
+	}
+	c.entries[key] = e
+	c.notify(key, "restore", KeyCreated)
+	c.trash.Remove(id)
+	return nil
+}
+
+// saveToTrash saves the existing keys into the trash before the operation changes them.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) saveToTrash(keys []string, operation string) error {
+	return c.backupKeys(keys, operation, nil)
+}
+
+// backupKeys saves the existing keys into the trash, within the reservation if any.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) backupKeys(keys []string, operation string, reservation *datasource.TrashReservation) error {
+	if c.trash == nil {
+		return nil
+	}
+	savedAt := time.Now()
+	for _, key := range keys {
+		e := c.lookup(key)
+		if e == nil {
+			continue
+		}
+		trashEntry := datasource.TrashEntry{
+			EntryPoint: datasource.EntryPoint(key),
+			Operation:  operation,
+			Payload:    c.serialize(key, e),
+			SavedAt:    savedAt,
+		}
+		if !e.expireAt.IsZero() {
+			expireAt := e.expireAt
+			trashEntry.ExpireAt = &expireAt
+		}
+		save := c.trash.Save
+		if reservation != nil {
+			save = reservation.Save
+		}
+		if _, err := save(trashEntry); err != nil {
+			return errors.New(fmt.Sprintf("the backup into the trash failed, the operation is cancelled: %s", err.Error()))
+		}
+	}
+	return nil
+}
+
+// serialize returns the payload of the entry saved into the trash.
+func (c *MemoryClient) serialize(key string, e *entry) []byte {
+	// The serialization of the fixture cannot fail.
+	payload, _ := json.Marshal(toFixtureEntry(key, e))
+	return payload
+}
+
+// saveElementChangeToTrash saves the entry point into the trash before the operation adds, changes or removes some of
+// its elements, unless the trash is configured to ignore these changes, since the whole entry point is serialized.
+// The mutex has to be acquired by the caller.
+func (c *MemoryClient) saveElementChangeToTrash(key string, operation string) error {
+	if c.trash == nil || !c.trash.KeepsElementChanges() {
+		return nil
+	}
+	return c.saveToTrash([]string{key}, operation)
+}
+
+func (c *MemoryClient) RenameEntrypoint(ctx context.Context, entryPointValue datasource.EntryPoint, newEntryPointValue datasource.EntryPoint) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.moveKey(string(entryPointValue), string(newEntryPointValue))
+}
+
+// moveKey renames the key, without overwriting an existing one. The mutex has to be acquired by the caller.
+func (c *MemoryClient) moveKey(from string, to string) error {
+	e := c.lookup(from)
+	if e == nil {
+		return errors.New(fmt.Sprintf("the entry point %s does not exist", from))
+	}
+	if c.lookup(to) != nil {
+		return errors.New(fmt.Sprintf("the entry point %s already exists", to))
+	}
+	delete(c.entries, from)
+	c.entries[to] = e
+	c.notify(from, "rename_from", KeyDeleted)
+	c.notify(to, "rename_to", KeyCreated)
+	return nil
+}
+
+func (c *MemoryClient) MoveEntrypointTree(ctx context.Context, entryPointValue datasource.EntryPoint, newEntryPointValue datasource.EntryPoint, dryRun bool, progressChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	if c.datasource.ReadOnly && !dryRun {
+		return datasource.None, errors.New("the data source can be only read")
+	}
+	source := string(entryPointValue)
+	target := string(newEntryPointValue)
+	if source == "" || target == "" {
+		return datasource.None, errors.New("the entry points to move from and to are required")
+	}
+	if target == source || strings.HasPrefix(target, source+pathSeparatorAsString) {
+		return datasource.None, errors.New(fmt.Sprintf("the entry point %s cannot be moved into itself", source))
+	}
+
+	c.mutex.Lock()
+	keys := c.children(entryPointValue)
+	// The entry point itself is moved as well when it has a content.
+	if c.lookup(source) != nil {
+		keys = append(keys, source)
+	}
+	sort.Strings(keys)
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(progressChannel)
+
+		if dryRun {
+			c.mutex.Lock()
+			var moves []interface{}
+			for _, key := range keys {
+				to := target + strings.TrimPrefix(key, source)
+				moves = append(moves, datasource.EntryPointMove{
+					From:     datasource.EntryPoint(key),
+					To:       datasource.EntryPoint(to),
+					Conflict: c.lookup(to) != nil,
+				})
+			}
+			c.mutex.Unlock()
+			for _, batch := range splitValues(moves, scanSize) {
+				if !sendBatch(ctx, datasource.DataBatch{Size: uint64(len(batch)), Data: batch}, progressChannel) {
+					return
+				}
+			}
+			return
+		}
+
+		progress := datasource.Progress{Total: uint64(len(keys))}
+		sendProgress := func() {
+			sendBatch(ctx, datasource.NewProgressBatch(progress), progressChannel)
+			progress.Error = ""
+		}
+		sendProgress()
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				break
+			}
+			c.mutex.Lock()
+			err := c.moveKey(key, target+strings.TrimPrefix(key, source))
+			c.mutex.Unlock()
+			progress.Processed++
+			if err != nil {
+				progress.Failed++
+				progress.Error = fmt.Sprintf("%s: %s", key, err.Error())
+				sendProgress()
+			} else if progress.Processed%uint64(scanSize) == 0 || progress.Processed == progress.Total {
+				sendProgress()
+			}
+		}
+	}()
+	return datasource.Moved, nil
+}
+
+// splitValues splits the values into batches of at most size values.
+func splitValues(values []interface{}, size int) [][]interface{} {
+	var batches [][]interface{}
+	for start := 0; start < len(values); start += size {
+		end := start + size
+		if end > len(values) {
+			end = len(values)
+		}
+		batches = append(batches, values[start:end])
+	}
+	return batches
+}
+
+func (c *MemoryClient) SetExpiry(ctx context.Context, entryPointValue datasource.EntryPoint, expiry datasource.Expiry) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if err := expiry.Validate(); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e := c.lookup(string(entryPointValue))
+	if e == nil {
+		return errors.New(fmt.Sprintf("the entry point %s does not exist", entryPointValue))
+	}
+	c.expire(string(entryPointValue), e, expiry)
+	return nil
+}
+
+// expire sets or removes the expiry of the entry. The mutex has to be acquired by the caller.
+func (c *MemoryClient) expire(key string, e *entry, expiry datasource.Expiry) {
+	if expiry.IsPersistent() {
+		e.expireAt = time.Time{}
+		c.notify(key, "persist", KeyUpdated)
+		return
+	}
+	if !expiry.ExpireAt.IsZero() {
+		e.expireAt = expiry.ExpireAt
+	} else {
+		e.expireAt = time.Now().Add(expiry.TimeToLive)
+	}
+	c.notify(key, "expire", KeyUpdated)
+}
+
+func (c *MemoryClient) SetChildrenExpiry(ctx context.Context, entryPointValue datasource.EntryPoint, expiry datasource.Expiry, progressChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	if c.datasource.ReadOnly {
+		return datasource.None, errors.New("the data source can be only read")
+	}
+	if err := expiry.Validate(); err != nil {
+		return datasource.None, err
+	}
+
+	c.mutex.Lock()
+	keys := c.children(entryPointValue)
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(progressChannel)
+
+		progress := datasource.Progress{Total: uint64(len(keys))}
+		sendBatch(ctx, datasource.NewProgressBatch(progress), progressChannel)
+		for start := 0; start < len(keys) && ctx.Err() == nil; start += scanSize {
+			end := start + scanSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			c.mutex.Lock()
+			for _, key := range keys[start:end] {
+				if e := c.lookup(key); e != nil {
+					c.expire(key, e, expiry)
+				} else {
+					progress.Failed++
+				}
+			}
+			c.mutex.Unlock()
+			progress.Processed += uint64(end - start)
+			sendBatch(ctx, datasource.NewProgressBatch(progress), progressChannel)
+		}
+	}()
+	return datasource.Moved, nil
+}
+
+func (c *MemoryClient) SetValue(ctx context.Context, entryPointValue datasource.EntryPoint, value string) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveToTrash([]string{key}, "set value"); err != nil {
+		return err
+	}
+	// Like SET in Redis, the value replaces an entry of any type and its expiry.
+	created := c.lookup(key) == nil
+	e := newEntry(datasource.Value)
+	e.value = value
+	c.entries[key] = e
+	c.changed(key, e, "set", created)
+	return nil
+}
+
+func (c *MemoryClient) AddSetMembers(ctx context.Context, entryPointValue datasource.EntryPoint, members []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "add set members"); err != nil {
+		return 0, err
+	}
+	e, created, err := c.writable(key, datasource.Set)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, member := range members {
+		if !e.members[member] {
+			e.members[member] = true
+			count++
+		}
+	}
+	c.changed(key, e, "sadd", created)
+	return count, nil
+}
+
+func (c *MemoryClient) RemoveSetMembers(ctx context.Context, entryPointValue datasource.EntryPoint, members []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "remove set members"); err != nil {
+		return 0, err
+	}
+	e, err := c.lookupType(key, datasource.Set)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, member := range members {
+		if e.members[member] {
+			delete(e.members, member)
+			count++
+		}
+	}
+	c.changed(key, e, "srem", false)
+	return count, nil
+}
+
+func (c *MemoryClient) AddSortedSetMembers(ctx context.Context, entryPointValue datasource.EntryPoint, members []datasource.ScoredMember) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "add sorted set members"); err != nil {
+		return 0, err
+	}
+	e, created, err := c.writable(key, datasource.ScoredSet)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, member := range members {
+		if _, exists := e.scores[member.Member]; !exists {
+			count++
+		}
+		e.scores[member.Member] = member.Score
+	}
+	c.changed(key, e, "zadd", created)
+	return count, nil
+}
+
+func (c *MemoryClient) SetHashFields(ctx context.Context, entryPointValue datasource.EntryPoint, fields map[string]string) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "set hash fields"); err != nil {
+		return err
+	}
+	e, created, err := c.writable(key, datasource.Hash)
+	if err != nil {
+		return err
+	}
+	for field, value := range fields {
+		e.fields[field] = value
+	}
+	c.changed(key, e, "hset", created)
+	return nil
+}
+
+func (c *MemoryClient) DeleteHashFields(ctx context.Context, entryPointValue datasource.EntryPoint, fields []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "delete hash fields"); err != nil {
+		return 0, err
+	}
+	e, err := c.lookupType(key, datasource.Hash)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, field := range fields {
+		if _, exists := e.fields[field]; exists {
+			delete(e.fields, field)
+			count++
+		}
+	}
+	c.changed(key, e, "hdel", false)
+	return count, nil
+}
+
+func (c *MemoryClient) PushListValues(ctx context.Context, entryPointValue datasource.EntryPoint, values []string, head bool) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "push list values"); err != nil {
+		return 0, err
+	}
+	e, created, err := c.writable(key, datasource.List)
+	if err != nil {
+		return 0, err
+	}
+	command := "rpush"
+	if head {
+		// Like LPUSH, the values are inserted one after the other at the head, the last one becoming the first.
+		command = "lpush"
+		for _, value := range values {
+			e.values = append([]string{value}, e.values...)
+		}
+	} else {
+		e.values = append(e.values, values...)
+	}
+	c.changed(key, e, command, created)
+	return int64(len(e.values)), nil
+}
+
+func (c *MemoryClient) SetListValue(ctx context.Context, entryPointValue datasource.EntryPoint, index int64, value string) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "set list value"); err != nil {
+		return err
+	}
+	e, err := c.lookupType(key, datasource.List)
+	if err != nil {
+		return err
+	} else if e == nil {
+		return errors.New(fmt.Sprintf("the entry point %s does not exist", key))
+	}
+	// Negative indexes start from the tail.
+	if index < 0 {
+		index += int64(len(e.values))
+	}
+	if index < 0 || index >= int64(len(e.values)) {
+		return errors.New("the index is out of range")
+	}
+	e.values[index] = value
+	c.changed(key, e, "lset", false)
+	return nil
+}
+
+func (c *MemoryClient) RemoveListValue(ctx context.Context, entryPointValue datasource.EntryPoint, value string, count int64) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "remove list value"); err != nil {
+		return 0, err
+	}
+	e, err := c.lookupType(key, datasource.List)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	removed := int64(0)
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	kept := make([]string, len(e.values))
+	keptCount := 0
+	for i := range e.values {
+		// A negative count removes the occurrences from the tail.
+		position := i
+		if count < 0 {
+			position = len(e.values) - 1 - i
+		}
+		if e.values[position] == value && (limit == 0 || removed < limit) {
+			removed++
+			continue
+		}
+		if count < 0 {
+			kept[len(kept)-1-keptCount] = e.values[position]
+		} else {
+			kept[keptCount] = e.values[position]
+		}
+		keptCount++
+	}
+	if count < 0 {
+		e.values = kept[len(kept)-keptCount:]
+	} else {
+		e.values = kept[:keptCount]
+	}
+	if removed > 0 {
+		c.changed(key, e, "lrem", false)
+	}
+	return removed, nil
+}
+
+func (c *MemoryClient) AddStreamMessage(ctx context.Context, entryPointValue datasource.EntryPoint, id string, fields map[string]interface{}) (string, error) {
+	if c.datasource.ReadOnly {
+		return "", errors.New("the data source can be only read")
+	}
+	if len(fields) == 0 {
+		return "", errors.New("a message of a stream requires at least one field")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "add stream message"); err != nil {
+		return "", err
+	}
+	e, created, err := c.writable(key, datasource.Stream)
+	if err != nil {
+		return "", err
+	}
+	messageId, err := e.addMessage(id, fields)
+	if err != nil {
+		if created {
+			delete(c.entries, key)
+		}
+		return "", err
+	}
+	c.changed(key, e, "xadd", created)
+	// Wakes the consumers up.
+	close(c.streamsChanged)
+	c.streamsChanged = make(chan struct{})
+	return messageId, nil
+}
+
+func (c *MemoryClient) DeleteStreamMessages(ctx context.Context, entryPointValue datasource.EntryPoint, ids []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(entryPointValue)
+	if err := c.saveElementChangeToTrash(key, "delete stream messages"); err != nil {
+		return 0, err
+	}
+	e, err := c.lookupType(key, datasource.Stream)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	deletedIds := make(map[string]bool)
+	for _, id := range ids {
+		deletedIds[id] = true
+	}
+	var kept []StreamMessage
+	for _, message := range e.messages {
+		if !deletedIds[message.Id] {
+			kept = append(kept, message)
+		}
+	}
+	count := int64(len(e.messages) - len(kept))
+	e.messages = kept
+	if count > 0 {
+		c.changed(key, e, "xdel", false)
+	}
+	return count, nil
+}
+
+func (c *MemoryClient) Consume(ctx context.Context, entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch, filter datasource.Filter, fromBeginning bool) (datasource.ActionStatus, error) {
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return datasource.None, err
+	}
+	key := string(entryPointValue)
+
+	c.mutex.Lock()
+	// A stream that does not exist yet can be consumed, it will be created by the first producer.
+	e, err := c.lookupType(key, datasource.Stream)
+	lastId := streamId{}
+	if err == nil && e != nil && !fromBeginning {
+		lastId = e.lastId
+	}
+	c.mutex.Unlock()
+	if err != nil {
+		return datasource.None, err
+	}
+
+	go func() {
+		defer close(target)
+		for {
+			c.mutex.Lock()
+			var values []interface{}
+			if e := c.lookup(key); e != nil && e.kind == datasource.Stream {
+				for _, message := range e.messages {
+					id, _ := parseStreamId(message.Id)
+					if lastId.less(id) {
+						lastId = id
+						if matcher.MatchFields(message.Fields) {
+							values = append(values, message)
+						}
+					}
+				}
+			}
+			streamsChanged := c.streamsChanged
+			c.mutex.Unlock()
+
+			for _, batch := range splitValues(values, scanSize) {
+				select {
+				case target <- datasource.DataBatch{Size: uint64(len(batch)), Data: batch}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-streamsChanged:
+			}
+		}
+	}()
+	return datasource.Moved, nil
+}
+
+// Publish sends the payload to the subscribers of the channel and returns their count.
+func (c *MemoryClient) Publish(channel string, payload string) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	count := int64(0)
+	for s := range c.subscriptions {
+		message := ChannelMessage{Channel: channel, Payload: payload, Timestamp: time.Now()}
+		if s.pattern {
+			if !s.matcher.MatchPattern(channel) {
+				continue
+			}
+			message.Pattern = s.channel
+		} else if s.channel != channel {
+			continue
+		}
+		count++
+		select {
+		case s.messages <- message:
+		default:
+			log.Printf("WARN a message of %s was dropped for a slow subscriber\n", channel)
+		}
+	}
+	return count
+}
+
+func (c *MemoryClient) ListChannels(ctx context.Context, filter datasource.Filter, channelsChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return datasource.None, err
+	}
+
+	c.mutex.Lock()
+	// Like in Redis, only the channels with subscribers exist, the subscriptions to patterns being excluded.
+	subscribers := make(map[string]uint64)
+	for s := range c.subscriptions {
+		if !s.pattern && matcher.MatchPattern(s.channel) {
+			subscribers[s.channel]++
+		}
+	}
+	c.mutex.Unlock()
+
+	var orderedChannels []string
+	for channel := range subscribers {
+		orderedChannels = append(orderedChannels, channel)
+	}
+	sort.Strings(orderedChannels)
+	var channels []interface{}
+	for _, channel := range orderedChannels {
+		channels = append(channels, datasource.ChannelNode{
+			Path:        datasource.EntryPoint(channel),
+			Type:        datasource.EntryPointTypesAsString[datasource.Channel],
+			Subscribers: subscribers[channel],
+		})
+	}
+	channelsChannel <- datasource.DataBatch{
+		Size: uint64(len(channels)),
+		Data: channels,
+	}
+	return datasource.Completed, nil
+}
+
+func (c *MemoryClient) Subscribe(ctx context.Context, channel datasource.EntryPoint, pattern bool, target chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	s := &subscription{
+		channel:  string(channel),
+		pattern:  pattern,
+		messages: make(chan ChannelMessage, subscriptionBufferSize),
+	}
+	if pattern {
+		matcher, err := datasource.NewMatcher(datasource.Filter{Glob: string(channel)})
+		if err != nil {
+			return datasource.None, err
+		}
+		s.matcher = matcher
+	}
+	c.mutex.Lock()
+	c.subscriptions[s] = true
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(target)
+		defer func() {
+			c.mutex.Lock()
+			delete(c.subscriptions, s)
+			c.mutex.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message := <-s.messages:
+				select {
+				case target <- datasource.DataBatch{Size: 1, Data: []interface{}{message}}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return datasource.Moved, nil
+}
+
+func (c *MemoryClient) Watch(ctx context.Context, entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	w := &watcher{
+		entryPoint: string(entryPointValue),
+		events:     make(chan KeyspaceEvent, subscriptionBufferSize),
+	}
+	c.mutex.Lock()
+	c.watchers[w] = true
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(target)
+		defer func() {
+			c.mutex.Lock()
+			delete(c.watchers, w)
+			c.mutex.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-w.events:
+				select {
+				case target <- datasource.DataBatch{Size: 1, Data: []interface{}{event}}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return datasource.Moved, nil
+}
+
+// ExecuteCommand executes a few commands of Redis on the entry points: PING, DBSIZE, KEYS, EXISTS, TYPE, GET, SET,
+// DEL, TTL, PTTL and PUBLISH.
+func (c *MemoryClient) ExecuteCommand(ctx context.Context, args []interface{}, nodeID string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, errors.New("the command is missing")
+	}
+	var arguments []string
+	for _, arg := range args {
+		arguments = append(arguments, fmt.Sprint(arg))
+	}
+	command := strings.ToLower(arguments[0])
+	arguments = arguments[1:]
+	if (command == "set" || command == "del") && c.datasource.ReadOnly {
+		return nil, errors.New(fmt.Sprintf("the data source %s can only be read", c.datasource.Id))
+	}
+	requireArguments := func(count int) error {
+		if len(arguments) < count {
+			return errors.New(fmt.Sprintf("wrong number of arguments for '%s' command", command))
+		}
+		return nil
+	}
+
+	switch command {
+	case "ping":
+		return "PONG", nil
+	case "publish":
+		if err := requireArguments(2); err != nil {
+			return nil, err
+		}
+		return c.Publish(arguments[0], arguments[1]), nil
+	case "set":
+		if err := requireArguments(2); err != nil {
+			return nil, err
+		}
+		return "OK", c.SetValue(ctx, datasource.EntryPoint(arguments[0]), arguments[1])
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch command {
+	case "dbsize":
+		return int64(len(c.sortedKeys())), nil
+	case "keys":
+		if err := requireArguments(1); err != nil {
+			return nil, err
+		}
+		pattern := datasource.GlobToRegexp(arguments[0])
+		keys := []interface{}{}
+		for _, key := range c.sortedKeys() {
+			if pattern.MatchString(key) {
+				keys = append(keys, key)
+			}
+		}
+		return keys, nil
+	case "exists":
+		count := int64(0)
+		for _, key := range arguments {
+			if c.lookup(key) != nil {
+				count++
+			}
+		}
+		return count, nil
+	case "type":
+		if err := requireArguments(1); err != nil {
+			return nil, err
+		}
+		if e := c.lookup(arguments[0]); e != nil {
+			return strings.ToLower(datasource.EntryPointTypesAsString[e.kind]), nil
+		}
+		return "none", nil
+	case "get":
+		if err := requireArguments(1); err != nil {
+			return nil, err
+		}
+		e, err := c.lookupType(arguments[0], datasource.Value)
+		if err != nil || e == nil {
+			return nil, err
+		}
+		return e.value, nil
+	case "del":
+		return c.deleteKeys(arguments, "delete")
+	case "ttl", "pttl":
+		if err := requireArguments(1); err != nil {
+			return nil, err
+		}
+		e := c.lookup(arguments[0])
+		if e == nil {
+			return int64(-2), nil
+		} else if e.expireAt.IsZero() {
+			return int64(-1), nil
+		}
+		unit := time.Second
+		if command == "pttl" {
+			unit = time.Millisecond
+		}
+		return int64(time.Until(e.expireAt) / unit), nil
+	}
+	return nil, errors.New(fmt.Sprintf("the command %s is not supported by the memory data source", strconv.Quote(command)))
+}
@@ -0,0 +1,662 @@
+package memory
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"lagoon/datasource"
+	"lagoon/datasource/conformance"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const yamlFixture = `
+entries:
+  - key: users:1:name
+    type: value
+    value: alice
+  - key: users:1:roles
+    type: set
+    members: [admin, reader]
+  - key: users:2:name
+    type: string
+    value: bob
+    timeToLive: 1h
+  - key: scores
+    type: zset
+    scoredMembers:
+      - member: alice
+        score: 10
+      - member: bob
+        score: 5
+      - member: carol
+        score: 10
+  - key: queue
+    type: list
+    values: [first, second, third]
+  - key: config
+    type: hash
+    fields:
+      color: blue
+      size: large
+  - key: events
+    type: stream
+    messages:
+      - id: 1-1
+        fields:
+          kind: login
+      - id: 2-1
+        fields:
+          kind: logout
+`
+
+const jsonFixture = `{"entries": [{"key": "greeting", "type": "value", "value": "hello"}]}`
+
+// openClient opens a data source seeded from a fixture with the content and the extension.
+func openClient(t *testing.T, content string, extension string, configuration map[string]string) *MemoryClient {
+	directory, err := ioutil.TempDir("", "lagoon-memory")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(directory) })
+	path := filepath.Join(directory, "fixture"+extension)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+	if configuration == nil {
+		configuration = map[string]string{}
+	}
+	configuration["fixture"] = path
+	client := MemoryClient{
+		datasource: &datasource.DataSourceDescriptor{Id: "memory-1", Bootstrap: "memory://test", Configuration: configuration},
+	}
+	err = client.Open()
+	assert.Nil(t, err)
+	t.Cleanup(client.Close)
+	return &client
+}
+
+func contentOf(t *testing.T, client *MemoryClient, entryPoint string, filter datasource.Filter) []interface{} {
+	channel := make(chan datasource.DataBatch, 1)
+	status, err := client.GetContent(context.Background(), datasource.EntryPoint(entryPoint), filter, channel)
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, status)
+	return (<-channel).Data
+}
+
+func nextBatch(t *testing.T, channel <-chan datasource.DataBatch) datasource.DataBatch {
+	select {
+	case batch := <-channel:
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatal("no data was received")
+	}
+	return datasource.DataBatch{}
+}
+
+func TestMemoryVendor_Accept(t *testing.T) {
+	vendor := MemoryVendor{}
+
+	assert.True(t, vendor.Accept(&datasource.DataSourceDescriptor{Vendor: " Memory "}))
+	assert.False(t, vendor.Accept(&datasource.DataSourceDescriptor{Vendor: "redis"}))
+}
+
+func TestMemoryVendor_CreateDataSourceOpensOnce(t *testing.T) {
+	// given
+	descriptor := datasource.DataSourceDescriptor{Vendor: "memory", Bootstrap: "memory://test"}
+
+	// when
+	ds, err := (&MemoryVendor{}).CreateDataSource(&descriptor)
+
+	// then
+	assert.Nil(t, err)
+	assert.Nil(t, ds.(*MemoryClient).stop, "The data source should be opened by the caller only")
+
+	// when
+	ds, err = datasource.CreateDataSource(&descriptor)
+
+	// then
+	assert.Nil(t, err)
+	assert.NotNil(t, ds.(*MemoryClient).stop)
+	ds.Close()
+}
+
+func TestMemoryClient_OpenWithInvalidFixture(t *testing.T) {
+	// given
+	client := MemoryClient{
+		datasource: &datasource.DataSourceDescriptor{Configuration: map[string]string{"fixture": "/does/not/exist.yml"}},
+	}
+
+	// when
+	err := client.Open()
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestMemoryClient_OpenWithUnknownType(t *testing.T) {
+	// given
+	directory, _ := ioutil.TempDir("", "lagoon-memory")
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "fixture.yml")
+	ioutil.WriteFile(path, []byte("entries:\n  - key: any\n    type: bitmap\n"), 0644)
+	client := MemoryClient{
+		datasource: &datasource.DataSourceDescriptor{Configuration: map[string]string{"fixture": path}},
+	}
+
+	// when
+	err := client.Open()
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestMemoryClient_OpenWithJsonFixture(t *testing.T) {
+	// given
+	client := openClient(t, jsonFixture, ".json", nil)
+
+	// when
+	values := contentOf(t, client, "greeting", datasource.Filter{})
+
+	// then
+	assert.Equal(t, []interface{}{"hello"}, values)
+}
+
+func TestMemoryClient_ListEntryPoints(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	channel := make(chan datasource.DataBatch, 1)
+
+	// when
+	status, err := client.ListEntryPoints(context.Background(), datasource.Filter{}, channel, 0, 1)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, status)
+	batch := <-channel
+	assert.Equal(t, []interface{}{
+		&datasource.EntryPointNode{Path: "config", HasContent: true},
+		&datasource.EntryPointNode{Path: "events", HasContent: true},
+		&datasource.EntryPointNode{Path: "queue", HasContent: true},
+		&datasource.EntryPointNode{Path: "scores", HasContent: true},
+		&datasource.EntryPointNode{Path: "users", Length: 3},
+		&datasource.EntryPointNode{Path: "users:1", Length: 2},
+		&datasource.EntryPointNode{Path: "users:2", Length: 1},
+	}, batch.Data)
+}
+
+func TestMemoryClient_ListEntryPointsWithFilterAndMinLevel(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	channel := make(chan datasource.DataBatch, 1)
+
+	// when
+	_, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "users:*:name"}, channel, 1, 2)
+
+	// then
+	assert.Nil(t, err)
+	batch := <-channel
+	assert.Equal(t, []interface{}{
+		&datasource.EntryPointNode{Path: "1", Length: 1},
+		&datasource.EntryPointNode{Path: "1:name", HasContent: true},
+		&datasource.EntryPointNode{Path: "2", Length: 1},
+		&datasource.EntryPointNode{Path: "2:name", HasContent: true},
+	}, batch.Data)
+}
+
+func TestMemoryClient_GetEntryPointInfos(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	// when
+	persistent, err := client.GetEntryPointInfos(context.Background(), "users:1:roles")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Set, persistent.Type)
+	assert.Equal(t, uint64(2), persistent.Length)
+	assert.Equal(t, time.Duration(-1), persistent.TimeToLive)
+
+	// when
+	expiring, err := client.GetEntryPointInfos(context.Background(), "users:2:name")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Value, expiring.Type)
+	assert.True(t, expiring.TimeToLive > 59*time.Minute && expiring.TimeToLive <= time.Hour)
+
+	// when
+	stream, err := client.GetEntryPointInfos(context.Background(), "events")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Stream, stream.Type)
+	assert.Equal(t, "1-1", stream.Stream.FirstEntryId)
+	assert.Equal(t, "2-1", stream.Stream.LastEntryId)
+
+	// when
+	_, err = client.GetEntryPointInfos(context.Background(), "unknown")
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestMemoryClient_GetContentOfEachType(t *testing.T) {
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	assert.Equal(t, []interface{}{"alice"}, contentOf(t, client, "users:1:name", datasource.Filter{}))
+	assert.Equal(t, []interface{}{"admin", "reader"}, contentOf(t, client, "users:1:roles", datasource.Filter{}))
+	assert.Equal(t, []interface{}{
+		SortedSetValues{Score: 5, Values: []string{"bob"}},
+		SortedSetValues{Score: 10, Values: []string{"alice", "carol"}},
+	}, contentOf(t, client, "scores", datasource.Filter{}))
+	assert.Equal(t, []interface{}{"first", "second", "third"}, contentOf(t, client, "queue", datasource.Filter{}))
+	assert.Equal(t, []interface{}{
+		HashValue{Key: "color", Value: "blue"},
+		HashValue{Key: "size", Value: "large"},
+	}, contentOf(t, client, "config", datasource.Filter{}))
+	messages := contentOf(t, client, "events", datasource.Filter{})
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "1-1", messages[0].(StreamMessage).Id)
+	assert.Equal(t, map[string]interface{}{"kind": "logout"}, messages[1].(StreamMessage).Fields)
+}
+
+func TestMemoryClient_GetContentWithFilter(t *testing.T) {
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	assert.Equal(t, []interface{}{"second", "third"}, contentOf(t, client, "queue", datasource.Filter{Glob: "*d"}))
+	assert.Equal(t, []interface{}{
+		SortedSetValues{Score: 10, Values: []string{"alice", "carol"}},
+	}, contentOf(t, client, "scores", datasource.Filter{ScoreRange: &datasource.ScoreRange{Min: 6, Max: 20}}))
+	assert.Equal(t, []interface{}{
+		HashValue{Key: "color", Value: "blue"},
+	}, contentOf(t, client, "config", datasource.Filter{Glob: "c*"}))
+}
+
+func TestMemoryClient_GetContentOfStreamLargerThanScanSize(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	for i := 0; i < scanSize; i++ {
+		_, err := client.AddStreamMessage(context.Background(), "events", "", map[string]interface{}{"index": i})
+		assert.Nil(t, err)
+	}
+
+	// when
+	messages := contentOf(t, client, "events", datasource.Filter{})
+
+	// then
+	assert.Len(t, messages, scanSize+2)
+	assert.Equal(t, map[string]interface{}{"index": strconv.Itoa(scanSize - 1)}, messages[scanSize+1].(StreamMessage).Fields)
+}
+
+func TestMemoryClient_Expiry(t *testing.T) {
+	// given
+	client := openClient(t, jsonFixture, ".json", nil)
+
+	// when
+	err := client.SetExpiry(context.Background(), "greeting", datasource.Expiry{TimeToLive: 10 * time.Millisecond})
+	time.Sleep(20 * time.Millisecond)
+
+	// then
+	assert.Nil(t, err)
+	_, err = client.GetEntryPointInfos(context.Background(), "greeting")
+	assert.NotNil(t, err)
+}
+
+func TestMemoryClient_ExpiredEntriesAreRemoved(t *testing.T) {
+	// given
+	client := openClient(t, jsonFixture, ".json", nil)
+	client.SetExpiry(context.Background(), "greeting", datasource.Expiry{ExpireAt: time.Now().Add(10 * time.Millisecond)})
+
+	// when
+	time.Sleep(expirationInterval + 100*time.Millisecond)
+
+	// then
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	assert.Empty(t, client.entries)
+}
+
+func TestMemoryClient_Writes(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	// when
+	added, err := client.AddSetMembers(context.Background(), "users:1:roles", []string{"reader", "writer"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), added)
+	assert.Equal(t, []interface{}{"admin", "reader", "writer"}, contentOf(t, client, "users:1:roles", datasource.Filter{}))
+
+	// when
+	length, err := client.PushListValues(context.Background(), "queue", []string{"a", "b"}, true)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), length)
+	assert.Equal(t, []interface{}{"b", "a", "first", "second", "third"}, contentOf(t, client, "queue", datasource.Filter{}))
+
+	// when
+	deleted, err := client.DeleteHashFields(context.Background(), "config", []string{"color", "size"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), deleted)
+	_, err = client.GetEntryPointInfos(context.Background(), "config")
+	assert.NotNil(t, err)
+
+	// when
+	_, err = client.AddSetMembers(context.Background(), "queue", []string{"any"})
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestMemoryClient_RemoveListValueFromTail(t *testing.T) {
+	// given
+	client := openClient(t, jsonFixture, ".json", nil)
+	client.PushListValues(context.Background(), "list", []string{"a", "b", "a", "c", "a"}, false)
+
+	// when
+	removed, err := client.RemoveListValue(context.Background(), "list", "a", -2)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), removed)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, contentOf(t, client, "list", datasource.Filter{}))
+}
+
+func TestMemoryClient_WritesWhenReadOnly(t *testing.T) {
+	// given
+	client := openClient(t, jsonFixture, ".json", nil)
+	client.datasource.ReadOnly = true
+
+	// when
+	err := client.SetValue(context.Background(), "greeting", "bye")
+
+	// then
+	assert.NotNil(t, err)
+	assert.Equal(t, []interface{}{"hello"}, contentOf(t, client, "greeting", datasource.Filter{}))
+}
+
+func TestMemoryClient_DeleteAndRestoreFromTrash(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	// when
+	err := client.DeleteEntrypoint(context.Background(), "config")
+
+	// then
+	assert.Nil(t, err)
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Len(t, entries, 1)
+	assert.Equal(t, datasource.EntryPoint("config"), entries[0].EntryPoint)
+
+	// when
+	err = client.RestoreTrashEntry(context.Background(), entries[0].Id, false)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{
+		HashValue{Key: "color", Value: "blue"},
+		HashValue{Key: "size", Value: "large"},
+	}, contentOf(t, client, "config", datasource.Filter{}))
+}
+
+func TestMemoryClient_DeleteEntrypointLargerThanTheTrash(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", map[string]string{"trashMaxSize": "10"})
+
+	// when
+	err := client.DeleteEntrypoint(context.Background(), "config")
+
+	// then
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximal size of the trash")
+	assert.Len(t, contentOf(t, client, "config", datasource.Filter{}), 2)
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestMemoryClient_ElementChangesAreSavedOnlyWhenEnabled(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", map[string]string{"trashElementChanges": "true"})
+	disabledClient := openClient(t, yamlFixture, ".yml", nil)
+
+	// when
+	for _, c := range []*MemoryClient{client, disabledClient} {
+		_, err := c.DeleteHashFields(context.Background(), "config", []string{"size"})
+		assert.Nil(t, err)
+		_, err = c.PushListValues(context.Background(), "queue", []string{"any"}, false)
+		assert.Nil(t, err)
+	}
+
+	// then
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Len(t, entries, 2)
+	assert.ElementsMatch(t, []string{"delete hash fields", "push list values"}, []string{entries[0].Operation, entries[1].Operation})
+	entries, _ = disabledClient.ListTrashEntries(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestMemoryClient_DeleteEntrypointChildrenLargerThanTheTrash(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", map[string]string{"trashMaxSize": "100"})
+	errorChannel := make(chan error)
+
+	// when
+	_, err := client.DeleteEntrypointChildren(context.Background(), "users", 0, errorChannel)
+
+	// then
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot be kept in the trash, the deletion is cancelled")
+	preview, _ := client.PreviewEntrypointChildrenDeletion(context.Background(), "users", 2)
+	assert.Equal(t, uint64(3), preview.Count)
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestMemoryClient_DeleteEntrypointChildren(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	preview, err := client.PreviewEntrypointChildrenDeletion(context.Background(), "users", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), preview.Count)
+	assert.Equal(t, []datasource.EntryPoint{"users:1:name", "users:1:roles"}, preview.Sample)
+	errorChannel := make(chan error)
+
+	// when
+	status, err := client.DeleteEntrypointChildren(context.Background(), "users", 0, errorChannel)
+	for range errorChannel {
+	}
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, status)
+	preview, _ = client.PreviewEntrypointChildrenDeletion(context.Background(), "users", 2)
+	assert.Equal(t, uint64(0), preview.Count)
+}
+
+func TestMemoryClient_DeleteEntrypointChildrenWhenCancelled(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	errorChannel := make(chan error)
+
+	// when
+	start := time.Now()
+	_, err := client.DeleteEntrypointChildren(ctx, "users", 1, errorChannel)
+	cancel()
+	for range errorChannel {
+	}
+
+	// then
+	assert.Nil(t, err)
+	// The deletion stops without waiting for the rate limit of 1 entry per second.
+	assert.True(t, time.Since(start) < time.Second)
+	preview, _ := client.PreviewEntrypointChildrenDeletion(context.Background(), "users", 2)
+	assert.True(t, preview.Count >= 2)
+}
+
+func TestMemoryClient_MoveEntrypointTree(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	client.SetValue(context.Background(), "people:2:name", "robert")
+	dryRunChannel := make(chan datasource.DataBatch, 10)
+
+	// when
+	_, err := client.MoveEntrypointTree(context.Background(), "users", "people", true, dryRunChannel)
+
+	// then
+	assert.Nil(t, err)
+	batch := nextBatch(t, dryRunChannel)
+	assert.Equal(t, []interface{}{
+		datasource.EntryPointMove{From: "users:1:name", To: "people:1:name"},
+		datasource.EntryPointMove{From: "users:1:roles", To: "people:1:roles"},
+		datasource.EntryPointMove{From: "users:2:name", To: "people:2:name", Conflict: true},
+	}, batch.Data)
+
+	// when
+	progressChannel := make(chan datasource.DataBatch, 10)
+	_, err = client.MoveEntrypointTree(context.Background(), "users", "people", false, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	var progress datasource.Progress
+	for batch := range progressChannel {
+		progress = batch.Progress.(datasource.Progress)
+	}
+	assert.Equal(t, uint64(3), progress.Processed)
+	assert.Equal(t, uint64(1), progress.Failed)
+	assert.Equal(t, []interface{}{"alice"}, contentOf(t, client, "people:1:name", datasource.Filter{}))
+	assert.Equal(t, []interface{}{"robert"}, contentOf(t, client, "people:2:name", datasource.Filter{}))
+}
+
+func TestMemoryClient_Watch(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	channel := make(chan datasource.DataBatch, 10)
+	_, err := client.Watch(ctx, "users", channel)
+	assert.Nil(t, err)
+
+	// when
+	client.SetValue(context.Background(), "users:3:name", "carol")
+	client.SetValue(context.Background(), "other", "ignored")
+	client.DeleteEntrypoint(context.Background(), "users:1:name")
+
+	// then
+	created := nextBatch(t, channel).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "users:3:name", created.Key)
+	assert.Equal(t, KeyCreated, created.Event)
+	assert.Equal(t, "set", created.Command)
+	deleted := nextBatch(t, channel).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "users:1:name", deleted.Key)
+	assert.Equal(t, KeyDeleted, deleted.Event)
+
+	// when
+	cancel()
+
+	// then
+	for range channel {
+	}
+}
+
+func TestMemoryClient_Consume(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channel := make(chan datasource.DataBatch, 10)
+
+	// when
+	status, err := client.Consume(ctx, "events", channel, datasource.Filter{}, true)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, status)
+	assert.Len(t, nextBatch(t, channel).Data, 2)
+
+	// when
+	id, err := client.AddStreamMessage(context.Background(), "events", "", map[string]interface{}{"kind": "login"})
+
+	// then
+	assert.Nil(t, err)
+	batch := nextBatch(t, channel)
+	assert.Equal(t, id, batch.Data[0].(StreamMessage).Id)
+}
+
+func TestMemoryClient_SubscribeAndListChannels(t *testing.T) {
+	// given
+	client := openClient(t, jsonFixture, ".json", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channel := make(chan datasource.DataBatch, 10)
+	patternChannel := make(chan datasource.DataBatch, 10)
+	client.Subscribe(ctx, "news", false, channel)
+	client.Subscribe(ctx, "ne*", true, patternChannel)
+
+	// when
+	channels := make(chan datasource.DataBatch, 1)
+	_, err := client.ListChannels(context.Background(), datasource.Filter{}, channels)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{
+		datasource.ChannelNode{Path: "news", Type: "CHANNEL", Subscribers: 1},
+	}, (<-channels).Data)
+
+	// when
+	count := client.Publish("news", "hello")
+
+	// then
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, "hello", nextBatch(t, channel).Data[0].(ChannelMessage).Payload)
+	assert.Equal(t, "ne*", nextBatch(t, patternChannel).Data[0].(ChannelMessage).Pattern)
+}
+
+func TestMemoryClient_ExecuteCommand(t *testing.T) {
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	result, err := client.ExecuteCommand(context.Background(), []interface{}{"GET", "users:1:name"}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", result)
+
+	result, err = client.ExecuteCommand(context.Background(), []interface{}{"KEYS", "users:*"}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"users:1:name", "users:1:roles", "users:2:name"}, result)
+
+	result, err = client.ExecuteCommand(context.Background(), []interface{}{"TTL", "users:1:name"}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), result)
+
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"FLUSHALL"}, "")
+	assert.NotNil(t, err)
+}
+
+func TestMemoryClient_GetStatus(t *testing.T) {
+	// given
+	client := openClient(t, yamlFixture, ".yml", nil)
+
+	// when
+	status, err := client.GetStatus(context.Background())
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, status.NodeStates, 1)
+	assert.Equal(t, map[string]interface{}{"keys": 7, "expires": 1}, status.NodeStates[0].StateSections[1].Values)
+}
+
+func TestMemoryClient_Conformance(t *testing.T) {
+	conformance.Run(t, conformance.Suite{
+		Open: func(t *testing.T, readOnly bool) datasource.DataSource {
+			client := MemoryClient{
+				datasource: &datasource.DataSourceDescriptor{Id: "memory-1", Bootstrap: "memory://test", ReadOnly: readOnly},
+			}
+			assert.Nil(t, client.Open())
+			t.Cleanup(client.Close)
+			return &client
+		},
+	})
+}
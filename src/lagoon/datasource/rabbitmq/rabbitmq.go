@@ -0,0 +1,720 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"io/ioutil"
+	"lagoon/datasource"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default count of the messages peeked from a queue by GetContent.
+const defaultContentWindow = int64(100)
+
+// Default timeout of the requests to the management API, in seconds.
+const defaultReadTimeout = 30
+
+const defaultManagementPort = "15672"
+
+// Kinds of the entry points, as second level of their paths: <vhost>:exchanges:<name> or <vhost>:queues:<name>.
+const (
+	pathSeparator = ":"
+	exchangesKind = "exchanges"
+	queuesKind    = "queues"
+)
+
+type RabbitMQVendor struct {
+}
+
+// RabbitMQClient is a data source for the virtual hosts, exchanges and queues of RabbitMQ.
+// The bootstrap is the URL of AMQP, like amqp://localhost:5672, the management API being reached by default
+// on the port 15672 of the same host.
+type RabbitMQClient struct {
+	datasource.UnsupportedOperations
+	datasource    *datasource.DataSourceDescriptor
+	amqpUrl       string
+	managementUrl string
+	user          string
+	password      string
+	httpClient    *http.Client
+	contentWindow int64
+	// peekWhenReadOnly allows to peek the messages of the queues of a read-only data source, which marks them as redelivered.
+	peekWhenReadOnly bool
+	// Connections of AMQP by virtual host.
+	connections map[string]*amqp.Connection
+	mutex       sync.Mutex
+	// openChannel opens a channel of AMQP on the virtual host.
+	openChannel func(vhost string) (amqpChannel, error)
+}
+
+// amqpChannel gathers the operations of the channels of AMQP used by the client.
+type amqpChannel interface {
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Nack(tag uint64, multiple bool, requeue bool) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error)
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Close() error
+}
+
+type AmqpMessage struct {
+	Exchange    string                 `json:"exchange"`
+	RoutingKey  string                 `json:"routingKey"`
+	MessageId   string                 `json:"messageId,omitempty"`
+	ContentType string                 `json:"contentType,omitempty"`
+	Headers     map[string]interface{} `json:"headers,omitempty"`
+	Body        string                 `json:"body"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Redelivered bool                   `json:"redelivered"`
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the body of the message.
+func (m AmqpMessage) DecodeValues(decode func(value string) interface{}) interface{} {
+	return struct {
+		AmqpMessage
+		Body interface{} `json:"body"`
+	}{m, decode(m.Body)}
+}
+
+type exchange struct {
+	Name  string `json:"name"`
+	Vhost string `json:"vhost"`
+	Type  string `json:"type"`
+}
+
+type queue struct {
+	Name                   string `json:"name"`
+	Vhost                  string `json:"vhost"`
+	Messages               uint64 `json:"messages"`
+	MessagesReady          uint64 `json:"messages_ready"`
+	MessagesUnacknowledged uint64 `json:"messages_unacknowledged"`
+	Consumers              uint64 `json:"consumers"`
+}
+
+type binding struct {
+	Source     string                 `json:"source"`
+	RoutingKey string                 `json:"routing_key"`
+	Arguments  map[string]interface{} `json:"arguments"`
+}
+
+type node struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Running     bool     `json:"running"`
+	MemUsed     uint64   `json:"mem_used"`
+	MemLimit    uint64   `json:"mem_limit"`
+	DiskFree    uint64   `json:"disk_free"`
+	FdUsed      uint64   `json:"fd_used"`
+	SocketsUsed uint64   `json:"sockets_used"`
+	Uptime      uint64   `json:"uptime"`
+	Partitions  []string `json:"partitions"`
+}
+
+type overview struct {
+	ClusterName     string                 `json:"cluster_name"`
+	RabbitmqVersion string                 `json:"rabbitmq_version"`
+	ErlangVersion   string                 `json:"erlang_version"`
+	ObjectTotals    map[string]interface{} `json:"object_totals"`
+	QueueTotals     map[string]interface{} `json:"queue_totals"`
+}
+
+func init() {
+	datasource.DeclareImplementation(&RabbitMQVendor{})
+}
+
+func (v *RabbitMQVendor) Accept(source *datasource.DataSourceDescriptor) bool {
+	return "rabbitmq" == strings.TrimSpace(strings.ToLower(source.Vendor))
+}
+
+// CreateDataSource creates the client of the data source, which is opened by the caller.
+func (v *RabbitMQVendor) CreateDataSource(source *datasource.DataSourceDescriptor) (datasource.DataSource, error) {
+	return &RabbitMQClient{
+		datasource: source,
+	}, nil
+}
+
+// Open reads the configuration and checks the access to the management API, the connections of AMQP
+// being only opened when required. The configuration accepts the keys managementUrl, managementScheme (http, or https
+// by default with amqps), readTimeout (in seconds), contentWindow and peekWhenReadOnly.
+func (c *RabbitMQClient) Open() error {
+	bootstrap, err := url.Parse(c.datasource.Bootstrap)
+	if err != nil {
+		return err
+	}
+	if bootstrap.Scheme != "amqp" && bootstrap.Scheme != "amqps" {
+		return errors.New(fmt.Sprintf("Protocol %s is unkown for RabbitMQ", bootstrap.Scheme))
+	}
+
+	c.user = "guest"
+	c.password = "guest"
+	if c.datasource.User != "" {
+		c.user = c.datasource.User
+		c.password = c.datasource.Password
+	} else if bootstrap.User != nil {
+		c.user = bootstrap.User.Username()
+		c.password, _ = bootstrap.User.Password()
+	}
+	bootstrap.User = nil
+	bootstrap.Path = "/"
+	c.amqpUrl = bootstrap.String()
+
+	managementScheme := "http"
+	if bootstrap.Scheme == "amqps" {
+		managementScheme = "https"
+	}
+	if value, ok := c.datasource.Configuration["managementScheme"]; ok {
+		if value != "http" && value != "https" {
+			return errors.New(fmt.Sprintf("the scheme of the management API '%s' is not valid", value))
+		}
+		managementScheme = value
+	}
+	c.managementUrl = fmt.Sprintf("%s://%s:%s", managementScheme, bootstrap.Hostname(), defaultManagementPort)
+	if value, ok := c.datasource.Configuration["managementUrl"]; ok {
+		c.managementUrl = value
+	}
+	c.managementUrl = strings.TrimSuffix(c.managementUrl, "/")
+
+	readTimeout := defaultReadTimeout
+	if value, ok := c.datasource.Configuration["readTimeout"]; ok {
+		timeout, err := strconv.Atoi(value)
+		if err == nil {
+			readTimeout = timeout
+		}
+	}
+	c.httpClient = &http.Client{Timeout: time.Duration(readTimeout) * time.Second}
+
+	c.contentWindow = defaultContentWindow
+	if value, ok := c.datasource.Configuration["contentWindow"]; ok {
+		window, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || window <= 0 {
+			return errors.New(fmt.Sprintf("the content window '%s' is not valid", value))
+		}
+		c.contentWindow = window
+	}
+
+	if value, ok := c.datasource.Configuration["peekWhenReadOnly"]; ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("the peek of the read-only queues '%s' is not valid", value))
+		}
+		c.peekWhenReadOnly = enabled
+	}
+
+	c.connections = make(map[string]*amqp.Connection)
+	if c.openChannel == nil {
+		c.openChannel = c.openAmqpChannel
+	}
+
+	var result overview
+	err = c.management(context.Background(), http.MethodGet, apiPath("overview"), &result)
+	if err == nil {
+		log.Printf("Connected to RabbitMQ %s on %s\n", result.RabbitmqVersion, c.managementUrl)
+	}
+	return err
+}
+
+func (c *RabbitMQClient) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for vhost, connection := range c.connections {
+		connection.Close()
+		delete(c.connections, vhost)
+	}
+}
+
+// openAmqpChannel opens a channel on the connection to the virtual host, which is created if required.
+func (c *RabbitMQClient) openAmqpChannel(vhost string) (amqpChannel, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	connection, exists := c.connections[vhost]
+	if !exists || connection.IsClosed() {
+		var err error
+		connection, err = amqp.DialConfig(c.amqpUrl, amqp.Config{
+			Vhost: vhost,
+			SASL:  []amqp.Authentication{&amqp.PlainAuth{Username: c.user, Password: c.password}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.connections[vhost] = connection
+	}
+	return connection.Channel()
+}
+
+// apiPath builds the path of a resource of the management API, escaping its segments.
+func apiPath(segments ...string) string {
+	path := "/api"
+	for _, segment := range segments {
+		path += "/" + url.PathEscape(segment)
+	}
+	return path
+}
+
+// management sends a request to the management API and decodes the JSON response into the result, if not nil.
+func (c *RabbitMQClient) management(ctx context.Context, method string, path string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, method, c.managementUrl+path, nil)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(c.user, c.password)
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(response.Body)
+		return errors.New(fmt.Sprintf("the management API of RabbitMQ replied %d to %s %s: %s", response.StatusCode, method, path, strings.TrimSpace(string(body))))
+	}
+	if result != nil {
+		return json.NewDecoder(response.Body).Decode(result)
+	}
+	return nil
+}
+
+// parseEntryPoint extracts the virtual host, the kind and the name of an exchange or a queue from its path.
+func parseEntryPoint(entryPointValue datasource.EntryPoint) (string, string, string, error) {
+	tokens := strings.SplitN(string(entryPointValue), pathSeparator, 3)
+	if len(tokens) != 3 || (tokens[1] != exchangesKind && tokens[1] != queuesKind) || tokens[2] == "" {
+		return "", "", "", errors.New(fmt.Sprintf("the entry point %s is not an exchange nor a queue", entryPointValue))
+	}
+	return tokens[0], tokens[1], tokens[2], nil
+}
+
+// ListEntryPoints provides the virtual hosts, with their exchanges and queues as children.
+// The glob pattern and the regular expression of the filter apply to the names of the exchanges and queues.
+func (c *RabbitMQClient) ListEntryPoints(ctx context.Context, filter datasource.Filter, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+
+	matcher, err := datasource.NewMatcher(datasource.Filter{Glob: filter.Glob, Regex: filter.Regex})
+	if err != nil {
+		return actionStatus, err
+	}
+	var exchanges []exchange
+	err = c.management(ctx, http.MethodGet, apiPath(exchangesKind), &exchanges)
+	if err != nil {
+		return actionStatus, err
+	}
+	var queues []queue
+	err = c.management(ctx, http.MethodGet, apiPath(queuesKind), &queues)
+	if err != nil {
+		return actionStatus, err
+	}
+
+	entrypoints := make(map[string]*datasource.EntryPointNode)
+	for _, e := range exchanges {
+		// The default exchange has no name and cannot be addressed.
+		if e.Name != "" && matcher.MatchPattern(e.Name) {
+			addToTree([]string{e.Vhost, exchangesKind, e.Name}, minTreeLevel, maxTreeLevel, entrypoints)
+		}
+	}
+	for _, q := range queues {
+		if matcher.MatchPattern(q.Name) {
+			addToTree([]string{q.Vhost, queuesKind, q.Name}, minTreeLevel, maxTreeLevel, entrypoints)
+		}
+	}
+
+	var orderedPaths []string
+	for path := range entrypoints {
+		orderedPaths = append(orderedPaths, path)
+	}
+	sort.Strings(orderedPaths)
+	values := make([]interface{}, len(orderedPaths))
+	for i, path := range orderedPaths {
+		values[i] = entrypoints[path]
+	}
+
+	entrypointsChannel <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+// addToTree adds the nodes of the path between the levels minTreeLevel and maxTreeLevel to the tree,
+// the levels lower than minTreeLevel being omitted from the paths.
+func addToTree(tokens []string, minTreeLevel uint, maxTreeLevel uint, entrypoints map[string]*datasource.EntryPointNode) {
+	tokenCount := uint(len(tokens))
+	path := ""
+	for level := minTreeLevel; level <= maxTreeLevel && level < tokenCount; level++ {
+		if level == minTreeLevel {
+			path = tokens[level]
+		} else {
+			path += pathSeparator + tokens[level]
+		}
+		node, exists := entrypoints[path]
+		if !exists {
+			node = &datasource.EntryPointNode{Path: datasource.EntryPoint(path)}
+			entrypoints[path] = node
+		}
+		if level < tokenCount-1 {
+			node.Length = node.Length + 1
+		} else {
+			node.HasContent = true
+		}
+	}
+}
+
+// GetEntryPointInfos returns the depth and the consumers of a queue, or the type and bindings of an exchange.
+func (c *RabbitMQClient) GetEntryPointInfos(ctx context.Context, entryPointValue datasource.EntryPoint) (datasource.EntryPointInfos, error) {
+	infos := datasource.EntryPointInfos{}
+
+	vhost, kind, name, err := parseEntryPoint(entryPointValue)
+	if err != nil {
+		return infos, err
+	}
+	if kind == queuesKind {
+		var q queue
+		err = c.management(ctx, http.MethodGet, apiPath(queuesKind, vhost, name), &q)
+		if err != nil {
+			return infos, err
+		}
+		infos.Type = datasource.Stream
+		infos.Length = q.Messages
+		infos.Queue = &datasource.QueueInfos{
+			Messages:       q.Messages,
+			Ready:          q.MessagesReady,
+			Unacknowledged: q.MessagesUnacknowledged,
+			Consumers:      q.Consumers,
+		}
+	} else {
+		var e exchange
+		err = c.management(ctx, http.MethodGet, apiPath(exchangesKind, vhost, name), &e)
+		if err != nil {
+			return infos, err
+		}
+		var bindings []binding
+		err = c.management(ctx, http.MethodGet, apiPath(exchangesKind, vhost, name, "bindings", "source"), &bindings)
+		if err != nil {
+			return infos, err
+		}
+		infos.Type = datasource.Channel
+		infos.Length = uint64(len(bindings))
+		infos.Exchange = &datasource.ExchangeInfos{
+			Type:     e.Type,
+			Bindings: uint64(len(bindings)),
+		}
+	}
+	return infos, nil
+}
+
+// GetContent peeks at most contentWindow messages at the head of a queue, without consuming them:
+// they are all requeued once read, and are consequently marked as redelivered. Since the queue is changed,
+// the peek is refused on a read-only data source, unless peekWhenReadOnly is set.
+func (c *RabbitMQClient) GetContent(ctx context.Context, entryPointValue datasource.EntryPoint, filter datasource.Filter, content chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+	if c.datasource.ReadOnly && !c.peekWhenReadOnly {
+		return actionStatus, errors.New("the data source can be only read, and peeking the messages of a queue marks them as redelivered")
+	}
+
+	vhost, kind, name, err := parseEntryPoint(entryPointValue)
+	if err != nil {
+		return actionStatus, err
+	}
+	if kind != queuesKind {
+		return actionStatus, errors.New(fmt.Sprintf("the entry point %s is not a queue", entryPointValue))
+	}
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return actionStatus, err
+	}
+
+	channel, err := c.openChannel(vhost)
+	if err != nil {
+		return actionStatus, err
+	}
+	defer channel.Close()
+	messages, err := peek(channel, name, c.contentWindow, matcher)
+	if err != nil {
+		return actionStatus, err
+	}
+
+	values := make([]interface{}, len(messages))
+	for i, message := range messages {
+		values[i] = message
+	}
+	content <- datasource.DataBatch{
+		Size: uint64(len(values)),
+		Data: values,
+	}
+	return datasource.Completed, nil
+}
+
+// peek gets at most count messages of the queue and requeues them all at once, in order to not read twice
+// the same message.
+func peek(channel amqpChannel, queue string, count int64, matcher *datasource.Matcher) ([]AmqpMessage, error) {
+	var (
+		messages []AmqpMessage
+		lastTag  uint64
+		err      error
+	)
+	for i := int64(0); i < count; i++ {
+		delivery, ok, getErr := channel.Get(queue, false)
+		if getErr != nil {
+			err = getErr
+			break
+		}
+		if !ok {
+			break
+		}
+		lastTag = delivery.DeliveryTag
+		if message := toAmqpMessage(delivery); matcher.MatchPattern(message.Body) && matcher.MatchFields(message.Headers) {
+			messages = append(messages, message)
+		}
+	}
+	// Closing the channel would also requeue the messages, but they are released as soon as possible.
+	if lastTag > 0 {
+		nackErr := channel.Nack(lastTag, true, true)
+		if err == nil {
+			err = nackErr
+		}
+	}
+	return messages, err
+}
+
+func toAmqpMessage(delivery amqp.Delivery) AmqpMessage {
+	return AmqpMessage{
+		Exchange:    delivery.Exchange,
+		RoutingKey:  delivery.RoutingKey,
+		MessageId:   delivery.MessageId,
+		ContentType: delivery.ContentType,
+		Headers:     delivery.Headers,
+		Body:        string(delivery.Body),
+		Timestamp:   delivery.Timestamp,
+		Redelivered: delivery.Redelivered,
+	}
+}
+
+// Consume receives copies of the messages routed to a queue or published to an exchange, with a temporary
+// exclusive queue bound like the queue or to all the messages of the exchange. The consumers of the queue
+// are then not affected. The messages sent with the default exchange directly to the queue cannot be copied.
+// When fromBeginning is true, the messages present in the queue are first peeked.
+func (c *RabbitMQClient) Consume(ctx context.Context, entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch, filter datasource.Filter, fromBeginning bool) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+
+	vhost, kind, name, err := parseEntryPoint(entryPointValue)
+	if err != nil {
+		return actionStatus, err
+	}
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return actionStatus, err
+	}
+	bindings, err := c.shadowBindings(ctx, vhost, kind, name)
+	if err != nil {
+		return actionStatus, err
+	}
+	if len(bindings) == 0 {
+		return actionStatus, errors.New(fmt.Sprintf("the entry point %s cannot be consumed, since no binding routes messages to it", entryPointValue))
+	}
+
+	channel, err := c.openChannel(vhost)
+	if err != nil {
+		return actionStatus, err
+	}
+	deliveries, shadowQueue, err := openShadowQueue(channel, bindings)
+	if err != nil {
+		channel.Close()
+		return actionStatus, err
+	}
+	// The shadow queue is bound before peeking, so that no message is missed in between.
+	var existingMessages []AmqpMessage
+	if fromBeginning && kind == queuesKind {
+		existingMessages, err = peek(channel, name, c.contentWindow, matcher)
+		if err != nil {
+			channel.Close()
+			return actionStatus, err
+		}
+	}
+
+	go func() {
+		defer close(target)
+		defer channel.Close()
+		defer channel.QueueDelete(shadowQueue, false, false, true)
+
+		for _, message := range existingMessages {
+			if !sendMessage(ctx, message, target) {
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case delivery, ok := <-deliveries:
+				if !ok {
+					log.Printf("The consumption of %s was interrupted by the server\n", entryPointValue)
+					return
+				}
+				message := toAmqpMessage(delivery)
+				if matcher.MatchPattern(message.Body) && matcher.MatchFields(message.Headers) && !sendMessage(ctx, message, target) {
+					return
+				}
+			}
+		}
+	}()
+
+	return datasource.Moved, nil
+}
+
+// openShadowQueue declares a temporary queue with the bindings and starts consuming it.
+func openShadowQueue(channel amqpChannel, bindings []binding) (<-chan amqp.Delivery, string, error) {
+	shadowQueue, err := channel.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, b := range bindings {
+		err = channel.QueueBind(shadowQueue.Name, b.RoutingKey, b.Source, false, b.Arguments)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	deliveries, err := channel.Consume(shadowQueue.Name, "", true, true, false, false, nil)
+	return deliveries, shadowQueue.Name, err
+}
+
+// sendMessage adds the message to the target and returns false if the consumption was cancelled meanwhile.
+func sendMessage(ctx context.Context, message AmqpMessage, target chan<- datasource.DataBatch) bool {
+	select {
+	case target <- datasource.DataBatch{Size: 1, Data: []interface{}{message}}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// shadowBindings returns the bindings for a temporary queue receiving the same messages as the queue,
+// or all the messages published to the exchange.
+func (c *RabbitMQClient) shadowBindings(ctx context.Context, vhost string, kind string, name string) ([]binding, error) {
+	var bindings []binding
+	if kind == queuesKind {
+		var queueBindings []binding
+		err := c.management(ctx, http.MethodGet, apiPath(queuesKind, vhost, name, "bindings"), &queueBindings)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range queueBindings {
+			// The implicit binding to the default exchange cannot be reproduced.
+			if b.Source != "" {
+				bindings = append(bindings, b)
+			}
+		}
+		return bindings, nil
+	}
+
+	var e exchange
+	err := c.management(ctx, http.MethodGet, apiPath(exchangesKind, vhost, name), &e)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Type {
+	case "topic":
+		bindings = append(bindings, binding{Source: name, RoutingKey: "#"})
+	case "fanout":
+		bindings = append(bindings, binding{Source: name})
+	default:
+		// The direct and headers exchanges have no wildcard: the existing bindings are reproduced.
+		var exchangeBindings []binding
+		err = c.management(ctx, http.MethodGet, apiPath(exchangesKind, vhost, name, "bindings", "source"), &exchangeBindings)
+		if err != nil {
+			return nil, err
+		}
+		distinctBindings := make(map[string]bool)
+		for _, b := range exchangeBindings {
+			signature := fmt.Sprintf("%s %v", b.RoutingKey, b.Arguments)
+			if !distinctBindings[signature] {
+				distinctBindings[signature] = true
+				bindings = append(bindings, binding{Source: name, RoutingKey: b.RoutingKey, Arguments: b.Arguments})
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// GetInfos lists the nodes of the cluster, with their type (disc or ram) as role.
+func (c *RabbitMQClient) GetInfos(ctx context.Context) (datasource.Cluster, error) {
+	result := []datasource.ClusterNode{}
+
+	var nodes []node
+	err := c.management(ctx, http.MethodGet, apiPath("nodes"), &nodes)
+	if err != nil {
+		return datasource.Cluster{}, err
+	}
+	for _, n := range nodes {
+		result = append(result, datasource.ClusterNode{
+			Id:     n.Name,
+			Server: n.Name,
+			Name:   n.Name,
+			Role:   n.Type,
+		})
+	}
+	return datasource.Cluster{Nodes: result}, nil
+}
+
+// GetStatus describes the cluster, the totals of its objects and messages, and the resources used by each node.
+func (c *RabbitMQClient) GetStatus(ctx context.Context) (datasource.ClusterState, error) {
+	result := datasource.ClusterState{
+		Timestamp:     time.Now(),
+		NodeStates:    []datasource.NodeState{},
+		StateSections: []datasource.StateSection{},
+	}
+
+	var clusterOverview overview
+	err := c.management(ctx, http.MethodGet, apiPath("overview"), &clusterOverview)
+	if err != nil {
+		return result, err
+	}
+	var nodes []node
+	err = c.management(ctx, http.MethodGet, apiPath("nodes"), &nodes)
+	if err != nil {
+		return result, err
+	}
+
+	result.StateSections = append(result.StateSections,
+		datasource.StateSection{
+			Name: "Cluster",
+			Values: map[string]interface{}{
+				"name":            clusterOverview.ClusterName,
+				"rabbitmqVersion": clusterOverview.RabbitmqVersion,
+				"erlangVersion":   clusterOverview.ErlangVersion,
+				"nodes":           len(nodes),
+			},
+		},
+		datasource.StateSection{Name: "Objects", Values: clusterOverview.ObjectTotals},
+		datasource.StateSection{Name: "Messages", Values: clusterOverview.QueueTotals},
+	)
+	for _, n := range nodes {
+		result.NodeStates = append(result.NodeStates, datasource.NodeState{
+			NodeId: n.Name,
+			StateSections: []datasource.StateSection{{
+				Name: "Node",
+				Values: map[string]interface{}{
+					"running":             n.Running,
+					"type":                n.Type,
+					"memoryUsed":          n.MemUsed,
+					"memoryLimit":         n.MemLimit,
+					"diskFree":            n.DiskFree,
+					"fileDescriptorsUsed": n.FdUsed,
+					"socketsUsed":         n.SocketsUsed,
+					"uptime":              n.Uptime,
+					"partitions":          n.Partitions,
+				},
+			}},
+		})
+	}
+	return result, nil
+}
@@ -9,17 +9,28 @@ import (
 	"github.com/redis/go-redis/v9"
 	"lagoon/datasource"
 	"log"
+	"math"
 	"reflect"
 	regexp2 "regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const scanSize = int64(1000)
 
+// Maximal count of keys deleted by a single command.
+const deleteBatchSize = 100
+
+// Maximal duration of a blocking read on a stream, after which the cancellation of the consumption is checked.
+const consumeBlockingTime = time.Second
+
+// Maximal duration during which an error is still offered to the reader of an operation whose context is done.
+var errorSendDelay = 5 * time.Second
+
 var invalidProtocolError struct {
 	protocol string
 }
@@ -28,6 +39,9 @@ type RedisClient struct {
 	datasource       *datasource.DataSourceDescriptor
 	client           redis.Cmdable
 	readOnlyCommands []string
+	trash            *datasource.Trash
+	// Set to 1 once the server rejected UNLINK.
+	unlinkUnsupported int32
 }
 
 type RedisVendor struct {
@@ -43,6 +57,79 @@ type HashValue struct {
 	Value string `json:"value"`
 }
 
+type ChannelMessage struct {
+	Channel   string    `json:"channel"`
+	Pattern   string    `json:"pattern,omitempty"`
+	Payload   string    `json:"payload"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// subscriber is implemented by the clients supporting publish/subscribe.
+type subscriber interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	PSubscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+// Kinds of the events of a watched key.
+const (
+	KeyCreated = "create"
+	KeyUpdated = "update"
+	KeyExpired = "expire"
+	KeyDeleted = "delete"
+)
+
+// Prefix of the channels of the keyspace notifications, for all the databases.
+const keyspaceChannelPrefix = "__keyspace@*__:"
+
+type KeyspaceEvent struct {
+	Key       string    `json:"key"`
+	Event     string    `json:"event"`
+	Command   string    `json:"command"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type StreamMessage struct {
+	Id        string                 `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the members.
+func (s SortedSetValues) DecodeValues(decode func(value string) interface{}) interface{} {
+	values := make([]interface{}, len(s.Values))
+	for i, value := range s.Values {
+		values[i] = decode(value)
+	}
+	return struct {
+		SortedSetValues
+		Values []interface{} `json:"values"`
+	}{s, values}
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the value of the field.
+func (h HashValue) DecodeValues(decode func(value string) interface{}) interface{} {
+	return struct {
+		HashValue
+		Value interface{} `json:"value"`
+	}{h, decode(h.Value)}
+}
+
+// DecodeValues implements datasource.DecodableValue, by decoding the values of the fields.
+func (m StreamMessage) DecodeValues(decode func(value string) interface{}) interface{} {
+	fields := make(map[string]interface{}, len(m.Fields))
+	for name, value := range m.Fields {
+		if text, ok := value.(string); ok {
+			fields[name] = decode(text)
+		} else {
+			fields[name] = value
+		}
+	}
+	return struct {
+		StreamMessage
+		Fields map[string]interface{} `json:"fields"`
+	}{m, fields}
+}
+
 func init() {
 	datasource.DeclareImplementation(&RedisVendor{})
 }
@@ -96,21 +183,21 @@ func (c *RedisVendor) CreateDataSource(source *datasource.DataSourceDescriptor)
 	return &datasource, err
 }
 
-func (c *RedisClient) GetInfos() (datasource.Cluster, error) {
+func (c *RedisClient) GetInfos(ctx context.Context) (datasource.Cluster, error) {
 	switch c.client.(type) {
 	case *redis.ClusterClient:
 		client := c.client.(*redis.ClusterClient)
-		return getClusterInfos(client)
+		return getClusterInfos(ctx, client)
 	default:
 		return datasource.Cluster{}, nil
 	}
 }
 
 // getClusterInfos returns the list of nodes of the server.
-func getClusterInfos(c *redis.ClusterClient) (datasource.Cluster, error) {
+func getClusterInfos(ctx context.Context, c *redis.ClusterClient) (datasource.Cluster, error) {
 	result := []datasource.ClusterNode{}
 
-	clusterNodes, err := c.ClusterNodes(context.Background()).Result()
+	clusterNodes, err := c.ClusterNodes(ctx).Result()
 	if err != nil {
 		return datasource.Cluster{}, err
 	}
@@ -130,14 +217,14 @@ func getClusterInfos(c *redis.ClusterClient) (datasource.Cluster, error) {
 			result = append(result, node)
 		}
 	}
-	return datasource.Cluster{result}, nil
+	return datasource.Cluster{Nodes: result}, nil
 }
 
-func (c *RedisClient) GetStatus() (datasource.ClusterState, error) {
+func (c *RedisClient) GetStatus(ctx context.Context) (datasource.ClusterState, error) {
 	switch c.client.(type) {
 	case *redis.ClusterClient:
 		client := c.client.(*redis.ClusterClient)
-		return getClusterStatus(client)
+		return getClusterStatus(ctx, client)
 	default:
 		result := datasource.ClusterState{
 			Timestamp:  time.Now(),
@@ -149,7 +236,7 @@ func (c *RedisClient) GetStatus() (datasource.ClusterState, error) {
 			StateSections: []datasource.StateSection{},
 		}
 		client := c.client.(*redis.Client)
-		err := getNodeInfo(client, &nodeState)
+		err := getNodeInfo(ctx, client, &nodeState)
 		if err != nil {
 			return result, nil
 		}
@@ -159,7 +246,7 @@ func (c *RedisClient) GetStatus() (datasource.ClusterState, error) {
 }
 
 // getClusterStatus returns the overall status of the cluster and the individual of each node.
-func getClusterStatus(c *redis.ClusterClient) (datasource.ClusterState, error) {
+func getClusterStatus(ctx context.Context, c *redis.ClusterClient) (datasource.ClusterState, error) {
 	result := datasource.ClusterState{
 		Timestamp:     time.Now(),
 		NodeStates:    []datasource.NodeState{},
@@ -167,7 +254,7 @@ func getClusterStatus(c *redis.ClusterClient) (datasource.ClusterState, error) {
 	}
 
 	// First collect the infos at the cluster level.
-	clusterInfos, err := c.ClusterInfo(context.Background()).Result()
+	clusterInfos, err := c.ClusterInfo(ctx).Result()
 	if err != nil {
 		return result, err
 	}
@@ -182,14 +269,14 @@ func getClusterStatus(c *redis.ClusterClient) (datasource.ClusterState, error) {
 	result.StateSections = append(result.StateSections, clusterSection)
 
 	// Then collect at the node level.
-	err = c.ForEachShard(context.Background(), func(ctx context.Context, nodeClient *redis.Client) error {
+	err = c.ForEachShard(ctx, func(ctx context.Context, nodeClient *redis.Client) error {
 		cmd := nodeClient.Do(ctx, "cluster", "myid")
 		if cmd.Err() == nil {
 			nodeState := datasource.NodeState{
 				NodeId:        cmd.String(),
 				StateSections: []datasource.StateSection{},
 			}
-			err := getNodeInfo(nodeClient, &nodeState)
+			err := getNodeInfo(ctx, nodeClient, &nodeState)
 			if err != nil {
 				return err
 			}
@@ -200,8 +287,8 @@ func getClusterStatus(c *redis.ClusterClient) (datasource.ClusterState, error) {
 	return result, err
 }
 
-func getNodeInfo(nodeClient *redis.Client, nodeState *datasource.NodeState) error {
-	nodeInfos, err := nodeClient.Info(context.Background()).Result()
+func getNodeInfo(ctx context.Context, nodeClient *redis.Client, nodeState *datasource.NodeState) error {
+	nodeInfos, err := nodeClient.Info(ctx).Result()
 	if err != nil {
 		return err
 	}
@@ -240,7 +327,13 @@ func convertClusterInfoAndPutValue(v string, section map[string]interface{}) {
 }
 
 func (c *RedisClient) Open() error {
-	err := c.createConnection()
+	trash, err := datasource.NewTrashFromConfiguration(c.datasource.Configuration)
+	if err != nil {
+		return err
+	}
+	c.trash = trash
+
+	err = c.createConnection()
 	if err == nil {
 		pong, err := c.client.Ping(context.Background()).Result()
 		if err == nil {
@@ -601,78 +694,122 @@ func (c *RedisClient) Close() {
 	}
 }
 
-func (c *RedisClient) ListEntryPoints(filter string, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
-	// TODO Add list of the channels
-	// https://stackoverflow.com/questions/8165188/redis-command-to-get-all-available-channels-for-pub-sub
-
+func (c *RedisClient) ListEntryPoints(ctx context.Context, filter datasource.Filter, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
+	// The publish/subscribe channels are listed apart, with ListChannels.
 	var (
 		err          error
 		actionStatus datasource.ActionStatus
 	)
 
-	err = c.client.Ping(context.Background()).Err()
+	scanFilter := filter.Glob
+	if scanFilter == "" {
+		scanFilter = "*"
+	}
+	var regexFilter *regexp2.Regexp
+	if filter.Regex != "" {
+		regexFilter, err = regexp2.Compile(filter.Regex)
+		if err != nil {
+			return actionStatus, err
+		}
+	}
+
+	err = c.client.Ping(ctx).Err()
 	if err == nil {
-		go c.extractEntryPointsWithLevels(err, filter, minTreeLevel, maxTreeLevel, entrypointsChannel)
+		go c.extractEntryPointsWithLevels(ctx, scanFilter, regexFilter, minTreeLevel, maxTreeLevel, entrypointsChannel)
 		actionStatus = datasource.Moved
 	}
 	return actionStatus, err
 }
 
-func (c *RedisClient) extractEntryPointsWithLevels(err error, filter string, minTreeLevel uint, maxTreeLevel uint, entrypointsChannel chan<- datasource.DataBatch) {
-	filterTokens := strings.Split(filter, ",")
-	scanFilter := filterTokens[0]
-	var regexFilter *regexp2.Regexp
-	if len(filterTokens) > 1 {
-		regexFilter, _ = regexp2.Compile(filterTokens[1])
-	}
-
-	scannedKeyCount, entrypoints, err := c.scanAllNodes(scanFilter, regexFilter, minTreeLevel, maxTreeLevel)
+func (c *RedisClient) extractEntryPointsWithLevels(ctx context.Context, scanFilter string, regexFilter *regexp2.Regexp, minTreeLevel uint, maxTreeLevel uint, entrypointsChannel chan<- datasource.DataBatch) {
+	scannedKeyCount, entrypoints, err := c.scanAllNodes(ctx, scanFilter, regexFilter, minTreeLevel, maxTreeLevel, func(progress datasource.ScanProgress) {
+		// The progress is only indicative and is dropped when the reader is late.
+		select {
+		case entrypointsChannel <- datasource.NewProgressBatch(progress):
+		default:
+		}
+	})
 	if err != nil {
 		log.Printf("ERROR while scanning: %s\n", err.Error())
 	} else {
 		log.Printf("Number of scanned keys: %d\n", scannedKeyCount)
-		var orderedKeys []string
-		for e, _ := range entrypoints {
-			orderedKeys = append(orderedKeys, e)
-		}
-		sort.Strings(orderedKeys)
-		var valuesToSend []interface{}
-		var node *datasource.EntryPointNode
-		for _, e := range orderedKeys {
-			node = entrypoints[e]
-			node.Path = datasource.EntryPoint(e)
-			valuesToSend = append(valuesToSend, node)
+	}
 
-			// Push messages when valuesToSend is equal to the scan size.
-			if int64(len(valuesToSend)) == scanSize {
-				c.sendValuesToChannel(valuesToSend, entrypointsChannel)
-				valuesToSend = nil
-			}
-		}
-		// After the loop, there might be residual values.
-		if len(valuesToSend) > 0 {
-			c.sendValuesToChannel(valuesToSend, entrypointsChannel)
+	// When the scan failed, the nodes found so far are sent before the error, so that the tree is known as incomplete.
+	var orderedKeys []string
+	for e, _ := range entrypoints {
+		orderedKeys = append(orderedKeys, e)
+	}
+	sort.Strings(orderedKeys)
+	var valuesToSend []interface{}
+	var node *datasource.EntryPointNode
+	for _, e := range orderedKeys {
+		node = entrypoints[e]
+		node.Path = datasource.EntryPoint(e)
+		valuesToSend = append(valuesToSend, node)
+
+		// Push messages when valuesToSend is equal to the scan size.
+		if int64(len(valuesToSend)) == scanSize {
+			c.sendValuesToChannel(ctx, valuesToSend, entrypointsChannel)
+			valuesToSend = nil
 		}
 	}
+	// After the loop, there might be residual values.
+	if len(valuesToSend) > 0 {
+		c.sendValuesToChannel(ctx, valuesToSend, entrypointsChannel)
+	}
+	if err != nil {
+		c.sendErrorToChannel(ctx, err, entrypointsChannel)
+	}
 
 	close(entrypointsChannel)
 }
 
-func (c *RedisClient) scanAllNodes(scanFilter string, regexFilter *regexp2.Regexp, minTreeLevel uint, maxTreeLevel uint) (int, map[string]*datasource.EntryPointNode, error) {
+// scanAllNodes scans the keys of all the master nodes and builds the tree of entry points. When reportProgress is
+// not nil, it is called after each page of keys and each completed node.
+func (c *RedisClient) scanAllNodes(ctx context.Context, scanFilter string, regexFilter *regexp2.Regexp, minTreeLevel uint, maxTreeLevel uint, reportProgress func(progress datasource.ScanProgress)) (int, map[string]*datasource.EntryPointNode, error) {
 	var (
 		err             error
 		scannedKeyCount int
 	)
 
+	progress := datasource.ScanProgress{Nodes: 1}
+	progressMutex := sync.Mutex{}
+	onScannedKeys := func(count int, nodeDone bool) {
+		if reportProgress == nil {
+			return
+		}
+		progressMutex.Lock()
+		progress.ScannedKeys += uint64(count)
+		if nodeDone {
+			progress.ScannedNodes++
+		}
+		current := progress
+		progressMutex.Unlock()
+		reportProgress(current)
+	}
+
 	entrypoints := make(map[string]*datasource.EntryPointNode)
 	switch client := c.client.(type) {
 	case *redis.ClusterClient:
+		var masters int32
+		client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			atomic.AddInt32(&masters, 1)
+			return nil
+		})
+		progress.Nodes = int(masters)
+
 		mutex := sync.Mutex{}
-		loopError := client.ForEachMaster(context.Background(), func(ctx context.Context, node *redis.Client) error {
+		loopError := client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
 			id := node.Do(ctx, "cluster", "myid").Val()
 			log.Printf("Scanning keys on master node %+v\n", id)
-			count, err := c.scanOneNode(node, false, scanFilter, regexFilter, minTreeLevel, maxTreeLevel, entrypoints, func() { mutex.Lock() }, func() { mutex.Unlock() })
+			count, err := c.scanOneNode(ctx, node, false, scanFilter, regexFilter, minTreeLevel, maxTreeLevel, entrypoints, func() { mutex.Lock() }, func() { mutex.Unlock() }, onScannedKeys)
+			mutex.Lock()
 			scannedKeyCount = scannedKeyCount + count
+			mutex.Unlock()
+			if err == nil {
+				onScannedKeys(0, true)
+			}
 			return err
 		})
 
@@ -680,12 +817,15 @@ func (c *RedisClient) scanAllNodes(scanFilter string, regexFilter *regexp2.Regex
 			err = loopError
 		}
 	default:
-		scannedKeyCount, err = c.scanOneNode(c.client, false, scanFilter, regexFilter, minTreeLevel, maxTreeLevel, entrypoints, func() {}, func() {})
+		scannedKeyCount, err = c.scanOneNode(ctx, c.client, false, scanFilter, regexFilter, minTreeLevel, maxTreeLevel, entrypoints, func() {}, func() {}, onScannedKeys)
+		if err == nil {
+			onScannedKeys(0, true)
+		}
 	}
 	return scannedKeyCount, entrypoints, err
 }
 
-func (c *RedisClient) scanOneNode(scanningRedisClient redis.Cmdable, validateOwnership bool, scanFilter string, regexFilter *regexp2.Regexp, minTreeLevel uint, maxTreeLevel uint, entrypoints map[string]*datasource.EntryPointNode, acquireMutex func(), releaseMutex func()) (int, error) {
+func (c *RedisClient) scanOneNode(ctx context.Context, scanningRedisClient redis.Cmdable, validateOwnership bool, scanFilter string, regexFilter *regexp2.Regexp, minTreeLevel uint, maxTreeLevel uint, entrypoints map[string]*datasource.EntryPointNode, acquireMutex func(), releaseMutex func(), onScannedKeys func(count int, nodeDone bool)) (int, error) {
 	var (
 		cursor          uint64
 		keys            []string
@@ -696,9 +836,10 @@ func (c *RedisClient) scanOneNode(scanningRedisClient redis.Cmdable, validateOwn
 	excludedKeys := make(map[string]bool)
 
 	for err == nil {
-		keys, cursor, err = scanningRedisClient.Scan(context.Background(), cursor, scanFilter, scanSize).Result()
+		keys, cursor, err = scanningRedisClient.Scan(ctx, cursor, scanFilter, scanSize).Result()
 		if err == nil {
 			scannedKeyCount = scannedKeyCount + len(keys)
+			onScannedKeys(len(keys), false)
 			for _, key := range keys {
 				if regexFilter != nil && !regexFilter.Match([]byte(key)) {
 					excludedKeys[key] = true
@@ -707,7 +848,7 @@ func (c *RedisClient) scanOneNode(scanningRedisClient redis.Cmdable, validateOwn
 
 				if validateOwnership {
 					// If the node belongs to a cluster, we validate the key exists and ignore it otherwise.
-					nodeType, err := scanningRedisClient.Type(context.Background(), key).Result()
+					nodeType, err := scanningRedisClient.Type(ctx, key).Result()
 					if "none" == strings.ToLower(nodeType) || err != nil {
 						continue
 					}
@@ -779,13 +920,132 @@ func (c *RedisClient) scanOneNode(scanningRedisClient redis.Cmdable, validateOwn
 	return scannedKeyCount, err
 }
 
-func (c *RedisClient) scan(filter string, dataChannel chan<- datasource.DataBatch, scanFn func(cursor uint64, match string, count int64) *redis.ScanCmd, formatFn func(values []string) interface{}) (datasource.ActionStatus, error) {
+// AnalyzeEntryPoints scans all the masters concurrently, like scanAllNodes, and samples the memory usage, length
+// and expiry of each key with pipelined commands.
+func (c *RedisClient) AnalyzeEntryPoints(ctx context.Context, analysis *datasource.Analysis) error {
+	switch client := c.client.(type) {
+	case *redis.ClusterClient:
+		var masters int32
+		client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			atomic.AddInt32(&masters, 1)
+			return nil
+		})
+		analysis.SetNodes(int(masters))
+
+		return client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			id := node.Do(ctx, "cluster", "myid").Val()
+			log.Printf("Analyzing keys on master node %+v\n", id)
+			return analyzeNode(ctx, node, analysis)
+		})
+	default:
+		return analyzeNode(ctx, c.client, analysis)
+	}
+}
+
+func analyzeNode(ctx context.Context, node redis.Cmdable, analysis *datasource.Analysis) error {
+	var cursor uint64
+	for {
+		keys, nextCursor, err := node.Scan(ctx, cursor, analysis.Options().Filter, scanSize).Result()
+		if err != nil {
+			return err
+		}
+		samples, err := sampleKeys(ctx, node, keys, analysis.Options().Samples)
+		if err != nil {
+			return err
+		}
+		for _, sample := range samples {
+			_, tokens := split(sample.Key)
+			analysis.Add(sample, tokens, pathSeparatorAsString)
+		}
+		analysis.Scanned(len(keys), nextCursor == 0)
+		if nextCursor == 0 {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// sampleKeys reads the type, expiry and memory usage of the keys, then their length, with two pipelines.
+// The keys deleted meanwhile are ignored.
+func sampleKeys(ctx context.Context, node redis.Cmdable, keys []string, samples int) ([]datasource.KeySample, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	types := make([]*redis.StatusCmd, len(keys))
+	timeToLives := make([]*redis.DurationCmd, len(keys))
+	memoryUsages := make([]*redis.IntCmd, len(keys))
+	// The errors are checked for each command, MEMORY USAGE failing with redis.Nil for the deleted keys.
+	_, _ = node.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+		for i, key := range keys {
+			types[i] = pipeliner.Type(ctx, key)
+			timeToLives[i] = pipeliner.PTTL(ctx, key)
+			if samples > 0 {
+				memoryUsages[i] = pipeliner.MemoryUsage(ctx, key, samples)
+			} else {
+				memoryUsages[i] = pipeliner.MemoryUsage(ctx, key)
+			}
+		}
+		return nil
+	})
+
+	result := make([]datasource.KeySample, 0, len(keys))
+	lengths := make([]*redis.IntCmd, 0, len(keys))
+	for i := range keys {
+		if err := types[i].Err(); err != nil {
+			return nil, err
+		}
+		if err := memoryUsages[i].Err(); err != nil && err != redis.Nil {
+			return nil, err
+		}
+	}
+	_, err := node.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+		for i, key := range keys {
+			var length *redis.IntCmd
+			var entryPointType datasource.EntryPointType
+			switch strings.ToLower(types[i].Val()) {
+			case "string":
+				entryPointType, length = datasource.Value, pipeliner.StrLen(ctx, key)
+			case "set":
+				entryPointType, length = datasource.Set, pipeliner.SCard(ctx, key)
+			case "zset":
+				entryPointType, length = datasource.ScoredSet, pipeliner.ZCard(ctx, key)
+			case "list":
+				entryPointType, length = datasource.List, pipeliner.LLen(ctx, key)
+			case "hash":
+				entryPointType, length = datasource.Hash, pipeliner.HLen(ctx, key)
+			case "stream":
+				entryPointType, length = datasource.Stream, pipeliner.XLen(ctx, key)
+			default:
+				// The key was deleted since it was scanned.
+				continue
+			}
+			result = append(result, datasource.KeySample{
+				Key:         key,
+				Type:        datasource.EntryPointTypesAsString[entryPointType],
+				MemoryUsage: memoryUsages[i].Val(),
+				// PTTL returns -1 when the key does not expire.
+				HasTimeToLive: timeToLives[i].Val() >= 0,
+			})
+			lengths = append(lengths, length)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Length = uint64(lengths[i].Val())
+	}
+	return result, nil
+}
+
+func (c *RedisClient) scan(ctx context.Context, filter string, dataChannel chan<- datasource.DataBatch, scanFn func(cursor uint64, match string, count int64) *redis.ScanCmd, formatFn func(values []string) interface{}) (datasource.ActionStatus, error) {
 	var (
 		err          error
 		actionStatus datasource.ActionStatus
 	)
 
-	err = c.client.Ping(context.Background()).Err()
+	err = c.client.Ping(ctx).Err()
 	if err != nil {
 		return actionStatus, err
 	} else {
@@ -798,7 +1058,7 @@ func (c *RedisClient) scan(filter string, dataChannel chan<- datasource.DataBatc
 		for len(dataChannel) < cap(dataChannel) {
 			values, cursor, err = scanFn(cursor, filter, scanSize).Result()
 			if err == nil {
-				c.sendValuesToChannel(formatFn(values), dataChannel)
+				c.sendValuesToChannel(ctx, formatFn(values), dataChannel)
 				if cursor == 0 {
 					actionStatus = datasource.Completed
 					break
@@ -813,18 +1073,24 @@ func (c *RedisClient) scan(filter string, dataChannel chan<- datasource.DataBatc
 			actionStatus = datasource.Moved
 
 			go func() {
-				for cursor != 0 {
+				// The scan stops as soon as the context is cancelled, when the reader leaves or the timeout is over.
+				for cursor != 0 && ctx.Err() == nil {
 					values, cursor, err = scanFn(cursor, filter, scanSize).Result()
-					c.sendValuesToChannel(formatFn(values), dataChannel)
-
 					if err != nil {
 						log.Printf("ERROR: %s\n", err.Error())
+						c.sendErrorToChannel(ctx, err, dataChannel)
 						cursor = 0
 					} else {
+						c.sendValuesToChannel(ctx, formatFn(values), dataChannel)
 						log.Printf("Cursor: %d, values length: %d\n", cursor, len(values))
 
 					}
 				}
+				if cursor != 0 {
+					// The listing was cut short by the timeout or a cancellation, the reader is told it is incomplete.
+					log.Printf("The scan was interrupted: %s\n", ctx.Err().Error())
+					c.sendErrorToChannel(ctx, ctx.Err(), dataChannel)
+				}
 
 				close(dataChannel)
 				log.Println("Leaving the reading routine")
@@ -835,13 +1101,13 @@ func (c *RedisClient) scan(filter string, dataChannel chan<- datasource.DataBatc
 	return actionStatus, err
 }
 
-func (c *RedisClient) fullScan(filter string, scanFn func(cursor uint64, match string, count int64) *redis.ScanCmd, appendFn func([]interface{}, []string) []interface{}) (datasource.DataBatch, error) {
+func (c *RedisClient) fullScan(ctx context.Context, filter string, scanFn func(cursor uint64, match string, count int64) *redis.ScanCmd, appendFn func([]interface{}, []string) []interface{}) (datasource.DataBatch, error) {
 	var (
 		err    error
 		result datasource.DataBatch
 	)
 
-	err = c.client.Ping(context.Background()).Err()
+	err = c.client.Ping(ctx).Err()
 	if err == nil {
 		var cursor uint64
 
@@ -866,7 +1132,7 @@ func (c *RedisClient) fullScan(filter string, scanFn func(cursor uint64, match s
 	return result, err
 }
 
-func (c *RedisClient) sendValuesToChannel(values interface{}, target chan<- datasource.DataBatch) {
+func (c *RedisClient) sendValuesToChannel(ctx context.Context, values interface{}, target chan<- datasource.DataBatch) {
 	var data []interface{}
 	var stringData []string
 	var ok bool
@@ -891,14 +1157,38 @@ func (c *RedisClient) sendValuesToChannel(values interface{}, target chan<- data
 	}
 
 	if len(data) > 0 {
-		target <- datasource.DataBatch{
+		// The values are dropped when the reading was abandoned.
+		select {
+		case target <- datasource.DataBatch{
 			Size: uint64(len(data)),
 			Data: data,
+		}:
+		case <-ctx.Done():
 		}
 	}
 }
 
-func (c *RedisClient) GetEntryPointInfos(entryPointValue datasource.EntryPoint) (datasource.EntryPointInfos, error) {
+// sendErrorToChannel reports the failure of an operation whose result is read asynchronously.
+// Once the context is done, the error is still sent if the reader takes it within errorSendDelay,
+// so that a truncated result can be told from a complete one.
+func (c *RedisClient) sendErrorToChannel(ctx context.Context, err error, target chan<- datasource.DataBatch) {
+	batch := datasource.NewErrorBatch(err)
+	select {
+	case target <- batch:
+		return
+	case <-ctx.Done():
+	}
+
+	timer := time.NewTimer(errorSendDelay)
+	defer timer.Stop()
+	select {
+	case target <- batch:
+	case <-timer.C:
+		log.Printf("ERROR not read before the end of the operation: %s\n", err.Error())
+	}
+}
+
+func (c *RedisClient) GetEntryPointInfos(ctx context.Context, entryPointValue datasource.EntryPoint) (datasource.EntryPointInfos, error) {
 	key := string(entryPointValue)
 
 	var (
@@ -906,38 +1196,63 @@ func (c *RedisClient) GetEntryPointInfos(entryPointValue datasource.EntryPoint)
 		err     error
 	)
 
-	keyType, err = c.client.Type(context.Background(), key).Result()
-	var infos datasource.EntryPointInfos
+	keyType, err = c.client.Type(ctx, key).Result()
+	var (
+		infos       datasource.EntryPointInfos
+		location    *datasource.LocationInfos
+		objectInfos *datasource.ObjectInfos
+	)
+
+	// TYPE does not touch the key, but the commands reading its length do: the idle time and frequency of the key
+	// have to be read before them.
+	if err == nil && strings.ToLower(keyType) != "none" {
+		node := c.client
+		if clusterClient, isCluster := c.client.(*redis.ClusterClient); isCluster {
+			// The commands OBJECT are not routed by the cluster client, they are sent to the master of the key.
+			if location, err = getLocationInfos(ctx, clusterClient, key); err != nil {
+				return infos, err
+			}
+			if node, err = clusterClient.MasterForKey(ctx, key); err != nil {
+				return infos, err
+			}
+		}
+		objectInfos = getObjectInfos(ctx, node, key)
+	}
 
 	if err == nil {
 		var result datasource.EntryPointType
 		length := uint64(0)
 		timeToLive := time.Duration(-1)
+		var stream *datasource.StreamInfos
 		t := strings.ToLower(keyType)
 		switch t {
 		case "string":
 			result = datasource.Value
-			length = uint64(c.client.StrLen(context.Background(), key).Val())
-			timeToLive = c.client.PTTL(context.Background(), key).Val()
+			length = uint64(c.client.StrLen(ctx, key).Val())
+			timeToLive = c.client.PTTL(ctx, key).Val()
 		case "set":
 			result = datasource.Set
-			length = uint64(c.client.SCard(context.Background(), key).Val())
-			timeToLive = c.client.PTTL(context.Background(), key).Val()
+			length = uint64(c.client.SCard(ctx, key).Val())
+			timeToLive = c.client.PTTL(ctx, key).Val()
 		case "zset":
 			result = datasource.ScoredSet
-			length = uint64(c.client.ZCard(context.Background(), key).Val())
-			timeToLive = c.client.PTTL(context.Background(), key).Val()
+			length = uint64(c.client.ZCard(ctx, key).Val())
+			timeToLive = c.client.PTTL(ctx, key).Val()
 		case "list":
 			result = datasource.List
-			length = uint64(c.client.LLen(context.Background(), key).Val())
-			timeToLive = c.client.PTTL(context.Background(), key).Val()
+			length = uint64(c.client.LLen(ctx, key).Val())
+			timeToLive = c.client.PTTL(ctx, key).Val()
 		case "hash":
 			result = datasource.Hash
-			length = uint64(c.client.HLen(context.Background(), key).Val())
-			timeToLive = c.client.PTTL(context.Background(), key).Val()
+			length = uint64(c.client.HLen(ctx, key).Val())
+			timeToLive = c.client.PTTL(ctx, key).Val()
 		case "stream":
 			result = datasource.Stream
-			length = uint64(c.client.XLen(context.Background(), key).Val())
+			var streamInfos datasource.StreamInfos
+			streamInfos, err = c.getStreamInfos(ctx, key, 0)
+			length = streamInfos.Length
+			stream = &streamInfos
+			timeToLive = c.client.PTTL(ctx, key).Val()
 		case "none":
 			err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
 		default:
@@ -947,268 +1262,1844 @@ func (c *RedisClient) GetEntryPointInfos(entryPointValue datasource.EntryPoint)
 			Type:       result,
 			Length:     length,
 			TimeToLive: timeToLive,
+			Stream:     stream,
+			Object:     objectInfos,
+			Location:   location,
 		}
 	}
 
 	return infos, err
 }
 
-func (c *RedisClient) DeleteEntrypoint(entryPointValue datasource.EntryPoint) error {
+// getObjectInfos returns how the key is stored by the node. The commands which fail, because they are not supported
+// by the server or not allowed by its eviction policy, leave their values empty.
+func getObjectInfos(ctx context.Context, node redis.Cmdable, key string) *datasource.ObjectInfos {
+	var (
+		memoryUsage *redis.IntCmd
+		encoding    *redis.StringCmd
+		idleTime    *redis.DurationCmd
+		frequency   *redis.Cmd
+	)
+	_, _ = node.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+		memoryUsage = pipeliner.MemoryUsage(ctx, key)
+		encoding = pipeliner.ObjectEncoding(ctx, key)
+		idleTime = pipeliner.ObjectIdleTime(ctx, key)
+		frequency = pipeliner.Do(ctx, "object", "freq", key)
+		return nil
+	})
+
+	objectInfos := &datasource.ObjectInfos{
+		MemoryUsage: memoryUsage.Val(),
+		Encoding:    encoding.Val(),
+	}
+	if idleTime.Err() == nil {
+		value := int64(idleTime.Val() / time.Millisecond)
+		objectInfos.IdleTime = &value
+	}
+	if value, err := frequency.Int64(); err == nil {
+		objectInfos.Frequency = &value
+	}
+	return objectInfos
+}
+
+// getLocationInfos returns the hash slot of the key and the nodes serving it.
+func getLocationInfos(ctx context.Context, client *redis.ClusterClient, key string) (*datasource.LocationInfos, error) {
+	slot := keySlot(key)
+	slots, err := client.ClusterSlots(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	location := &datasource.LocationInfos{Slot: slot}
+	for _, slotRange := range slots {
+		if slot < slotRange.Start || slot > slotRange.End || len(slotRange.Nodes) == 0 {
+			continue
+		}
+		// The first node of the range is its master, the other ones are the replicas.
+		location.NodeId = slotRange.Nodes[0].ID
+		location.Node = slotRange.Nodes[0].Addr
+		for _, replica := range slotRange.Nodes[1:] {
+			location.Replicas = append(location.Replicas, replica.Addr)
+		}
+		break
+	}
+	return location, nil
+}
+
+func (c *RedisClient) DeleteEntrypoint(ctx context.Context, entryPointValue datasource.EntryPoint) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if err := c.saveToTrash(ctx, []string{string(entryPointValue)}, "delete"); err != nil {
+		return err
+	}
+	_, err := c.deleteKeys(ctx, []string{string(entryPointValue)})
+	return err
+}
+
+// Trash returns the trash of the data source, nil when it is disabled.
+func (c *RedisClient) Trash() *datasource.Trash {
+	return c.trash
+}
+
+func (c *RedisClient) ListTrashEntries(ctx context.Context) ([]datasource.TrashEntry, error) {
+	if c.trash == nil {
+		return []datasource.TrashEntry{}, nil
+	}
+	return c.trash.List(), nil
+}
+
+func (c *RedisClient) RestoreTrashEntry(ctx context.Context, id string, replace bool) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if c.trash == nil {
+		return errors.New("the trash is disabled for the data source")
+	}
+	entry, exists := c.trash.Get(id)
+	if !exists {
+		return errors.New(fmt.Sprintf("the entry %s was not found in the trash", id))
+	}
+	timeToLive := time.Duration(0)
+	if entry.ExpireAt != nil {
+		timeToLive = time.Until(*entry.ExpireAt)
+		if timeToLive <= 0 {
+			return errors.New(fmt.Sprintf("the entry point %s would be already expired", entry.EntryPoint))
+		}
+	}
+	// The current value is itself saved before being replaced.
+	if replace {
+		if err := c.saveToTrash(ctx, []string{string(entry.EntryPoint)}, "restore"); err != nil {
+			return err
+		}
+		err := c.client.RestoreReplace(ctx, string(entry.EntryPoint), timeToLive, string(entry.Payload)).Err()
+		if err != nil {
+			return err
+		}
+	} else {
+		err := c.client.Restore(ctx, string(entry.EntryPoint), timeToLive, string(entry.Payload)).Err()
+		if err != nil {
+			return err
+		}
+	}
+	c.trash.Remove(id)
+	return nil
+}
+
+// saveToTrash saves the serialized values of the existing keys into the trash before the operation changes them.
+func (c *RedisClient) saveToTrash(ctx context.Context, keys []string, operation string) error {
+	if c.trash == nil {
+		return nil
+	}
+	return c.backupKeys(ctx, keys, operation, c.trash.Save)
+}
+
+// backupKeys dumps the existing keys and passes their backups to save, either the trash or a reservation in the trash.
+func (c *RedisClient) backupKeys(ctx context.Context, keys []string, operation string, save func(datasource.TrashEntry) (string, error)) error {
+	for start := 0; start < len(keys); start += int(scanSize) {
+		end := start + int(scanSize)
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var (
+			dumpCmds       []*redis.StringCmd
+			timeToLiveCmds []*redis.DurationCmd
+		)
+		_, err := c.client.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+			for _, key := range keys[start:end] {
+				dumpCmds = append(dumpCmds, pipeliner.Dump(ctx, key))
+				timeToLiveCmds = append(timeToLiveCmds, pipeliner.PTTL(ctx, key))
+			}
+			return nil
+		})
+		// Missing keys have nothing to save.
+		if err != nil && err != redis.Nil {
+			return errors.New(fmt.Sprintf("the backup into the trash failed, the operation is cancelled: %s", err.Error()))
+		}
+		savedAt := time.Now()
+		for i, key := range keys[start:end] {
+			if dumpCmds[i].Err() != nil {
+				continue
+			}
+			entry := datasource.TrashEntry{
+				EntryPoint: datasource.EntryPoint(key),
+				Operation:  operation,
+				Payload:    []byte(dumpCmds[i].Val()),
+				SavedAt:    savedAt,
+			}
+			if timeToLive := timeToLiveCmds[i].Val(); timeToLive > 0 {
+				expireAt := savedAt.Add(timeToLive)
+				entry.ExpireAt = &expireAt
+			}
+			if _, err := save(entry); err != nil {
+				return errors.New(fmt.Sprintf("the backup into the trash failed, the operation is cancelled: %s", err.Error()))
+			}
+		}
+	}
+	return nil
+}
+
+// saveElementChangeToTrash saves the entry point into the trash before the operation adds, changes or removes some of
+// its elements, unless the trash is configured to ignore these changes, since the whole entry point is dumped.
+func (c *RedisClient) saveElementChangeToTrash(ctx context.Context, entryPointValue datasource.EntryPoint, operation string) error {
+	if c.trash == nil || !c.trash.KeepsElementChanges() {
+		return nil
+	}
+	return c.saveToTrash(ctx, []string{string(entryPointValue)}, operation)
+}
+
+func (c *RedisClient) RenameEntrypoint(ctx context.Context, entryPointValue datasource.EntryPoint, newEntryPointValue datasource.EntryPoint) error {
 	if c.datasource.ReadOnly {
 		return errors.New("the data source can be only read")
 	}
-	return c.client.Del(context.Background(), string(entryPointValue)).Err()
+	return c.moveKey(ctx, string(entryPointValue), string(newEntryPointValue))
 }
 
-func (c *RedisClient) DeleteEntrypointChildren(entryPointValue datasource.EntryPoint, errorChannel chan<- error) (datasource.ActionStatus, error) {
+func (c *RedisClient) MoveEntrypointTree(ctx context.Context, entryPointValue datasource.EntryPoint, newEntryPointValue datasource.EntryPoint, dryRun bool, progressChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
 	var (
 		err          error
 		actionStatus datasource.ActionStatus
 	)
-	if c.datasource.ReadOnly {
+	if c.datasource.ReadOnly && !dryRun {
 		return actionStatus, errors.New("the data source can be only read")
 	}
+	source := string(entryPointValue)
+	target := string(newEntryPointValue)
+	if source == "" || target == "" {
+		return actionStatus, errors.New("the entry points to move from and to are required")
+	}
+	if target == source || strings.HasPrefix(target, source+pathSeparatorAsString) {
+		return actionStatus, errors.New(fmt.Sprintf("the entry point %s cannot be moved into itself", source))
+	}
 
-	scanFilter := string(entryPointValue) + ":*"
-
-	err = c.client.Ping(context.Background()).Err()
+	err = c.client.Ping(ctx).Err()
 	if err != nil {
 		return actionStatus, err
-	} else {
-		go func() {
-			defer close(errorChannel)
+	}
+	go func() {
+		defer close(progressChannel)
 
-			_, entrypoints, err := c.scanAllNodes(scanFilter, nil, 0, datasource.MaxLevel)
-			if err != nil {
-				errorChannel <- err
+		progress := datasource.Progress{}
+		sendProgress := func() {
+			select {
+			case progressChannel <- datasource.NewProgressBatch(progress):
+			case <-ctx.Done():
 			}
-			// Exclude the parent endpoint which should have been added.
-			delete(entrypoints, string(entryPointValue))
+			progress.Error = ""
+		}
 
-			total := int64(0)
-			keys := []string{}
-			for k, v := range entrypoints {
-				if v.HasContent {
-					keys = append(keys, k)
-				}
-			}
-			log.Printf("%d entries have to be deleted\n", len(keys))
+		// Nothing is moved when the children are not all known, not to split the tree.
+		keys, err := c.scanChildren(ctx, entryPointValue)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			log.Printf("ERROR while scanning the children of %s, nothing is moved: %s\n", source, err.Error())
+			progress.Error = fmt.Sprintf("the children of %s cannot be listed, nothing is moved: %s", source, err.Error())
+			sendProgress()
+			return
+		}
+		// The entry point itself is moved as well when it has a content.
+		if exists, _ := c.client.Exists(ctx, source).Result(); exists > 0 {
+			keys = append(keys, source)
+		}
+		sort.Strings(keys)
 
-			switch client := c.client.(type) {
-			case *redis.ClusterClient:
-				// On a cluster, keys have to be deleted one by one, or by groups only if all the elements of the group belongs to the same slot.
-				for _, k := range keys {
-					log.Printf("Deleting %s...\n", k)
-					count, err := client.Del(context.Background(), k).Result()
-					total = total + count
-					if err != nil {
-						log.Printf("ERROR while deleting %s: %s\n", k, err.Error())
-						errorChannel <- err
-					} else if count > 1 && total%scanSize == 0 {
-						log.Printf("%d entries were deleted so far\n", total)
-					}
-				}
-			default:
-				total, err = client.Del(context.Background(), keys...).Result()
-				if err != nil {
-					log.Printf("ERROR while deleting keys %s: %s\n", scanFilter, err.Error())
-					errorChannel <- err
-				}
+		if dryRun {
+			c.sendPlannedMoves(ctx, source, target, keys, progressChannel)
+			return
+		}
+
+		progress.Total = uint64(len(keys))
+		log.Printf("%d entries have to be moved from %s to %s\n", len(keys), source, target)
+		sendProgress()
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				log.Printf("The move from %s to %s was cancelled: %s\n", source, target, ctx.Err().Error())
+				break
 			}
-			log.Printf("A total of %d entries were deleted\n", total)
-		}()
-	}
+			err := c.moveKey(ctx, key, target+strings.TrimPrefix(key, source))
+			progress.Processed++
+			if err != nil {
+				log.Printf("ERROR while moving %s: %s\n", key, err.Error())
+				progress.Failed++
+				progress.Error = fmt.Sprintf("%s: %s", key, err.Error())
+				sendProgress()
+			} else if progress.Processed%uint64(scanSize) == 0 || progress.Processed == progress.Total {
+				sendProgress()
+			}
+		}
+		log.Printf("%d entries were moved from %s to %s, %d failed\n", progress.Processed-progress.Failed, source, target, progress.Failed)
+	}()
 	return datasource.Moved, err
 }
 
-func (c *RedisClient) GetContent(entryPointValue datasource.EntryPoint, filter string, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
-	var (
-		err    error
-		result datasource.DataBatch
-	)
-
-	key := string(entryPointValue)
-	statusCmd := c.client.Type(context.Background(), key)
-	err = statusCmd.Err()
-
-	if err == nil {
-		t := strings.ToLower(statusCmd.Val())
-		switch t {
-		case "string":
-			value, err := c.getValue(entryPointValue)
-			if err == nil {
-				result = datasource.DataBatch{
-					Size: 1,
-					Data: []interface{}{value},
-				}
+// sendPlannedMoves adds the moves of the keys from the source to the target entry point to the channel,
+// verifying by groups whether the targets already exist.
+func (c *RedisClient) sendPlannedMoves(ctx context.Context, source string, target string, keys []string, movesChannel chan<- datasource.DataBatch) {
+	for start := 0; start < len(keys); start += int(scanSize) {
+		end := start + int(scanSize)
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var existsCmds []*redis.IntCmd
+		c.client.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+			for _, key := range keys[start:end] {
+				existsCmds = append(existsCmds, pipeliner.Exists(ctx, target+strings.TrimPrefix(key, source)))
 			}
-		case "set":
-			result, err = c.getSetValues(entryPointValue, filter)
-		case "zset":
-			result, err = c.getZSetValues(entryPointValue, filter)
-		case "list":
-			result, err = c.getListValues(entryPointValue, filter)
-		case "hash":
-			result, err = c.getFullHash(entryPointValue, filter)
-		case "stream":
-			// TODO
-			err = errors.New(fmt.Sprintf("Type %s is unsupported", t))
-		case "none":
-			err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
-		default:
-			err = errors.New(fmt.Sprintf("Type %s is unsupported", t))
+			return nil
+		})
+		var moves []interface{}
+		for i, key := range keys[start:end] {
+			moves = append(moves, datasource.EntryPointMove{
+				From:     datasource.EntryPoint(key),
+				To:       datasource.EntryPoint(target + strings.TrimPrefix(key, source)),
+				Conflict: existsCmds[i].Val() > 0,
+			})
+		}
+		select {
+		case movesChannel <- datasource.DataBatch{Size: uint64(len(moves)), Data: moves}:
+		case <-ctx.Done():
+			return
 		}
 	}
-	if err == nil {
-		contentChannel <- result
-	}
-
-	return datasource.Completed, err
 }
 
-func (c *RedisClient) getValue(entryPointValue datasource.EntryPoint) (datasource.SingleValue, error) {
-	key := string(entryPointValue)
-	result := c.client.Get(context.Background(), key)
-	return result.Val(), result.Err()
-}
+// moveKey renames the key, without overwriting an existing one.
+func (c *RedisClient) moveKey(ctx context.Context, from string, to string) error {
+	if _, isCluster := c.client.(*redis.ClusterClient); isCluster && keySlot(from) != keySlot(to) {
+		// RENAME is not allowed between different slots, which can belong to different masters:
+		// the key is copied then deleted.
+		payload, err := c.client.Dump(ctx, from).Result()
+		if err == redis.Nil {
+			return errors.New(fmt.Sprintf("the entry point %s does not exist", from))
+		} else if err != nil {
+			return err
+		}
+		timeToLive, err := c.client.PTTL(ctx, from).Result()
+		if err != nil {
+			return err
+		}
+		if timeToLive < 0 {
+			timeToLive = 0
+		}
+		// The key is saved before its copy is deleted, in case the move is interrupted.
+		if c.trash != nil {
+			entry := datasource.TrashEntry{
+				EntryPoint: datasource.EntryPoint(from),
+				Operation:  "move",
+				Payload:    []byte(payload),
+				SavedAt:    time.Now(),
+			}
+			if timeToLive > 0 {
+				expireAt := entry.SavedAt.Add(timeToLive)
+				entry.ExpireAt = &expireAt
+			}
+			if _, err := c.trash.Save(entry); err != nil {
+				return errors.New(fmt.Sprintf("the backup into the trash failed, the move is cancelled: %s", err.Error()))
+			}
+		}
+		// RESTORE fails if the target already exists.
+		err = c.client.Restore(ctx, to, timeToLive, payload).Err()
+		if err != nil {
+			return err
+		}
+		return c.client.Del(ctx, from).Err()
+	}
+
+	renamed, err := c.client.RenameNX(ctx, from, to).Result()
+	if err == nil && !renamed {
+		err = errors.New(fmt.Sprintf("the entry point %s already exists", to))
+	}
+	return err
+}
+
+func (c *RedisClient) SetExpiry(ctx context.Context, entryPointValue datasource.EntryPoint, expiry datasource.Expiry) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if err := expiry.Validate(); err != nil {
+		return err
+	}
+	exists, err := c.client.Exists(ctx, string(entryPointValue)).Result()
+	if err != nil {
+		return err
+	} else if exists == 0 {
+		return errors.New(fmt.Sprintf("the entry point %s does not exist", entryPointValue))
+	}
+	return expire(ctx, c.client, string(entryPointValue), expiry).Err()
+}
+
+func (c *RedisClient) SetChildrenExpiry(ctx context.Context, entryPointValue datasource.EntryPoint, expiry datasource.Expiry, progressChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var (
+		err          error
+		actionStatus datasource.ActionStatus
+	)
+	if c.datasource.ReadOnly {
+		return actionStatus, errors.New("the data source can be only read")
+	}
+	if err = expiry.Validate(); err != nil {
+		return actionStatus, err
+	}
+
+	scanFilter := string(entryPointValue) + ":*"
+
+	err = c.client.Ping(ctx).Err()
+	if err != nil {
+		return actionStatus, err
+	}
+	go func() {
+		defer close(progressChannel)
+
+		progress := datasource.Progress{}
+		sendProgress := func() {
+			select {
+			case progressChannel <- datasource.NewProgressBatch(progress):
+			case <-ctx.Done():
+			}
+			progress.Error = ""
+		}
+
+		keys, err := c.scanChildren(ctx, entryPointValue)
+		if err != nil {
+			progress.Error = err.Error()
+			sendProgress()
+		}
+		progress.Total = uint64(len(keys))
+		log.Printf("The expiry of %d entries has to be changed\n", len(keys))
+		sendProgress()
+
+		// The commands are pipelined by groups, the cluster client dispatches them to the owning masters.
+		for start := 0; start < len(keys) && ctx.Err() == nil; start += int(scanSize) {
+			end := start + int(scanSize)
+			if end > len(keys) {
+				end = len(keys)
+			}
+			cmds, err := c.client.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+				for _, key := range keys[start:end] {
+					expire(ctx, pipeliner, key, expiry)
+				}
+				return nil
+			})
+			progress.Processed += uint64(end - start)
+			if err != nil {
+				for _, cmd := range cmds {
+					if cmd.Err() != nil {
+						progress.Failed++
+					}
+				}
+				log.Printf("ERROR while changing the expiry of keys %s: %s\n", scanFilter, err.Error())
+				progress.Error = err.Error()
+			}
+			sendProgress()
+		}
+		log.Printf("The expiry of %d entries was changed, %d failed\n", progress.Processed-progress.Failed, progress.Failed)
+	}()
+	return datasource.Moved, err
+}
+
+// expire sets or removes the expiry of the key with the client, which can be a pipeline.
+func expire(ctx context.Context, client redis.Cmdable, key string, expiry datasource.Expiry) *redis.BoolCmd {
+	if expiry.IsPersistent() {
+		return client.Persist(ctx, key)
+	} else if !expiry.ExpireAt.IsZero() {
+		return client.PExpireAt(ctx, key, expiry.ExpireAt)
+	}
+	return client.PExpire(ctx, key, expiry.TimeToLive)
+}
+
+func (c *RedisClient) SetValue(ctx context.Context, entryPointValue datasource.EntryPoint, value string) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if err := c.saveToTrash(ctx, []string{string(entryPointValue)}, "set value"); err != nil {
+		return err
+	}
+	return c.client.Set(ctx, string(entryPointValue), value, 0).Err()
+}
+
+func (c *RedisClient) AddSetMembers(ctx context.Context, entryPointValue datasource.EntryPoint, members []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "add set members"); err != nil {
+		return 0, err
+	}
+	return c.client.SAdd(ctx, string(entryPointValue), toInterfaces(members)...).Result()
+}
+
+func (c *RedisClient) RemoveSetMembers(ctx context.Context, entryPointValue datasource.EntryPoint, members []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "remove set members"); err != nil {
+		return 0, err
+	}
+	return c.client.SRem(ctx, string(entryPointValue), toInterfaces(members)...).Result()
+}
+
+func (c *RedisClient) AddSortedSetMembers(ctx context.Context, entryPointValue datasource.EntryPoint, members []datasource.ScoredMember) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "add sorted set members"); err != nil {
+		return 0, err
+	}
+	var scoredMembers []redis.Z
+	for _, member := range members {
+		scoredMembers = append(scoredMembers, redis.Z{Score: member.Score, Member: member.Member})
+	}
+	return c.client.ZAdd(ctx, string(entryPointValue), scoredMembers...).Result()
+}
+
+func (c *RedisClient) SetHashFields(ctx context.Context, entryPointValue datasource.EntryPoint, fields map[string]string) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "set hash fields"); err != nil {
+		return err
+	}
+	var values []interface{}
+	for field, value := range fields {
+		values = append(values, field, value)
+	}
+	return c.client.HSet(ctx, string(entryPointValue), values...).Err()
+}
+
+func (c *RedisClient) DeleteHashFields(ctx context.Context, entryPointValue datasource.EntryPoint, fields []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "delete hash fields"); err != nil {
+		return 0, err
+	}
+	return c.client.HDel(ctx, string(entryPointValue), fields...).Result()
+}
+
+func (c *RedisClient) PushListValues(ctx context.Context, entryPointValue datasource.EntryPoint, values []string, head bool) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "push list values"); err != nil {
+		return 0, err
+	}
+	if head {
+		return c.client.LPush(ctx, string(entryPointValue), toInterfaces(values)...).Result()
+	}
+	return c.client.RPush(ctx, string(entryPointValue), toInterfaces(values)...).Result()
+}
+
+func (c *RedisClient) SetListValue(ctx context.Context, entryPointValue datasource.EntryPoint, index int64, value string) error {
+	if c.datasource.ReadOnly {
+		return errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "set list value"); err != nil {
+		return err
+	}
+	return c.client.LSet(ctx, string(entryPointValue), index, value).Err()
+}
+
+func (c *RedisClient) RemoveListValue(ctx context.Context, entryPointValue datasource.EntryPoint, value string, count int64) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "remove list value"); err != nil {
+		return 0, err
+	}
+	return c.client.LRem(ctx, string(entryPointValue), count, value).Result()
+}
+
+func (c *RedisClient) AddStreamMessage(ctx context.Context, entryPointValue datasource.EntryPoint, id string, fields map[string]interface{}) (string, error) {
+	if c.datasource.ReadOnly {
+		return "", errors.New("the data source can be only read")
+	}
+	if len(fields) == 0 {
+		return "", errors.New("a message of a stream requires at least one field")
+	}
+	if id == "" {
+		id = "*"
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "add stream message"); err != nil {
+		return "", err
+	}
+	return c.client.XAdd(ctx, &redis.XAddArgs{Stream: string(entryPointValue), ID: id, Values: fields}).Result()
+}
+
+func (c *RedisClient) DeleteStreamMessages(ctx context.Context, entryPointValue datasource.EntryPoint, ids []string) (int64, error) {
+	if c.datasource.ReadOnly {
+		return 0, errors.New("the data source can be only read")
+	}
+	if err := c.saveElementChangeToTrash(ctx, entryPointValue, "delete stream messages"); err != nil {
+		return 0, err
+	}
+	return c.client.XDel(ctx, string(entryPointValue), ids...).Result()
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
+
+func (c *RedisClient) DeleteEntrypointChildren(ctx context.Context, entryPointValue datasource.EntryPoint, rateLimit uint, errorChannel chan<- error) (datasource.ActionStatus, error) {
+	var (
+		err          error
+		actionStatus datasource.ActionStatus
+	)
+	if c.datasource.ReadOnly {
+		return actionStatus, errors.New("the data source can be only read")
+	}
+
+	err = c.client.Ping(ctx).Err()
+	if err != nil {
+		return actionStatus, err
+	} else {
+		go func() {
+			defer close(errorChannel)
+			sendError := func(err error) {
+				select {
+				case errorChannel <- err:
+				case <-ctx.Done():
+				}
+			}
+
+			keys, err := c.scanChildren(ctx, entryPointValue)
+			if err != nil {
+				sendError(err)
+			}
+			log.Printf("%d entries have to be deleted\n", len(keys))
+			// The estimated size of all the backups is reserved in the trash, so that they are not evicted by each other.
+			// Without an estimate, when the command is not allowed, the backups are saved in the trash as they come.
+			save := func(entry datasource.TrashEntry) (string, error) { return "", nil }
+			if c.trash != nil && len(keys) > 0 {
+				memory, err := c.memoryUsage(ctx, keys)
+				if err != nil {
+					log.Printf("WARN the memory usage of the children of %s cannot be evaluated: %s\n", entryPointValue, err.Error())
+					memory = 0
+				}
+				reservation, err := c.trash.Reserve(int(memory))
+				if err != nil {
+					sendError(errors.New(fmt.Sprintf("the children of %s cannot be kept in the trash, the deletion is cancelled: %s", entryPointValue, err.Error())))
+					return
+				}
+				defer reservation.Release()
+				save = reservation.Save
+			}
+			if _, isCluster := c.client.(*redis.ClusterClient); isCluster {
+				// The keys of a same slot are deleted together.
+				sort.SliceStable(keys, func(i, j int) bool {
+					return keySlot(keys[i]) < keySlot(keys[j])
+				})
+			}
+
+			chunkSize := int(scanSize)
+			if rateLimit > 0 && int(rateLimit) < chunkSize {
+				chunkSize = int(rateLimit)
+			}
+			total := int64(0)
+			startTime := time.Now()
+			for start := 0; start < len(keys); start += chunkSize {
+				if ctx.Err() != nil {
+					log.Printf("The deletion of the children of %s was cancelled after %d entries: %s\n", entryPointValue, total, ctx.Err().Error())
+					return
+				}
+				end := start + chunkSize
+				if end > len(keys) {
+					end = len(keys)
+				}
+				err = c.backupKeys(ctx, keys[start:end], "delete children", save)
+				if err != nil {
+					sendError(err)
+					return
+				}
+				count, err := c.deleteKeys(ctx, keys[start:end])
+				total = total + count
+				if err != nil {
+					log.Printf("ERROR while deleting keys of %s: %s\n", entryPointValue, err.Error())
+					sendError(err)
+				} else {
+					log.Printf("%d entries were deleted so far\n", total)
+				}
+
+				if rateLimit > 0 {
+					// Waits until the rate of deleted keys is under the limit.
+					expectedDuration := time.Duration(end) * time.Second / time.Duration(rateLimit)
+					if elapsed := time.Since(startTime); elapsed < expectedDuration {
+						select {
+						case <-time.After(expectedDuration - elapsed):
+						case <-ctx.Done():
+						}
+					}
+				}
+			}
+			log.Printf("A total of %d entries were deleted\n", total)
+		}()
+	}
+	return datasource.Moved, err
+}
+
+// deleteKeys deletes the keys with pipelined commands, grouping the keys by slot on a cluster, since a command
+// can only apply to keys of a single slot. The pipeline of the cluster client dispatches the commands to the owning masters.
+// UNLINK is used to release the memory asynchronously, unless it is not supported by the server.
+func (c *RedisClient) deleteKeys(ctx context.Context, keys []string) (int64, error) {
+	var batches [][]string
+	if _, isCluster := c.client.(*redis.ClusterClient); isCluster {
+		var slots []int
+		keysBySlot := make(map[int][]string)
+		for _, key := range keys {
+			slot := keySlot(key)
+			if _, exists := keysBySlot[slot]; !exists {
+				slots = append(slots, slot)
+			}
+			keysBySlot[slot] = append(keysBySlot[slot], key)
+		}
+		for _, slot := range slots {
+			batches = append(batches, splitKeys(keysBySlot[slot], deleteBatchSize)...)
+		}
+	} else {
+		batches = splitKeys(keys, deleteBatchSize)
+	}
+
+	unlink := atomic.LoadInt32(&c.unlinkUnsupported) == 0
+	cmds, err := c.client.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+		for _, batch := range batches {
+			if unlink {
+				pipeliner.Unlink(ctx, batch...)
+			} else {
+				pipeliner.Del(ctx, batch...)
+			}
+		}
+		return nil
+	})
+	if err != nil && unlink && strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+		log.Printf("UNLINK is not supported by the server, DEL is used instead\n")
+		atomic.StoreInt32(&c.unlinkUnsupported, 1)
+		return c.deleteKeys(ctx, keys)
+	}
+
+	count := int64(0)
+	for _, cmd := range cmds {
+		if intCmd, ok := cmd.(*redis.IntCmd); ok {
+			count = count + intCmd.Val()
+		}
+	}
+	return count, err
+}
+
+// splitKeys splits the keys into batches of at most size keys.
+func splitKeys(keys []string, size int) [][]string {
+	var batches [][]string
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batches = append(batches, keys[start:end])
+	}
+	return batches
+}
+
+func (c *RedisClient) PreviewEntrypointChildrenDeletion(ctx context.Context, entryPointValue datasource.EntryPoint, sampleSize int) (datasource.DeletionPreview, error) {
+	preview := datasource.DeletionPreview{Sample: []datasource.EntryPoint{}}
+	keys, err := c.scanChildren(ctx, entryPointValue)
+	if err != nil {
+		return preview, err
+	}
+	sort.Strings(keys)
+	preview.Count = uint64(len(keys))
+	for i := 0; i < len(keys) && i < sampleSize; i++ {
+		preview.Sample = append(preview.Sample, datasource.EntryPoint(keys[i]))
+	}
+
+	// The memory is not reported when the command is not allowed.
+	preview.Memory, err = c.memoryUsage(ctx, keys)
+	if err != nil {
+		log.Printf("WARN the memory usage of the children of %s cannot be evaluated: %s\n", entryPointValue, err.Error())
+		preview.Memory = 0
+	}
+	return preview, nil
+}
+
+// memoryUsage returns the total memory used by the keys, in bytes. The keys deleted meanwhile are ignored.
+func (c *RedisClient) memoryUsage(ctx context.Context, keys []string) (uint64, error) {
+	total := uint64(0)
+	// The memory usages are requested by groups, the cluster client dispatches them to the owning masters.
+	for start := 0; start < len(keys); start += int(scanSize) {
+		end := start + int(scanSize)
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var memoryCmds []*redis.IntCmd
+		_, err := c.client.Pipelined(ctx, func(pipeliner redis.Pipeliner) error {
+			for _, key := range keys[start:end] {
+				memoryCmds = append(memoryCmds, pipeliner.MemoryUsage(ctx, key))
+			}
+			return nil
+		})
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+		for _, memoryCmd := range memoryCmds {
+			total += uint64(memoryCmd.Val())
+		}
+	}
+	return total, nil
+}
+
+// scanChildren returns the keys of all the children of the entry point, on all the masters, excluding the entry point itself.
+func (c *RedisClient) scanChildren(ctx context.Context, entryPointValue datasource.EntryPoint) ([]string, error) {
+	_, entrypoints, err := c.scanAllNodes(ctx, string(entryPointValue)+":*", nil, 0, datasource.MaxLevel, nil)
+	// Exclude the parent endpoint which should have been added.
+	delete(entrypoints, string(entryPointValue))
+
+	keys := []string{}
+	for k, v := range entrypoints {
+		if v.HasContent {
+			keys = append(keys, k)
+		}
+	}
+	return keys, err
+}
+
+func (c *RedisClient) GetContent(ctx context.Context, entryPointValue datasource.EntryPoint, filter datasource.Filter, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var (
+		err    error
+		result datasource.DataBatch
+	)
+
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return datasource.None, err
+	}
+
+	key := string(entryPointValue)
+	statusCmd := c.client.Type(ctx, key)
+	err = statusCmd.Err()
+
+	if err == nil {
+		t := strings.ToLower(statusCmd.Val())
+		switch t {
+		case "string":
+			value, err := c.getValue(ctx, entryPointValue)
+			if err == nil {
+				result = datasource.DataBatch{
+					Size: 1,
+					Data: []interface{}{value},
+				}
+			}
+		case "set":
+			result, err = c.getSetValues(ctx, entryPointValue, matcher)
+		case "zset":
+			result, err = c.getZSetValues(ctx, entryPointValue, matcher)
+		case "list":
+			result, err = c.getListValues(ctx, entryPointValue, matcher)
+		case "hash":
+			result, err = c.getFullHash(ctx, entryPointValue, matcher)
+		case "stream":
+			return c.sendStreamContent(ctx, entryPointValue, matcher, contentChannel)
+		case "none":
+			err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+		default:
+			err = errors.New(fmt.Sprintf("Type %s is unsupported", t))
+		}
+	}
+	if err == nil {
+		contentChannel <- result
+	}
+
+	return datasource.Completed, err
+}
+
+func (c *RedisClient) getValue(ctx context.Context, entryPointValue datasource.EntryPoint) (datasource.SingleValue, error) {
+	key := string(entryPointValue)
+	result := c.client.Get(ctx, key)
+	return result.Val(), result.Err()
+}
+
+func (c *RedisClient) getSetValues(ctx context.Context, entryPointValue datasource.EntryPoint, matcher *datasource.Matcher) (datasource.DataBatch, error) {
+	return c.fullScan(ctx, matcher.Filter().Glob, func(cursor uint64, match string, count int64) *redis.ScanCmd {
+		return c.client.SScan(ctx, string(entryPointValue), cursor, match, count)
+	}, setValuesAppender(matcher))
+}
+
+// setValuesAppender returns a function appending the members of a set returned by SSCAN to the previous ones, sorted.
+func setValuesAppender(matcher *datasource.Matcher) func([]interface{}, []string) []interface{} {
+	return func(allValues []interface{}, values []string) (result []interface{}) {
+		result = allValues
+		for _, v := range values {
+			if matcher.MatchRegex(v) && matcher.MatchRange(v) {
+				result = append(result, v)
+			}
+		}
+		// Sort the values.
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].(string) < result[j].(string)
+		})
+		return
+	}
+}
+
+func (c *RedisClient) getZSetValues(ctx context.Context, entryPointValue datasource.EntryPoint, matcher *datasource.Matcher) (datasource.DataBatch, error) {
+	return c.fullScan(ctx, matcher.Filter().Glob, func(cursor uint64, match string, count int64) *redis.ScanCmd {
+		return c.client.ZScan(ctx, string(entryPointValue), cursor, match, count)
+	}, zsetValuesAppender(matcher))
+}
+
+// zsetValuesAppender returns a function merging the members of a sorted set returned by ZSCAN into the previous
+// SortedSetValues, sorted by score.
+func zsetValuesAppender(matcher *datasource.Matcher) func([]interface{}, []string) []interface{} {
+	return func(allValues []interface{}, values []string) (result []interface{}) {
+		scoredValuesMap := make(map[float64]SortedSetValues)
+
+		var previousValue SortedSetValues
+		for _, val := range allValues {
+			previousValue = val.(SortedSetValues)
+			scoredValuesMap[previousValue.Score] = previousValue
+		}
+
+		// Result slice contains a sequence of "value score value score...".
+		// First group the values by score.
+		for i := 0; i < len(values); i = i + 2 {
+			score, err := strconv.ParseFloat(values[i+1], 64)
+			if err == nil && matcher.MatchScore(score) && matcher.MatchRegex(values[i]) && matcher.MatchRange(values[i]) {
+				scoredValues, exists := scoredValuesMap[score]
+				if exists {
+					scoredValues.Values = append(scoredValues.Values, values[i])
+				} else {
+					scoredValues = SortedSetValues{Score: score, Values: []string{values[i]}}
+				}
+				scoredValuesMap[score] = scoredValues
+			}
+		}
+
+		for _, value := range scoredValuesMap {
+			// Sort the values for each score.
+			sort.Strings(value.Values)
+			result = append(result, value)
+		}
+		// Sort the total results by score.
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].(SortedSetValues).Score < result[j].(SortedSetValues).Score
+		})
+		return
+	}
+}
+
+func (c *RedisClient) getListValues(ctx context.Context, entryPointValue datasource.EntryPoint, matcher *datasource.Matcher) (datasource.DataBatch, error) {
+	var result datasource.DataBatch
+	values, err := c.client.LRange(ctx, string(entryPointValue), 0, -1).Result()
+	if err == nil {
+		if len(values) > 0 {
+			sendableValues := listValuesOf(matcher, values)
+			result = datasource.DataBatch{
+				Size: uint64(len(sendableValues)),
+				Data: sendableValues,
+			}
+		}
+	}
+	return result, err
+}
+
+// listValuesOf returns the values of a list accepted by the matcher.
+func listValuesOf(matcher *datasource.Matcher, values []string) []interface{} {
+	var sendableValues []interface{}
+	for _, value := range values {
+		// There is no server-side matching for lists, the glob pattern has to be applied here.
+		if matcher.MatchPattern(value) && matcher.MatchRange(value) {
+			sendableValues = append(sendableValues, value)
+		}
+	}
+	return sendableValues
+}
+
+func (c *RedisClient) getFullHash(ctx context.Context, entryPointValue datasource.EntryPoint, matcher *datasource.Matcher) (datasource.DataBatch, error) {
+	return c.fullScan(ctx, matcher.Filter().Glob, func(cursor uint64, match string, count int64) *redis.ScanCmd {
+		return c.client.HScan(ctx, string(entryPointValue), cursor, match, count)
+	}, hashValuesAppender(matcher))
+}
+
+// hashValuesAppender returns a function appending the fields of a hash returned by HSCAN to the previous ones, sorted.
+func hashValuesAppender(matcher *datasource.Matcher) func([]interface{}, []string) []interface{} {
+	return func(allValues []interface{}, values []string) (result []interface{}) {
+		result = allValues
+		for i := 0; i < len(values); i = i + 2 {
+			// Patterns apply to the fields, ranges to the values.
+			if matcher.MatchRegex(values[i]) && matcher.MatchRange(values[i+1]) && matcher.MatchField(values[i], values[i+1]) {
+				result = append(result, HashValue{values[i], values[i+1]})
+			}
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].(HashValue).Key < result[j].(HashValue).Key
+		})
+		return
+	}
+}
+
+// GetContentPage reads one window of the content: SSCAN, ZSCAN and HSCAN continue from the cursor they returned,
+// whereas the cursor of lists is the index of the next value and the one of streams the ID of the next message.
+// The values of a page are sorted, but not the complete content.
+func (c *RedisClient) GetContentPage(ctx context.Context, entryPointValue datasource.EntryPoint, filter datasource.Filter, page datasource.Page) (datasource.ContentPage, error) {
+	var result datasource.ContentPage
+
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return result, err
+	}
+	count := page.Count
+	if count <= 0 {
+		count = scanSize
+	}
+
+	key := string(entryPointValue)
+	t, err := c.client.Type(ctx, key).Result()
+	if err != nil {
+		return result, err
+	}
+	var (
+		values []interface{}
+		next   string
+	)
+	switch strings.ToLower(t) {
+	case "string":
+		var value datasource.SingleValue
+		value, err = c.getValue(ctx, entryPointValue)
+		values = []interface{}{value}
+		next = datasource.FirstPage
+	case "set":
+		values, next, err = c.scanPage(page.Cursor, count, matcher, func(cursor uint64, match string, count int64) *redis.ScanCmd {
+			return c.client.SScan(ctx, key, cursor, match, count)
+		}, setValuesAppender(matcher))
+	case "zset":
+		values, next, err = c.scanPage(page.Cursor, count, matcher, func(cursor uint64, match string, count int64) *redis.ScanCmd {
+			return c.client.ZScan(ctx, key, cursor, match, count)
+		}, zsetValuesAppender(matcher))
+	case "hash":
+		values, next, err = c.scanPage(page.Cursor, count, matcher, func(cursor uint64, match string, count int64) *redis.ScanCmd {
+			return c.client.HScan(ctx, key, cursor, match, count)
+		}, hashValuesAppender(matcher))
+	case "list":
+		values, next, err = c.getListPage(ctx, key, page.Cursor, count, matcher)
+	case "stream":
+		values, next, err = c.getStreamPage(ctx, key, page.Cursor, count, matcher)
+	case "none":
+		err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+	default:
+		err = errors.New(fmt.Sprintf("Type %s is unsupported", t))
+	}
+	if err == nil {
+		result = datasource.ContentPage{Size: uint64(len(values)), Data: values, Cursor: next}
+	}
+	return result, err
+}
+
+// scanPage runs one iteration of SSCAN, ZSCAN or HSCAN from the cursor.
+func (c *RedisClient) scanPage(cursorValue string, count int64, matcher *datasource.Matcher, scanFn func(cursor uint64, match string, count int64) *redis.ScanCmd, appendFn func([]interface{}, []string) []interface{}) ([]interface{}, string, error) {
+	cursor, err := parsePageCursor(cursorValue)
+	if err != nil {
+		return nil, "", err
+	}
+	values, next, err := scanFn(cursor, matcher.Filter().Glob, count).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	return appendFn(nil, values), strconv.FormatUint(next, 10), nil
+}
+
+// getListPage reads the window of count values of a list, starting at the index given by the cursor.
+func (c *RedisClient) getListPage(ctx context.Context, key string, cursorValue string, count int64, matcher *datasource.Matcher) ([]interface{}, string, error) {
+	start, err := parsePageCursor(cursorValue)
+	if err != nil {
+		return nil, "", err
+	}
+	values, err := c.client.LRange(ctx, key, int64(start), int64(start)+count-1).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	next := datasource.FirstPage
+	if int64(len(values)) == count {
+		// The next window might be empty, when the length of the list is a multiple of the count.
+		next = strconv.FormatInt(int64(start)+count, 10)
+	}
+	return listValuesOf(matcher, values), next, nil
+}
+
+// getStreamPage reads count messages of a stream, starting at the ID given by the cursor. One more message
+// is read to know the ID of the next page.
+func (c *RedisClient) getStreamPage(ctx context.Context, key string, cursorValue string, count int64, matcher *datasource.Matcher) ([]interface{}, string, error) {
+	start := cursorValue
+	if start == "" || start == datasource.FirstPage {
+		start = "-"
+	}
+	messages, err := c.client.XRangeN(ctx, key, start, "+", count+1).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	next := datasource.FirstPage
+	if int64(len(messages)) > count {
+		next = messages[count].ID
+		messages = messages[:count]
+	}
+	var values []interface{}
+	for _, message := range messages {
+		if matcher.MatchFields(message.Values) {
+			values = append(values, toStreamMessage(message))
+		}
+	}
+	return values, next, nil
+}
+
+// parsePageCursor converts the cursor of a page into the numeric cursor of SCAN or index of LRANGE.
+func parsePageCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("the cursor '%s' is not valid", cursor))
+	}
+	return value, nil
+}
+
+func (c *RedisClient) GetStreamInfos(ctx context.Context, entryPointValue datasource.EntryPoint, pendingEntriesCount int64) (datasource.StreamInfos, error) {
+	key := string(entryPointValue)
+	keyType, err := c.client.Type(ctx, key).Result()
+	if err == nil {
+		t := strings.ToLower(keyType)
+		switch t {
+		case "stream":
+			return c.getStreamInfos(ctx, key, pendingEntriesCount)
+		case "none":
+			err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+		default:
+			err = errors.New(fmt.Sprintf("Entrypoint %s is not a stream but a %s", entryPointValue, t))
+		}
+	}
+	return datasource.StreamInfos{}, err
+}
+
+func (c *RedisClient) getStreamInfos(ctx context.Context, key string, pendingEntriesCount int64) (datasource.StreamInfos, error) {
+	var result datasource.StreamInfos
+
+	stream, err := c.client.XInfoStream(ctx, key).Result()
+	if err != nil {
+		return result, err
+	}
+	result = datasource.StreamInfos{
+		Length:          uint64(stream.Length),
+		FirstEntryId:    stream.FirstEntry.ID,
+		LastEntryId:     stream.LastEntry.ID,
+		LastGeneratedId: stream.LastGeneratedID,
+		Groups:          []datasource.ConsumerGroup{},
+	}
+	groups, err := c.client.XInfoGroups(ctx, key).Result()
+	if err != nil {
+		return result, err
+	}
+	for _, group := range groups {
+		consumerGroup := datasource.ConsumerGroup{
+			Name:            group.Name,
+			LastDeliveredId: group.LastDeliveredID,
+			Lag:             group.Lag,
+			Pending:         uint64(group.Pending),
+			Consumers:       []datasource.StreamConsumer{},
+		}
+		// The lag is only provided from Redis 7.
+		if group.LastDeliveredID == stream.LastGeneratedID {
+			consumerGroup.Lag = 0
+		} else if group.Lag == 0 {
+			consumerGroup.Lag = -1
+		}
+
+		// XINFO CONSUMERS is read natively, because the fields of its reply vary with the version of Redis.
+		consumersCmd := redis.NewSliceCmd(ctx, "xinfo", "consumers", key, group.Name)
+		consumersCmd.SetFirstKeyPos(2)
+		c.processCmd(ctx, consumersCmd, "")
+		consumers, err := consumersCmd.Result()
+		if err != nil {
+			return result, err
+		}
+		for _, consumer := range consumers {
+			fields := toStringMap(consumer)
+			name, _ := fields["name"].(string)
+			pending, _ := fields["pending"].(int64)
+			idle, _ := fields["idle"].(int64)
+			consumerGroup.Consumers = append(consumerGroup.Consumers, datasource.StreamConsumer{
+				Name:    name,
+				Pending: uint64(pending),
+				Idle:    idle,
+			})
+		}
+
+		if pendingEntriesCount > 0 && group.Pending > 0 {
+			pendingEntries, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+				Stream: key,
+				Group:  group.Name,
+				Start:  "-",
+				End:    "+",
+				Count:  pendingEntriesCount,
+			}).Result()
+			if err != nil {
+				return result, err
+			}
+			for _, pendingEntry := range pendingEntries {
+				consumerGroup.PendingEntries = append(consumerGroup.PendingEntries, datasource.PendingEntry{
+					Id:            pendingEntry.ID,
+					Consumer:      pendingEntry.Consumer,
+					Idle:          int64(pendingEntry.Idle / time.Millisecond),
+					DeliveryCount: pendingEntry.RetryCount,
+				})
+			}
+		}
+		result.Groups = append(result.Groups, consumerGroup)
+	}
+	return result, nil
+}
+
+// toStringMap converts a map reply, returned as a map with RESP3 or as a flat list of keys and values with RESP2.
+func toStringMap(reply interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	switch v := reply.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			result[fmt.Sprint(key)] = value
+		}
+	case []interface{}:
+		for i := 0; i+1 < len(v); i = i + 2 {
+			result[fmt.Sprint(v[i])] = v[i+1]
+		}
+	}
+	return result
+}
+
+func (c *RedisClient) GetStreamRange(ctx context.Context, entryPointValue datasource.EntryPoint, streamRange datasource.StreamRange, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var (
+		err    error
+		result datasource.DataBatch
+	)
+
+	statusCmd := c.client.Type(ctx, string(entryPointValue))
+	err = statusCmd.Err()
+	if err == nil {
+		t := strings.ToLower(statusCmd.Val())
+		switch t {
+		case "stream":
+			result, err = c.getStream(ctx, entryPointValue, streamRange, nil)
+		case "none":
+			err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+		default:
+			err = errors.New(fmt.Sprintf("Entrypoint %s is not a stream but a %s", entryPointValue, t))
+		}
+	}
+	if err == nil {
+		contentChannel <- result
+	}
 
-func (c *RedisClient) getSetValues(entryPointValue datasource.EntryPoint, filter string) (datasource.DataBatch, error) {
-	return c.fullScan(filter, func(cursor uint64, match string, count int64) *redis.ScanCmd {
-		return c.client.SScan(context.Background(), string(entryPointValue), cursor, match, count)
-	}, func(allValues []interface{}, values []string) (result []interface{}) {
-		result = allValues
-		for _, v := range values {
-			result = append(result, v)
+	return datasource.Completed, err
+}
+
+func (c *RedisClient) GetSortedSetRange(ctx context.Context, entryPointValue datasource.EntryPoint, sortedSetRange datasource.SortedSetRange, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var (
+		err    error
+		result datasource.DataBatch
+	)
+
+	statusCmd := c.client.Type(ctx, string(entryPointValue))
+	err = statusCmd.Err()
+	if err == nil {
+		t := strings.ToLower(statusCmd.Val())
+		switch t {
+		case "zset":
+			result, err = c.getZSetRange(ctx, string(entryPointValue), sortedSetRange)
+		case "none":
+			err = errors.New(fmt.Sprintf("Entrypoint %s was not found", entryPointValue))
+		default:
+			err = errors.New(fmt.Sprintf("Entrypoint %s is not a sorted set but a %s", entryPointValue, t))
 		}
-		// Sort the values.
-		sort.Slice(result, func(i, j int) bool {
-			return result[i].(string) < result[j].(string)
-		})
-		return
-	})
+	}
+	if err == nil {
+		contentChannel <- result
+	}
+
+	return datasource.Completed, err
 }
 
-func (c *RedisClient) getZSetValues(entryPointValue datasource.EntryPoint, filter string) (datasource.DataBatch, error) {
-	return c.fullScan(filter, func(cursor uint64, match string, count int64) *redis.ScanCmd {
-		return c.client.ZScan(context.Background(), string(entryPointValue), cursor, match, count)
-	}, func(allValues []interface{}, values []string) (result []interface{}) {
-		scoredValuesMap := make(map[float64]SortedSetValues)
+// getZSetRange runs ZRANGE, ZRANGEBYSCORE or ZRANGEBYLEX, or their reverse versions, which are supported by all
+// the versions of Redis.
+func (c *RedisClient) getZSetRange(ctx context.Context, key string, sortedSetRange datasource.SortedSetRange) (datasource.DataBatch, error) {
+	var (
+		members []redis.Z
+		err     error
+	)
+	count := sortedSetRange.Count
+	if count <= 0 {
+		count = scanSize
+	}
 
-		var previousValue SortedSetValues
-		for _, val := range allValues {
-			previousValue = val.(SortedSetValues)
-			scoredValuesMap[previousValue.Score] = previousValue
+	switch sortedSetRange.By {
+	case datasource.ByRank, "":
+		var start, stop int64
+		if start, stop, err = rankWindow(sortedSetRange, count); err != nil {
+			return datasource.DataBatch{}, err
+		}
+		if sortedSetRange.Reverse {
+			members, err = c.client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+		} else {
+			members, err = c.client.ZRangeWithScores(ctx, key, start, stop).Result()
+		}
+	case datasource.ByScore:
+		rangeBy := &redis.ZRangeBy{Min: withDefault(sortedSetRange.Start, "-inf"), Max: withDefault(sortedSetRange.Stop, "+inf"), Offset: sortedSetRange.Offset, Count: count}
+		if sortedSetRange.Reverse {
+			members, err = c.client.ZRevRangeByScoreWithScores(ctx, key, rangeBy).Result()
+		} else {
+			members, err = c.client.ZRangeByScoreWithScores(ctx, key, rangeBy).Result()
 		}
+	case datasource.ByLex:
+		members, err = c.getZSetRangeByLex(ctx, key, sortedSetRange, count)
+	default:
+		err = errors.New(fmt.Sprintf("the kind of range '%s' is not valid", sortedSetRange.By))
+	}
+	if err != nil {
+		return datasource.DataBatch{}, err
+	}
 
-		// Result slice contains a sequence of "value score value score...".
-		// First group the values by score.
-		for i := 0; i < len(values); i = i + 2 {
-			score, err := strconv.ParseFloat(values[i+1], 64)
-			if err == nil {
-				scoredValues, exists := scoredValuesMap[score]
-				if exists {
-					scoredValues.Values = append(scoredValues.Values, values[i])
-				} else {
-					scoredValues = SortedSetValues{Score: score, Values: []string{values[i]}}
-				}
-				scoredValuesMap[score] = scoredValues
-			}
+	// The consecutive members with the same score are grouped, keeping the order of the server.
+	var result datasource.DataBatch
+	for _, member := range members {
+		value := fmt.Sprint(member.Member)
+		if last := len(result.Data) - 1; last >= 0 && result.Data[last].(SortedSetValues).Score == member.Score {
+			previous := result.Data[last].(SortedSetValues)
+			previous.Values = append(previous.Values, value)
+			result.Data[last] = previous
+		} else {
+			result.Data = append(result.Data, SortedSetValues{Score: member.Score, Values: []string{value}})
 		}
+	}
+	result.Size = uint64(len(result.Data))
+	return result, nil
+}
 
-		for _, value := range scoredValuesMap {
-			// Sort the values for each score.
-			sort.Strings(value.Values)
-			result = append(result, value)
+// getZSetRangeByLex runs ZRANGEBYLEX, which does not provide the scores: they are read apart in a pipeline.
+func (c *RedisClient) getZSetRangeByLex(ctx context.Context, key string, sortedSetRange datasource.SortedSetRange, count int64) ([]redis.Z, error) {
+	var (
+		values []string
+		err    error
+	)
+	rangeBy := &redis.ZRangeBy{Min: withDefault(sortedSetRange.Start, "-"), Max: withDefault(sortedSetRange.Stop, "+"), Offset: sortedSetRange.Offset, Count: count}
+	if sortedSetRange.Reverse {
+		values, err = c.client.ZRevRangeByLex(ctx, key, rangeBy).Result()
+	} else {
+		values, err = c.client.ZRangeByLex(ctx, key, rangeBy).Result()
+	}
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	pipeline := c.client.Pipeline()
+	scoreCmds := make([]*redis.FloatCmd, len(values))
+	for i, value := range values {
+		scoreCmds[i] = pipeline.ZScore(ctx, key, value)
+	}
+	if _, err = pipeline.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	var members []redis.Z
+	for i, value := range values {
+		// The members removed meanwhile are ignored.
+		if score, err := scoreCmds[i].Result(); err == nil {
+			members = append(members, redis.Z{Score: score, Member: value})
 		}
-		// Sort the total results by score.
-		sort.Slice(result, func(i, j int) bool {
-			return result[i].(SortedSetValues).Score < result[j].(SortedSetValues).Score
-		})
-		return
-	})
+	}
+	return members, nil
 }
 
-func (c *RedisClient) getListValues(entryPointValue datasource.EntryPoint, filter string) (datasource.DataBatch, error) {
-	var result datasource.DataBatch
-	values, err := c.client.LRange(context.Background(), string(entryPointValue), 0, -1).Result()
+// rankWindow returns the start and stop indexes of a range of ranks, the window of count members by default.
+func rankWindow(sortedSetRange datasource.SortedSetRange, count int64) (int64, int64, error) {
+	var (
+		start int64
+		stop  int64
+		err   error
+	)
+	if sortedSetRange.Start != "" {
+		if start, err = strconv.ParseInt(sortedSetRange.Start, 10, 64); err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("the rank '%s' is not valid", sortedSetRange.Start))
+		}
+	}
+	if sortedSetRange.Stop != "" {
+		if stop, err = strconv.ParseInt(sortedSetRange.Stop, 10, 64); err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("the rank '%s' is not valid", sortedSetRange.Stop))
+		}
+	} else if start >= 0 {
+		stop = start + count - 1
+	} else {
+		stop = -1
+	}
+	return start, stop, nil
+}
+
+func withDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func (c *RedisClient) getStream(ctx context.Context, entryPointValue datasource.EntryPoint, streamRange datasource.StreamRange, matcher *datasource.Matcher) (datasource.DataBatch, error) {
+	var (
+		result   datasource.DataBatch
+		messages []redis.XMessage
+		err      error
+	)
+
+	start := streamRange.Start
+	if start == "" {
+		start = "-"
+	}
+	end := streamRange.End
+	if end == "" {
+		end = "+"
+	}
+	count := streamRange.Count
+	if count <= 0 {
+		count = scanSize
+	}
+
+	if streamRange.Reverse {
+		// XREVRANGE expects the upper bound first.
+		messages, err = c.client.XRevRangeN(ctx, string(entryPointValue), end, start, count).Result()
+	} else {
+		messages, err = c.client.XRangeN(ctx, string(entryPointValue), start, end, count).Result()
+	}
 	if err == nil {
-		if len(values) > 0 {
-			var regexp *regexp2.Regexp
-			if filter != "" {
-				regexp = regexp2.MustCompile(strings.ReplaceAll(filter, "*", ".*"))
-			}
-			var sendableValues []interface{}
-			for _, value := range values {
-				if regexp == nil || regexp.Match([]byte(value)) {
-					sendableValues = append(sendableValues, value)
-				}
-			}
-			result = datasource.DataBatch{
-				Size: uint64(len(sendableValues)),
-				Data: sendableValues,
+		for _, message := range messages {
+			if matcher == nil || matcher.MatchFields(message.Values) {
+				result.Data = append(result.Data, toStreamMessage(message))
 			}
 		}
+		result.Size = uint64(len(result.Data))
 	}
 	return result, err
 }
 
-func (c *RedisClient) getFullHash(entryPointValue datasource.EntryPoint, filter string) (datasource.DataBatch, error) {
-	return c.fullScan(filter, func(cursor uint64, match string, count int64) *redis.ScanCmd {
-		return c.client.HScan(context.Background(), string(entryPointValue), cursor, match, count)
-	}, func(allValues []interface{}, values []string) (result []interface{}) {
-		result = allValues
-		for i := 0; i < len(values); i = i + 2 {
-			result = append(result, HashValue{values[i], values[i+1]})
+// sendStreamContent sends the messages of the stream by pages of XRANGE, each page starting right after the last
+// message of the previous one, so that the whole stream is never held in memory. The stream fitting in a single page
+// is sent at once, the next pages of a larger one are read in the background.
+func (c *RedisClient) sendStreamContent(ctx context.Context, entryPointValue datasource.EntryPoint, matcher *datasource.Matcher, contentChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	key := string(entryPointValue)
+	values, lastId, err := c.getStreamContentPage(ctx, key, "-", matcher)
+	if err != nil {
+		return datasource.Completed, err
+	}
+	if lastId == "" {
+		contentChannel <- datasource.DataBatch{Size: uint64(len(values)), Data: values}
+		return datasource.Completed, nil
+	}
+
+	c.sendValuesToChannel(ctx, values, contentChannel)
+	go func() {
+		defer close(contentChannel)
+		for lastId != "" && ctx.Err() == nil {
+			values, lastId, err = c.getStreamContentPage(ctx, key, nextStreamId(lastId), matcher)
+			if err != nil {
+				log.Printf("ERROR while reading the stream %s: %s\n", key, err.Error())
+				c.sendErrorToChannel(ctx, err, contentChannel)
+				return
+			}
+			if len(values) > 0 {
+				c.sendValuesToChannel(ctx, values, contentChannel)
+			}
 		}
-		sort.Slice(result, func(i, j int) bool {
-			return result[i].(HashValue).Key < result[j].(HashValue).Key
-		})
-		return
-	})
+		if lastId != "" {
+			// The content was cut short by the timeout or a cancellation, the reader is told it is incomplete.
+			c.sendErrorToChannel(ctx, ctx.Err(), contentChannel)
+		}
+	}()
+	return datasource.Moved, nil
+}
+
+// getStreamContentPage returns the matching messages of a page of the stream starting at the ID, and the ID of the last
+// message of the page when the page is full, empty when there are no more messages.
+func (c *RedisClient) getStreamContentPage(ctx context.Context, key string, start string, matcher *datasource.Matcher) ([]interface{}, string, error) {
+	messages, err := c.client.XRangeN(ctx, key, start, "+", scanSize).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	values := []interface{}{}
+	for _, message := range messages {
+		if matcher.MatchFields(message.Values) {
+			values = append(values, toStreamMessage(message))
+		}
+	}
+	lastId := ""
+	if int64(len(messages)) == scanSize {
+		lastId = messages[len(messages)-1].ID
+	}
+	return values, lastId, nil
 }
 
-func (c *RedisClient) getStream(entryPointValue datasource.EntryPoint, filter string, target chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
-	messages, err := c.client.XRange(context.Background(), string(entryPointValue), "-", "+").Result()
+// nextStreamId returns the smallest ID following the ID of a message, as the exclusive start of XRANGE,
+// since the prefix ( is only supported from Redis 6.2.
+func nextStreamId(id string) string {
+	parts := strings.SplitN(id, "-", 2)
+	millis, _ := strconv.ParseUint(parts[0], 10, 64)
+	sequence := uint64(0)
+	if len(parts) == 2 {
+		sequence, _ = strconv.ParseUint(parts[1], 10, 64)
+	}
+	if sequence == math.MaxUint64 {
+		return fmt.Sprintf("%d-0", millis+1)
+	}
+	return fmt.Sprintf("%d-%d", millis, sequence+1)
+}
+
+// toStreamMessage converts a message read from a stream, extracting its timestamp from the milliseconds part of its ID.
+func toStreamMessage(message redis.XMessage) StreamMessage {
+	streamMessage := StreamMessage{
+		Id:     message.ID,
+		Fields: message.Values,
+	}
+	millis, err := strconv.ParseInt(strings.SplitN(message.ID, "-", 2)[0], 10, 64)
 	if err == nil {
+		streamMessage.Timestamp = time.Unix(0, millis*int64(time.Millisecond))
+	}
+	return streamMessage
+}
+
+func (c *RedisClient) Consume(ctx context.Context, entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch, filter datasource.Filter, fromBeginning bool) (datasource.ActionStatus, error) {
+	var actionStatus datasource.ActionStatus
+
+	key := string(entryPointValue)
+	keyType, err := c.client.Type(ctx, key).Result()
+	if err != nil {
+		return actionStatus, err
+	}
+	// A stream that does not exist yet can be consumed, it will be created by the first producer.
+	t := strings.ToLower(keyType)
+	if t != "stream" && t != "none" {
+		return actionStatus, errors.New(fmt.Sprintf("Entrypoint %s is not a stream but a %s", entryPointValue, t))
+	}
+
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return actionStatus, err
+	}
+
+	lastId := "0-0"
+	if !fromBeginning {
+		// Equivalent of $, but resolved once: using $ in each of the successive blocking reads would skip
+		// the messages added between two of them.
+		messages, err := c.client.XRevRangeN(ctx, key, "+", "-", 1).Result()
+		if err != nil {
+			return actionStatus, err
+		}
 		if len(messages) > 0 {
-			dataBatch := datasource.DataBatch{
-				Size: uint64(len(target)),
+			lastId = messages[0].ID
+		}
+	}
+
+	go func() {
+		defer close(target)
+
+		// The blocking time is kept short, in order to release the connection as soon as the consumption is cancelled.
+		for ctx.Err() == nil {
+			streams, err := c.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{key, lastId},
+				Count:   scanSize,
+				Block:   consumeBlockingTime,
+			}).Result()
+			if err == redis.Nil {
+				// No new message arrived in the blocking time.
+				continue
+			} else if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("ERROR while consuming %s: %s\n", key, err.Error())
+					c.sendErrorToChannel(ctx, err, target)
+				}
+				break
+			}
+
+			var values []interface{}
+			for _, stream := range streams {
+				for _, message := range stream.Messages {
+					if matcher.MatchFields(message.Values) {
+						values = append(values, toStreamMessage(message))
+					}
+					lastId = message.ID
+				}
 			}
-			for _, message := range messages {
-				dataBatch.Data = append(dataBatch.Data, message)
+			if len(values) > 0 {
+				select {
+				case target <- datasource.DataBatch{Size: uint64(len(values)), Data: values}:
+				case <-ctx.Done():
+				}
 			}
-			target <- dataBatch
 		}
-		// End of the stream.
-		target <- datasource.DataBatch{
-			Size: 0,
+		log.Printf("Stop consuming %s\n", key)
+	}()
+	return datasource.Moved, nil
+}
+
+func (c *RedisClient) ListChannels(ctx context.Context, filter datasource.Filter, channelsChannel chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var (
+		err          error
+		actionStatus datasource.ActionStatus
+	)
+
+	matcher, err := datasource.NewMatcher(filter)
+	if err != nil {
+		return actionStatus, err
+	}
+	pattern := filter.Glob
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	subscribers := make(map[string]int64)
+	mutex := sync.Mutex{}
+	collectChannels := func(ctx context.Context, node redis.Cmdable) error {
+		channels, err := node.PubSubChannels(ctx, pattern).Result()
+		if err != nil || len(channels) == 0 {
+			return err
+		}
+		counts, err := node.PubSubNumSub(ctx, channels...).Result()
+		if err == nil {
+			mutex.Lock()
+			for channel, count := range counts {
+				subscribers[channel] = subscribers[channel] + count
+			}
+			mutex.Unlock()
 		}
-		return datasource.Completed, nil
+		return err
+	}
+
+	switch client := c.client.(type) {
+	case *redis.ClusterClient:
+		// The subscribers of a channel can be connected to any node, replicas included.
+		err = client.ForEachShard(ctx, func(ctx context.Context, node *redis.Client) error {
+			return collectChannels(ctx, node)
+		})
+	default:
+		err = collectChannels(ctx, c.client)
+	}
+	if err != nil {
+		return actionStatus, err
+	}
+
+	var orderedChannels []string
+	for channel := range subscribers {
+		if matcher.MatchRegex(channel) {
+			orderedChannels = append(orderedChannels, channel)
+		}
+	}
+	sort.Strings(orderedChannels)
+	var channels []interface{}
+	for _, channel := range orderedChannels {
+		channels = append(channels, datasource.ChannelNode{
+			Path:        datasource.EntryPoint(channel),
+			Type:        datasource.EntryPointTypesAsString[datasource.Channel],
+			Subscribers: uint64(subscribers[channel]),
+		})
 	}
-	return datasource.None, err
+	channelsChannel <- datasource.DataBatch{
+		Size: uint64(len(channels)),
+		Data: channels,
+	}
+	return datasource.Completed, nil
 }
 
-func (c *RedisClient) Consume(entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch, filter datasource.Filter, fromBeginning bool) (datasource.ActionStatus, error) {
+func (c *RedisClient) Subscribe(ctx context.Context, channel datasource.EntryPoint, pattern bool, target chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	client, ok := c.client.(subscriber)
+	if !ok {
+		return datasource.None, errors.New("the data source does not support publish/subscribe")
+	}
+
+	var pubSub *redis.PubSub
+	if pattern {
+		pubSub = client.PSubscribe(ctx, string(channel))
+	} else {
+		pubSub = client.Subscribe(ctx, string(channel))
+	}
+	// Waits for the confirmation of the subscription.
+	_, err := pubSub.Receive(ctx)
+	if err != nil {
+		pubSub.Close()
+		return datasource.None, err
+	}
+
+	go func() {
+		defer close(target)
+		defer pubSub.Close()
+
+		messages := pubSub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("Stop subscribing to %s\n", channel)
+				return
+			case message, open := <-messages:
+				if !open {
+					return
+				}
+				channelMessage := ChannelMessage{
+					Channel:   message.Channel,
+					Pattern:   message.Pattern,
+					Payload:   message.Payload,
+					Timestamp: time.Now(),
+				}
+				select {
+				case target <- datasource.DataBatch{Size: 1, Data: []interface{}{channelMessage}}:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+	return datasource.Moved, nil
+}
 
-	panic("Implement me!")
+func (c *RedisClient) Watch(ctx context.Context, entryPointValue datasource.EntryPoint, target chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+	var (
+		err      error
+		pubSubs  []*redis.PubSub
+		patterns []string
+	)
+	if entryPointValue == "" {
+		patterns = []string{keyspaceChannelPrefix + "*"}
+	} else {
+		escapedEntryPoint := escapeGlob(string(entryPointValue))
+		patterns = []string{keyspaceChannelPrefix + escapedEntryPoint, keyspaceChannelPrefix + escapedEntryPoint + pathSeparatorAsString + "*"}
+	}
 
-	/*
-		streamCmd := c.datasource.XReadStreams(string(entryPointValue))
-		c.datasource.XRead()
-		var status ActionStatus
-		err := streamCmd.Err()
-		if err == nil {
-			go func() {
-				for c.activeStreams {
-					// TODO
+	// The keyspace notifications are only published by the node owning the key, each master has to be subscribed.
+	mutex := sync.Mutex{}
+	subscribeNode := func(ctx context.Context, node *redis.Client) error {
+		err := checkKeyspaceNotifications(ctx, node)
+		if err != nil {
+			return err
+		}
+		pubSub := node.PSubscribe(ctx, patterns...)
+		mutex.Lock()
+		pubSubs = append(pubSubs, pubSub)
+		mutex.Unlock()
+		// Waits for the confirmation of the subscription.
+		_, err = pubSub.Receive(ctx)
+		return err
+	}
+	switch client := c.client.(type) {
+	case *redis.ClusterClient:
+		err = client.ForEachMaster(ctx, subscribeNode)
+	case *redis.Client:
+		err = subscribeNode(ctx, client)
+	default:
+		err = errors.New("the data source does not support publish/subscribe")
+	}
+	if err != nil {
+		for _, pubSub := range pubSubs {
+			pubSub.Close()
+		}
+		return datasource.None, err
+	}
+
+	waitGroup := sync.WaitGroup{}
+	for _, pubSub := range pubSubs {
+		waitGroup.Add(1)
+		go func(pubSub *redis.PubSub) {
+			defer waitGroup.Done()
+			defer pubSub.Close()
+			watchKeyspace(ctx, pubSub, target)
+		}(pubSub)
+	}
+	go func() {
+		waitGroup.Wait()
+		log.Printf("Stop watching %s\n", entryPointValue)
+		close(target)
+	}()
+	return datasource.Moved, nil
+}
+
+// checkKeyspaceNotifications verifies that the node publishes the notifications of the keyspace.
+func checkKeyspaceNotifications(ctx context.Context, node redis.Cmdable) error {
+	config, err := node.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		// The command CONFIG might be disabled, the notifications are then hoped to be enabled.
+		log.Printf("WARN the configuration of the keyspace notifications cannot be verified: %s\n", err.Error())
+		return nil
+	}
+	flags := config["notify-keyspace-events"]
+	if !strings.Contains(flags, "K") || !strings.ContainsAny(flags, "Ag$lshzxetdm") {
+		return errors.New(fmt.Sprintf("the keyspace notifications are disabled on the server (notify-keyspace-events is '%s'), set it for instance to 'KA' to watch the keys", flags))
+	}
+	return nil
+}
+
+// watchKeyspace converts the keyspace notifications received by the subscription into KeyspaceEvent, until the context is cancelled.
+func watchKeyspace(ctx context.Context, pubSub *redis.PubSub, target chan<- datasource.DataBatch) {
+	// Keys for which the event "new" was received, the creation is completed by the next event of the key.
+	createdKeys := make(map[string]bool)
+	messages := pubSub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, open := <-messages:
+			if !open {
+				return
+			}
+			key := message.Channel[strings.Index(message.Channel, "__:")+3:]
+			command := message.Payload
+			if command == "new" {
+				createdKeys[key] = true
+				continue
+			}
+			event := keyspaceEventKind(command)
+			if createdKeys[key] {
+				delete(createdKeys, key)
+				if event == KeyUpdated {
+					event = KeyCreated
 				}
-			}()
+			}
+			keyspaceEvent := KeyspaceEvent{
+				Key:       key,
+				Event:     event,
+				Command:   command,
+				Timestamp: time.Now(),
+			}
+			select {
+			case target <- datasource.DataBatch{Size: 1, Data: []interface{}{keyspaceEvent}}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// keyspaceEventKind returns the kind of event for the command of a keyspace notification.
+// Without the event "new" enabled on the server, the creations of keys are seen as updates.
+func keyspaceEventKind(command string) string {
+	switch command {
+	case "del", "unlink", "evicted", "rename_from", "move_from":
+		return KeyDeleted
+	case "expired":
+		return KeyExpired
+	case "rename_to", "move_to", "copy_to", "restore":
+		return KeyCreated
+	default:
+		return KeyUpdated
+	}
+}
+
+// escapeGlob escapes the special characters of the glob-style patterns.
+func escapeGlob(value string) string {
+	var builder strings.Builder
+	for _, char := range value {
+		if strings.ContainsRune("*?[]\\", char) {
+			builder.WriteRune('\\')
 		}
-		return status, err
-	*/
+		builder.WriteRune(char)
+	}
+	return builder.String()
 }
 
-func (c *RedisClient) ExecuteCommand(args []interface{}, nodeID string) (interface{}, error) {
+func (c *RedisClient) ExecuteCommand(ctx context.Context, args []interface{}, nodeID string) (interface{}, error) {
 	if len(args) > 0 && c.datasource.ReadOnly && !c.isClusterReadonlyCommand(args) {
 		cmd, ok := args[0].(string)
 		if ok {
@@ -1219,8 +3110,8 @@ func (c *RedisClient) ExecuteCommand(args []interface{}, nodeID string) (interfa
 		}
 	}
 
-	cmd := redis.NewCmd(context.Background(), args...)
-	c.processCmd(cmd, nodeID)
+	cmd := redis.NewCmd(ctx, args...)
+	c.processCmd(ctx, cmd, nodeID)
 	return cmd.Result()
 }
 
@@ -1246,15 +3137,15 @@ func (c *RedisClient) isReadOnlyCommand(cmd string) bool {
 	return false
 }
 
-func (c *RedisClient) processCmd(cmd redis.Cmder, nodeID string) {
+func (c *RedisClient) processCmd(ctx context.Context, cmd redis.Cmder, nodeID string) {
 	switch v := c.client.(type) {
 	case *redis.Client:
-		v.Process(context.Background(), cmd)
+		v.Process(ctx, cmd)
 	case *redis.ClusterClient:
 		if nodeID == "" {
-			v.Process(context.Background(), cmd)
+			v.Process(ctx, cmd)
 		} else {
-			v.ForEachShard(context.Background(), func(ctx context.Context, client *redis.Client) error {
+			v.ForEachShard(ctx, func(ctx context.Context, client *redis.Client) error {
 				myIDResult, err := client.Do(ctx, "cluster", "myid").Result()
 				if err == nil {
 					myID := (myIDResult).(string)
@@ -1,7 +1,11 @@
 package redis
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
@@ -9,9 +13,12 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"lagoon/datasource"
+	"lagoon/datasource/conformance"
+	"lagoon/datasource/decoding"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,7 +77,7 @@ func TestRedisClient_OpenAndCloseWithPassword(t *testing.T) {
 	client.client.ConfigSet(context.Background(), "requirepass", "this-is-my-password")
 
 	// when
-	_, err = client.ExecuteCommand([]interface{}{"info"}, "")
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"info"}, "")
 	assert.NotNil(t, err)
 
 	clientWithPassword := RedisClient{
@@ -88,12 +95,12 @@ func TestRedisClient_OpenAndCloseWithPassword(t *testing.T) {
 	// then
 	err = clientWithPassword.Open()
 	assert.Nil(t, err)
-	result, err := client.ExecuteCommand([]interface{}{"info"}, "")
+	result, err := client.ExecuteCommand(context.Background(), []interface{}{"info"}, "")
 	// No action is possible any more without password.
 	assert.NotNil(t, err)
 
 	// then
-	result, err = clientWithPassword.ExecuteCommand([]interface{}{"info"}, "")
+	result, err = clientWithPassword.ExecuteCommand(context.Background(), []interface{}{"info"}, "")
 	// The action is possible with password.
 	assert.Nil(t, err)
 
@@ -114,7 +121,7 @@ func TestRedisClient_OpenAndClose(t *testing.T) {
 	// then
 	assert.Nil(t, err)
 
-	result, err := client.GetInfos()
+	result, err := client.GetInfos(context.Background())
 	assert.Nil(t, err)
 
 	t.Logf("Redis Info: %+v\n", result)
@@ -155,7 +162,7 @@ func TestRedisClient_ListAllEntryPoints(t *testing.T) {
 	dataChannel := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.ListEntryPoints("*", dataChannel, 0, 1)
+	actionStatus, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -169,7 +176,7 @@ func TestRedisClient_ListAllEntryPoints(t *testing.T) {
 
 	// List all the entry points of level 0 only.
 	dataChannel = make(chan datasource.DataBatch, 100)
-	actionStatus, err = client.ListEntryPoints("*", dataChannel, 0, 0)
+	actionStatus, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, dataChannel, 0, 0)
 	assert.Nil(t, err)
 	actualData = []interface{}{}
 	for batch := range dataChannel {
@@ -179,7 +186,7 @@ func TestRedisClient_ListAllEntryPoints(t *testing.T) {
 
 	// List all the entry points at once.
 	dataChannel = make(chan datasource.DataBatch, scanSize)
-	actionStatus, err = client.ListEntryPoints("*", dataChannel, 0, 1)
+	actionStatus, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, dataChannel, 0, 1)
 	assert.Nil(t, err)
 	assert.Equal(t, datasource.Moved, actionStatus)
 	actualData = []interface{}{}
@@ -211,7 +218,7 @@ func TestRedisClient_ListAllEntryPointsWhenThereAreMoreThanChannelAndScanSize(t
 	dataChannel := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.ListEntryPoints("*", dataChannel, 0, 1)
+	actionStatus, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -252,7 +259,7 @@ func TestRedisClient_ListEntryPointsWithKeyHashTags(t *testing.T) {
 	dataChannel := make(chan datasource.DataBatch, 10)
 
 	// when
-	actionStatus, err := client.ListEntryPoints("*", dataChannel, 0, 4)
+	actionStatus, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, dataChannel, 0, 4)
 
 	// then
 	assert.Nil(t, err)
@@ -282,6 +289,108 @@ func TestRedisClient_ListEntryPointsWithKeyHashTags(t *testing.T) {
 	EqualUnorderedSlices(t, keys, expectedResult)
 }
 
+func TestRedisClient_ListEntryPointsReportsTheProgress(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	for i := 0; i < 2500; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session:%d", i), "value", time.Minute)
+	}
+	dataChannel := make(chan datasource.DataBatch, 100)
+
+	// when
+	actionStatus, err := client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "*"}, dataChannel, 0, 0)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	var progress []datasource.ScanProgress
+	var data []interface{}
+	for batch := range dataChannel {
+		assert.Nil(t, batch.Error)
+		if batch.Progress != nil {
+			progress = append(progress, batch.Progress.(datasource.ScanProgress))
+		}
+		data = append(data, batch.Data...)
+	}
+	assert.Len(t, data, 1)
+	assert.True(t, len(progress) > 1)
+	// The progress is reported after each page of keys, then once the node is completely scanned.
+	assert.Equal(t, datasource.ScanProgress{ScannedKeys: 2500, ScannedNodes: 1, Nodes: 1}, progress[len(progress)-1])
+	assert.Equal(t, 0, progress[len(progress)-2].ScannedNodes)
+}
+
+func TestRedisClient_ListEntryPointsWhenCancelled(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	for i := 0; i < 5000; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session-%d", i), "value", time.Minute)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	dataChannel := make(chan datasource.DataBatch, 1)
+
+	// when
+	actionStatus, err := client.ListEntryPoints(ctx, datasource.Filter{Glob: "*"}, dataChannel, 0, 0)
+	firstBatch := <-dataChannel
+	for firstBatch.Progress != nil {
+		firstBatch = <-dataChannel
+	}
+	cancel()
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	assert.Equal(t, uint64(1000), firstBatch.Size)
+	// The listing stops sending the remaining batches, reports the cancellation and closes the channel.
+	batchesCount := 0
+	var lastBatch datasource.DataBatch
+	for batch := range dataChannel {
+		batchesCount++
+		lastBatch = batch
+	}
+	assert.True(t, batchesCount < 4)
+	assert.NotNil(t, lastBatch.Error)
+}
+
+func TestRedisClient_SendErrorToChannelWhenTheContextIsDone(t *testing.T) {
+	// given
+	client := RedisClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dataChannel := make(chan datasource.DataBatch)
+	received := make(chan datasource.DataBatch)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		received <- <-dataChannel
+	}()
+
+	// when
+	client.sendErrorToChannel(ctx, ctx.Err(), dataChannel)
+
+	// then
+	batch := <-received
+	assert.Equal(t, context.Canceled, batch.Error)
+}
+
 func TestRedisClient_ListEntryPointsWithOneFilter(t *testing.T) {
 	// given
 	testData := []struct {
@@ -316,7 +425,7 @@ func TestRedisClient_ListEntryPointsWithOneFilter(t *testing.T) {
 	dataChannel := make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-*", dataChannel, 0, 1)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-*"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -329,7 +438,7 @@ func TestRedisClient_ListEntryPointsWithOneFilter(t *testing.T) {
 	dataChannel = make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-at*", dataChannel, 0, 1)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-at*"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -342,7 +451,7 @@ func TestRedisClient_ListEntryPointsWithOneFilter(t *testing.T) {
 	dataChannel = make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-a*t*", dataChannel, 0, 1)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-a*t*"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -355,7 +464,7 @@ func TestRedisClient_ListEntryPointsWithOneFilter(t *testing.T) {
 	dataChannel = make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-a*t*", dataChannel, 0, 0)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-a*t*"}, dataChannel, 0, 0)
 
 	// then
 	assert.Nil(t, err)
@@ -400,7 +509,7 @@ func TestRedisClient_ListEntryPointsWithTwoFilters(t *testing.T) {
 	dataChannel := make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-*, *bolt*", dataChannel, 0, 1)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-*", Regex: "bolt"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -412,7 +521,7 @@ func TestRedisClient_ListEntryPointsWithTwoFilters(t *testing.T) {
 
 	dataChannel = make(chan datasource.DataBatch, 100)
 	// when
-	_, err = client.ListEntryPoints("group-*, *", dataChannel, 0, 1)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-*", Regex: ".*"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -425,7 +534,7 @@ func TestRedisClient_ListEntryPointsWithTwoFilters(t *testing.T) {
 	dataChannel = make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-*, *bolt*", dataChannel, 0, 1)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-*", Regex: "bolt"}, dataChannel, 0, 1)
 
 	// then
 	assert.Nil(t, err)
@@ -438,7 +547,7 @@ func TestRedisClient_ListEntryPointsWithTwoFilters(t *testing.T) {
 	dataChannel = make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.ListEntryPoints("group-*, *bolt*", dataChannel, 0, 0)
+	_, err = client.ListEntryPoints(context.Background(), datasource.Filter{Glob: "group-*", Regex: "bolt"}, dataChannel, 0, 0)
 
 	// then
 	assert.Nil(t, err)
@@ -466,7 +575,7 @@ func TestRedisClient_GetEntryPointInfosForValue(t *testing.T) {
 	client.client.Set(context.Background(), "my-string", "my-value", time.Minute)
 
 	// when
-	infos, err := client.GetEntryPointInfos("my-string")
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-string")
 
 	// then
 	assert.Nil(t, err)
@@ -481,7 +590,7 @@ func TestRedisClient_GetEntryPointInfosForValue(t *testing.T) {
 	client.client.Persist(context.Background(), "my-string")
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-string")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-string")
 
 	// then
 	assert.Nil(t, err)
@@ -492,7 +601,7 @@ func TestRedisClient_GetEntryPointInfosForValue(t *testing.T) {
 	client.client.Set(context.Background(), "my-integer", 12345, -1)
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-string")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-string")
 
 	// then
 	assert.Nil(t, err)
@@ -503,7 +612,7 @@ func TestRedisClient_GetEntryPointInfosForValue(t *testing.T) {
 	client.client.Set(context.Background(), "my-integer", true, -1)
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-string")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-string")
 
 	// then
 	assert.Nil(t, err)
@@ -540,7 +649,7 @@ func TestRedisClient_GetEntryPointInfosForHash(t *testing.T) {
 	client.client.Expire(context.Background(), "my-hash", time.Minute)
 
 	// when
-	infos, err := client.GetEntryPointInfos("my-hash")
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-hash")
 
 	// then
 	assert.Nil(t, err)
@@ -556,7 +665,7 @@ func TestRedisClient_GetEntryPointInfosForHash(t *testing.T) {
 	client.client.Persist(context.Background(), "my-hash")
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-hash")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-hash")
 
 	// then
 	assert.Nil(t, err)
@@ -588,7 +697,7 @@ func TestRedisClient_GetEntryPointInfosForSet(t *testing.T) {
 	client.client.Expire(context.Background(), "my-set", time.Minute)
 
 	// when
-	infos, err := client.GetEntryPointInfos("my-set")
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-set")
 
 	// then
 	assert.Nil(t, err)
@@ -604,7 +713,7 @@ func TestRedisClient_GetEntryPointInfosForSet(t *testing.T) {
 	client.client.Persist(context.Background(), "my-set")
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-set")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-set")
 
 	// then
 	assert.Nil(t, err)
@@ -636,7 +745,7 @@ func TestRedisClient_GetEntryPointInfosForList(t *testing.T) {
 	client.client.Expire(context.Background(), "my-list", time.Minute)
 
 	// when
-	infos, err := client.GetEntryPointInfos("my-list")
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-list")
 
 	// then
 	assert.Nil(t, err)
@@ -652,7 +761,7 @@ func TestRedisClient_GetEntryPointInfosForList(t *testing.T) {
 	client.client.Persist(context.Background(), "my-list")
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-list")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-list")
 
 	// then
 	assert.Nil(t, err)
@@ -706,7 +815,7 @@ func TestRedisClient_GetEntryPointInfosForOrderedSet(t *testing.T) {
 	client.client.Expire(context.Background(), "my-zset", time.Minute)
 
 	// when
-	infos, err := client.GetEntryPointInfos("my-zset")
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-zset")
 
 	// then
 	assert.Nil(t, err)
@@ -722,7 +831,7 @@ func TestRedisClient_GetEntryPointInfosForOrderedSet(t *testing.T) {
 	client.client.Persist(context.Background(), "my-zset")
 
 	// when
-	infos, err = client.GetEntryPointInfos("my-zset")
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-zset")
 
 	// then
 	assert.Nil(t, err)
@@ -731,6 +840,132 @@ func TestRedisClient_GetEntryPointInfosForOrderedSet(t *testing.T) {
 	assertWithTimeToLive(t, expected, infos)
 }
 
+func TestRedisClient_GetEntryPointInfosForStream(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	for i := 1; i <= 3; i++ {
+		client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: fmt.Sprintf("%d-0", i), Values: map[string]interface{}{"index": i}})
+	}
+	client.client.XGroupCreate(context.Background(), "my-stream", "my-group", "0")
+	// Two messages are delivered but not acknowledged.
+	client.client.XReadGroup(context.Background(), &redis.XReadGroupArgs{Group: "my-group", Consumer: "my-consumer", Streams: []string{"my-stream", ">"}, Count: 2})
+	client.client.Expire(context.Background(), "my-stream", time.Hour)
+
+	// when
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-stream")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Stream, infos.Type)
+	assert.Equal(t, uint64(3), infos.Length)
+	assert.True(t, infos.TimeToLive > 0 && infos.TimeToLive <= time.Hour)
+	assert.Equal(t, "1-0", infos.Stream.FirstEntryId)
+	assert.Equal(t, "3-0", infos.Stream.LastEntryId)
+	assert.Equal(t, 1, len(infos.Stream.Groups))
+	group := infos.Stream.Groups[0]
+	assert.Equal(t, "my-group", group.Name)
+	assert.Equal(t, "2-0", group.LastDeliveredId)
+	assert.Equal(t, uint64(2), group.Pending)
+	assert.Equal(t, 1, len(group.Consumers))
+	assert.Equal(t, "my-consumer", group.Consumers[0].Name)
+	assert.Equal(t, uint64(2), group.Consumers[0].Pending)
+	assert.Empty(t, group.PendingEntries)
+
+	// when
+	streamInfos, err := client.GetStreamInfos(context.Background(), "my-stream", 10)
+
+	// then
+	assert.Nil(t, err)
+	pendingEntries := streamInfos.Groups[0].PendingEntries
+	assert.Equal(t, 2, len(pendingEntries))
+	assert.Equal(t, "1-0", pendingEntries[0].Id)
+	assert.Equal(t, "my-consumer", pendingEntries[0].Consumer)
+	assert.Equal(t, int64(1), pendingEntries[0].DeliveryCount)
+	assert.Equal(t, "2-0", pendingEntries[1].Id)
+}
+
+func TestRedisClient_GetStreamInfosForOtherType(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.Set(context.Background(), "my-string", "my-value", -1)
+
+	// when
+	_, err = client.GetStreamInfos(context.Background(), "my-string", 10)
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestRedisClient_GetEntryPointInfosWithObject(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.Set(context.Background(), "my-string", "my-value", time.Minute)
+	client.client.SAdd(context.Background(), "my-set", 1, 2, 3)
+	// The idle time is measured in seconds by the server.
+	time.Sleep(2 * time.Second)
+
+	// when
+	infos, err := client.GetEntryPointInfos(context.Background(), "my-string")
+
+	// then
+	assert.Nil(t, err)
+	assert.NotNil(t, infos.Object)
+	assert.True(t, infos.Object.MemoryUsage > 0)
+	assert.Equal(t, "embstr", infos.Object.Encoding)
+	// The default eviction policy of the server tracks the idle time and not the frequency.
+	assert.NotNil(t, infos.Object.IdleTime)
+	assert.True(t, *infos.Object.IdleTime >= 1000, "The key should not be touched before its idle time is read")
+	assert.Nil(t, infos.Object.Frequency)
+	assert.Nil(t, infos.Location)
+
+	// when
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-string")
+
+	// then
+	assert.Nil(t, err)
+	assert.True(t, *infos.Object.IdleTime >= 1000, "The previous inspection should not touch the key")
+
+	// when
+	infos, err = client.GetEntryPointInfos(context.Background(), "my-set")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "intset", infos.Object.Encoding)
+}
+
 func TestRedisClient_GetEntryPointInfosForMissingKey(t *testing.T) {
 	// given
 	client := RedisClient{
@@ -746,7 +981,7 @@ func TestRedisClient_GetEntryPointInfosForMissingKey(t *testing.T) {
 	}()
 
 	// when
-	_, err = client.GetEntryPointInfos("unknown-key")
+	_, err = client.GetEntryPointInfos(context.Background(), "unknown-key")
 
 	// then
 	assert.NotNil(t, err, "An error is expected here, because the key does not exist")
@@ -770,7 +1005,7 @@ func TestRedisClient_GetContentForValue(t *testing.T) {
 	data := make(chan datasource.DataBatch, 1)
 
 	// when
-	actionStatus, err := client.GetContent("my-string", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-string", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -788,7 +1023,7 @@ func TestRedisClient_GetContentForValue(t *testing.T) {
 	data = make(chan datasource.DataBatch, 1)
 
 	// when
-	actionStatus, err = client.GetContent("my-integer", "", data)
+	actionStatus, err = client.GetContent(context.Background(), "my-integer", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -806,7 +1041,7 @@ func TestRedisClient_GetContentForValue(t *testing.T) {
 	data = make(chan datasource.DataBatch, 1)
 
 	// when
-	actionStatus, err = client.GetContent("my-boolean", "", data)
+	actionStatus, err = client.GetContent(context.Background(), "my-boolean", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -824,7 +1059,7 @@ func TestRedisClient_GetContentForValue(t *testing.T) {
 	data = make(chan datasource.DataBatch, 1)
 
 	// when
-	actionStatus, err = client.GetContent("my-float", "", data)
+	actionStatus, err = client.GetContent(context.Background(), "my-float", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -860,7 +1095,7 @@ func TestRedisClient_GetContentForHash(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-hash", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-hash", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -900,7 +1135,7 @@ func TestRedisClient_GetContentForHashBiggerThanScanSize(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-hash", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-hash", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -913,6 +1148,49 @@ func TestRedisClient_GetContentForHashBiggerThanScanSize(t *testing.T) {
 	assert.Equal(t, datasource.DataBatch{Size: uint64(scanSize + 100), Data: expectedValues}, result)
 }
 
+func TestRedisClient_GetContentPageForHashBiggerThanScanSize(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	var values = map[string]interface{}{}
+	for i := 0; i < int(scanSize)+100; i++ {
+		values["my-field-"+fmt.Sprintf("%10d", i)] = "my-value-" + fmt.Sprintf("%10d", i)
+	}
+	client.client.HMSet(context.Background(), "my-hash", values)
+
+	// when
+	fields := map[string]string{}
+	pages := 0
+	page := datasource.Page{Count: 200}
+	for {
+		content, err := client.GetContentPage(context.Background(), "my-hash", datasource.Filter{}, page)
+		assert.Nil(t, err)
+		pages++
+		for _, value := range content.Data {
+			fields[value.(HashValue).Key] = value.(HashValue).Value
+		}
+		if content.Cursor == datasource.FirstPage || pages > int(scanSize) {
+			break
+		}
+		page.Cursor = content.Cursor
+	}
+
+	// then
+	// The windows all together contain every field of the hash.
+	assert.Len(t, fields, int(scanSize)+100)
+	assert.Equal(t, "my-value-"+fmt.Sprintf("%10d", 123), fields["my-field-"+fmt.Sprintf("%10d", 123)])
+}
+
 func TestRedisClient_GetContentForSet(t *testing.T) {
 	// given
 	client := RedisClient{
@@ -937,7 +1215,7 @@ func TestRedisClient_GetContentForSet(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-set", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-set", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -975,7 +1253,7 @@ func TestRedisClient_GetContentForSetBiggerThanScanSize(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-set", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-set", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -1013,7 +1291,7 @@ func TestRedisClient_GetContentForList(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-list", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-list", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -1051,13 +1329,53 @@ func TestRedisClient_GetContentForListBiggerThanScanSize(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-list", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-list", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
 	result := <-data
 	// Values are expected in saved order.
-	assert.Equal(t, datasource.DataBatch{uint64(scanSize + 100), values}, result)
+	assert.Equal(t, datasource.DataBatch{Size: uint64(scanSize + 100), Data: values}, result)
+}
+
+func TestRedisClient_GetContentPageForList(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	var values []interface{}
+	for i := 0; i < 250; i++ {
+		values = append(values, fmt.Sprintf("my-value-%d", i))
+	}
+	client.client.RPush(context.Background(), "my-list", values...)
+
+	// when
+	content, err := client.GetContentPage(context.Background(), "my-list", datasource.Filter{}, datasource.Page{Cursor: "200", Count: 100})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.ContentPage{Size: 50, Data: values[200:], Cursor: datasource.FirstPage}, content)
+
+	// when
+	content, err = client.GetContentPage(context.Background(), "my-list", datasource.Filter{}, datasource.Page{Cursor: "100", Count: 100})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.ContentPage{Size: 100, Data: values[100:200], Cursor: "200"}, content)
+
+	// when
+	_, err = client.GetContentPage(context.Background(), "my-list", datasource.Filter{}, datasource.Page{Cursor: "next"})
+
+	// then
+	assert.EqualError(t, err, "the cursor 'next' is not valid")
 }
 
 func TestRedisClient_GetContentForOrderedSet(t *testing.T) {
@@ -1110,13 +1428,13 @@ func TestRedisClient_GetContentForOrderedSet(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-zset", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-zset", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
 	result := <-data
 	// Result is expected ordered, by score and in the values sets as well.
-	assert.Equal(t, datasource.DataBatch{5, []interface{}{
+	assert.Equal(t, datasource.DataBatch{Size: 5, Data: []interface{}{
 		SortedSetValues{
 			Score:  0.5,
 			Values: []string{"my-first-value", "my-second-value"},
@@ -1157,7 +1475,7 @@ func TestRedisClient_GetContentForOrderedSetBiggerThanScanSize(t *testing.T) {
 
 	var values = []redis.Z{}
 	for i := int(scanSize+100) - 1; i >= 0; i-- {
-		values = append(values, redis.Z{float64(i), "my-value-" + strconv.Itoa(i)})
+		values = append(values, redis.Z{Score: float64(i), Member: "my-value-" + strconv.Itoa(i)})
 	}
 	err = client.client.ZAdd(context.Background(), "my-zset", values...).Err()
 	assert.Nil(t, err)
@@ -1165,7 +1483,7 @@ func TestRedisClient_GetContentForOrderedSetBiggerThanScanSize(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	actionStatus, err := client.GetContent("my-zset", "", data)
+	actionStatus, err := client.GetContent(context.Background(), "my-zset", datasource.Filter{}, data)
 
 	// then
 	assert.Equal(t, datasource.Completed, actionStatus)
@@ -1194,24 +1512,14 @@ func TestRedisClient_GetContentForMissingKey(t *testing.T) {
 	data := make(chan datasource.DataBatch, 100)
 
 	// when
-	_, err = client.GetContent("unknown-key", "", data)
+	_, err = client.GetContent(context.Background(), "unknown-key", datasource.Filter{}, data)
 
 	// then
 	assert.NotNil(t, err, "An error is expected here, because the key does not exist")
 }
 
-func TestRedisClient_DeleteEntrypointChildren(t *testing.T) {
+func TestRedisClient_GetContentWithFilter(t *testing.T) {
 	// given
-	testData := []struct {
-		key   string
-		count int
-	}{
-		{key: "group-atom", count: 200},
-		{key: "group-atic", count: 200},
-		{key: "group-artic", count: 200},
-		{key: "group-bolton", count: 200},
-	}
-
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
 			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
@@ -1224,46 +1532,56 @@ func TestRedisClient_DeleteEntrypointChildren(t *testing.T) {
 		client.Close()
 	}()
 
-	for _, d := range testData {
-		// In the case, also create the parent to check it is not deleted.
-		client.client.Set(context.Background(), d.key, d.key, time.Minute)
-		for i := 1; i <= d.count; i++ {
-			s := fmt.Sprintf("%s:%d", d.key, i)
-			client.client.Set(context.Background(), s, s, time.Minute)
-		}
-	}
-	// Check the number of keys before.
-	keys, err := client.client.Keys(context.Background(), "*").Result()
-	assert.Nil(t, err)
-	if len(keys) != 804 { // There are three root nodes plus all their leaves.
-		t.Fatalf("Expected result size is %d, but actual is %d", 804, len(keys))
-	}
+	client.client.RPush(context.Background(), "my-list", "value-1", "other-2", "value-3", "value-30")
+	client.client.SAdd(context.Background(), "my-set", "1", "5", "12", "50")
+	client.client.ZAdd(context.Background(), "my-zset", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"}, redis.Z{Score: 3, Member: "c"})
+	client.client.HSet(context.Background(), "my-hash", "user:1", "active", "user:2", "inactive", "order:1", "active")
+	data := make(chan datasource.DataBatch, 1)
 
-	errorChannel := make(chan error, 10)
+	// when
+	_, err = client.GetContent(context.Background(), "my-list", datasource.Filter{Glob: "value-*", ValueRange: &datasource.ValueRange{Max: "value-3"}}, data)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.DataBatch{Size: 2, Data: []interface{}{"value-1", "value-3"}}, <-data)
 
 	// when
-	actionStatus, err := client.DeleteEntrypointChildren("group-atic", errorChannel)
+	_, err = client.GetContent(context.Background(), "my-set", datasource.Filter{ValueRange: &datasource.ValueRange{Min: "5", Max: "20"}}, data)
 
 	// then
 	assert.Nil(t, err)
-	assert.Equal(t, datasource.Moved, actionStatus)
+	assert.Equal(t, datasource.DataBatch{Size: 2, Data: []interface{}{"12", "5"}}, <-data)
 
-	// Wait for error channel to be closed.
-	err = <-errorChannel
+	// when
+	min := 2.0
+	_, err = client.GetContent(context.Background(), "my-zset", datasource.Filter{ScoreRange: datasource.NewScoreRange(&min, nil)}, data)
+
+	// then
 	assert.Nil(t, err)
+	assert.Equal(t, datasource.DataBatch{Size: 2, Data: []interface{}{
+		SortedSetValues{Score: 2, Values: []string{"b"}},
+		SortedSetValues{Score: 3, Values: []string{"c"}},
+	}}, <-data)
 
-	keys, err = client.client.Keys(context.Background(), "*").Result()
+	// when
+	_, err = client.GetContent(context.Background(), "my-hash", datasource.Filter{Fields: []datasource.FieldFilter{{Field: "user:*", Value: "active"}}}, data)
+
+	// then
 	assert.Nil(t, err)
-	if len(keys) != 604 { // There are still four root nodes plus all the leaves of only three of them.
-		t.Fatalf("Expected result size is %d, but actual is %d", 604, len(keys))
-	}
+	assert.Equal(t, datasource.DataBatch{Size: 1, Data: []interface{}{HashValue{"user:1", "active"}}}, <-data)
+
+	// when
+	_, err = client.GetContent(context.Background(), "my-list", datasource.Filter{Regex: "value-[0-9"}, data)
+
+	// then
+	assert.NotNil(t, err, "An error is expected here, because the regular expression is invalid")
 }
 
-func TestRedisClient_DeleteEntrypointChildrenInReadOnlyMode(t *testing.T) {
+func TestRedisClient_GetContentForStreamWithFieldFilter(t *testing.T) {
+	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
 			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
-			ReadOnly:  true,
 		},
 	}
 	err := client.Open()
@@ -1273,16 +1591,23 @@ func TestRedisClient_DeleteEntrypointChildrenInReadOnlyMode(t *testing.T) {
 		client.Close()
 	}()
 
-	errorChannel := make(chan error, 10)
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: "1-0", Values: map[string]interface{}{"type": "order-created"}})
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: "2-0", Values: map[string]interface{}{"type": "payment"}})
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: "3-0", Values: map[string]interface{}{"type": "order-shipped"}})
+	data := make(chan datasource.DataBatch, 1)
 
 	// when
-	_, err = client.DeleteEntrypointChildren("any", errorChannel)
+	_, err = client.GetContent(context.Background(), "my-stream", datasource.Filter{Fields: []datasource.FieldFilter{{Field: "type", Value: "order-*"}}}, data)
 
 	// then
-	assert.NotNil(t, err)
+	assert.Nil(t, err)
+	result := <-data
+	assert.Equal(t, uint64(2), result.Size)
+	assert.Equal(t, "1-0", result.Data[0].(StreamMessage).Id)
+	assert.Equal(t, "3-0", result.Data[1].(StreamMessage).Id)
 }
 
-func TestRedisClient_DeleteEntrypoint(t *testing.T) {
+func TestRedisClient_DeleteEntrypointChildren(t *testing.T) {
 	// given
 	testData := []struct {
 		key   string
@@ -1307,7 +1632,7 @@ func TestRedisClient_DeleteEntrypoint(t *testing.T) {
 	}()
 
 	for _, d := range testData {
-		// In the case, also create the parent to check that only this one is delete.
+		// In the case, also create the parent to check it is not deleted.
 		client.client.Set(context.Background(), d.key, d.key, time.Minute)
 		for i := 1; i <= d.count; i++ {
 			s := fmt.Sprintf("%s:%d", d.key, i)
@@ -1321,25 +1646,1166 @@ func TestRedisClient_DeleteEntrypoint(t *testing.T) {
 		t.Fatalf("Expected result size is %d, but actual is %d", 804, len(keys))
 	}
 
+	errorChannel := make(chan error, 10)
+
 	// when
-	err = client.DeleteEntrypoint("group-artic")
+	actionStatus, err := client.DeleteEntrypointChildren(context.Background(), "group-atic", 0, errorChannel)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+
+	// Wait for error channel to be closed.
+	err = <-errorChannel
+	assert.Nil(t, err)
+
+	keys, err = client.client.Keys(context.Background(), "*").Result()
+	assert.Nil(t, err)
+	if len(keys) != 604 { // There are still four root nodes plus all the leaves of only three of them.
+		t.Fatalf("Expected result size is %d, but actual is %d", 604, len(keys))
+	}
+}
+
+func TestRedisClient_DeleteEntrypointChildrenWithRateLimit(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	for i := 0; i < 300; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session:%d", i), "value", -1)
+	}
+	client.client.Set(context.Background(), "other:1", "value", -1)
+	errorChannel := make(chan error, 10)
+	start := time.Now()
+
+	// when
+	_, err = client.DeleteEntrypointChildren(context.Background(), "session", 1000, errorChannel)
+	for err := range errorChannel {
+		assert.Nil(t, err)
+	}
+
+	// then
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) >= 300*time.Millisecond, "The deletion should last at least 300 ms")
+	assert.Equal(t, int64(0), client.client.Exists(context.Background(), "session:0", "session:299").Val())
+	assert.Equal(t, int64(1), client.client.Exists(context.Background(), "other:1").Val())
+}
+
+func TestRedisClient_DeleteEntrypointChildrenWhenCancelled(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	for i := 0; i < 300; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session:%d", i), "value", -1)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errorChannel := make(chan error, 10)
+	start := time.Now()
+
+	// when
+	_, err = client.DeleteEntrypointChildren(ctx, "session", 100, errorChannel)
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	for err := range errorChannel {
+		assert.Nil(t, err)
+	}
+
+	// then
+	assert.Nil(t, err)
+	// The deletion stops without waiting for the 3 seconds required by the rate limit.
+	assert.True(t, time.Since(start) < time.Second, "The deletion should stop once cancelled")
+	keys, err := client.client.Keys(context.Background(), "session:*").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, 200, len(keys))
+}
+
+func TestSplitKeys(t *testing.T) {
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, splitKeys([]string{"a", "b", "c"}, 2))
+	assert.Nil(t, splitKeys([]string{}, 2))
+}
+
+func TestRedisClient_PreviewEntrypointChildrenDeletion(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.Set(context.Background(), "session", "parent", -1)
+	for i := 0; i < 5; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session:%d", i), "value", -1)
+	}
+	client.client.Set(context.Background(), "other:1", "value", -1)
+
+	// when
+	preview, err := client.PreviewEntrypointChildrenDeletion(context.Background(), "session", 2)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), preview.Count)
+	assert.Equal(t, []datasource.EntryPoint{"session:0", "session:1"}, preview.Sample)
+	assert.True(t, preview.Memory > 0)
+	assert.Equal(t, int64(6), client.client.Exists(context.Background(), "session", "session:0", "session:1", "session:2", "session:3", "session:4").Val())
+}
+
+func TestRedisClient_DeleteEntrypointChildrenInReadOnlyMode(t *testing.T) {
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			ReadOnly:  true,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	errorChannel := make(chan error, 10)
+
+	// when
+	_, err = client.DeleteEntrypointChildren(context.Background(), "any", 0, errorChannel)
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestRedisClient_DeleteEntrypoint(t *testing.T) {
+	// given
+	testData := []struct {
+		key   string
+		count int
+	}{
+		{key: "group-atom", count: 200},
+		{key: "group-atic", count: 200},
+		{key: "group-artic", count: 200},
+		{key: "group-bolton", count: 200},
+	}
+
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	for _, d := range testData {
+		// In the case, also create the parent to check that only this one is delete.
+		client.client.Set(context.Background(), d.key, d.key, time.Minute)
+		for i := 1; i <= d.count; i++ {
+			s := fmt.Sprintf("%s:%d", d.key, i)
+			client.client.Set(context.Background(), s, s, time.Minute)
+		}
+	}
+	// Check the number of keys before.
+	keys, err := client.client.Keys(context.Background(), "*").Result()
+	assert.Nil(t, err)
+	if len(keys) != 804 { // There are three root nodes plus all their leaves.
+		t.Fatalf("Expected result size is %d, but actual is %d", 804, len(keys))
+	}
+
+	// when
+	err = client.DeleteEntrypoint(context.Background(), "group-artic")
 
 	// then
 	assert.Nil(t, err)
 
 	keys, err = client.client.Keys(context.Background(), "*").Result()
 	assert.Nil(t, err)
-	if len(keys) != 803 { // There are three root nodes plus all the leaves of all four nodes.
-		t.Fatalf("Expected result size is %d, but actual is %d", 803, len(keys))
+	if len(keys) != 803 { // There are three root nodes plus all the leaves of all four nodes.
+		t.Fatalf("Expected result size is %d, but actual is %d", 803, len(keys))
+	}
+}
+
+func TestRedisClient_DeleteEntrypointInReadOnlyMode(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			ReadOnly:  true,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	err = client.DeleteEntrypoint(context.Background(), "group-artic")
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestRedisClient_RestoreDeletedEntrypointFromTrash(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.HSet(context.Background(), "my-hash", "field", "value")
+	client.client.Expire(context.Background(), "my-hash", time.Hour)
+	err = client.DeleteEntrypoint(context.Background(), "my-hash")
+	assert.Nil(t, err)
+
+	entries, err := client.ListTrashEntries(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, datasource.EntryPoint("my-hash"), entries[0].EntryPoint)
+	assert.Equal(t, "delete", entries[0].Operation)
+	assert.NotNil(t, entries[0].ExpireAt)
+
+	// when
+	err = client.RestoreTrashEntry(context.Background(), entries[0].Id, false)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"field": "value"}, client.client.HGetAll(context.Background(), "my-hash").Val())
+	assert.True(t, client.client.TTL(context.Background(), "my-hash").Val() > 0)
+	entries, _ = client.ListTrashEntries(context.Background())
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestRedisClient_RestoreChangedEntrypointFromTrash(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "my-string", "old-value", -1)
+	err = client.SetValue(context.Background(), "my-string", "new-value")
+	assert.Nil(t, err)
+	// A new key has nothing to backup.
+	_, err = client.AddSetMembers(context.Background(), "my-set", []string{"a"})
+	assert.Nil(t, err)
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Equal(t, 1, len(entries))
+
+	// when
+	err = client.RestoreTrashEntry(context.Background(), entries[0].Id, false)
+
+	// then
+	assert.NotNil(t, err, "The existing value should not be replaced")
+
+	// when
+	err = client.RestoreTrashEntry(context.Background(), entries[0].Id, true)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "old-value", client.client.Get(context.Background(), "my-string").Val())
+	entries, _ = client.ListTrashEntries(context.Background())
+	assert.Equal(t, 1, len(entries), "The replaced value should be saved into the trash")
+	assert.Equal(t, "restore", entries[0].Operation)
+}
+
+func TestRedisClient_DeleteEntrypointChildrenWithBackup(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "session:1", "value-1", -1)
+	client.client.Set(context.Background(), "session:2", "value-2", -1)
+	errorChannel := make(chan error, 10)
+
+	// when
+	_, err = client.DeleteEntrypointChildren(context.Background(), "session", 0, errorChannel)
+	for range errorChannel {
+	}
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), client.client.Exists(context.Background(), "session:1", "session:2").Val())
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Equal(t, 2, len(entries))
+}
+
+func TestRedisClient_DeleteEntrypointLargerThanTheTrash(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap:     fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			Configuration: map[string]string{"trashMaxSize": "32"},
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "my-string", strings.Repeat("value", 20), -1)
+
+	// when
+	err = client.DeleteEntrypoint(context.Background(), "my-string")
+
+	// then
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximal size of the trash")
+	assert.Equal(t, int64(1), client.client.Exists(context.Background(), "my-string").Val())
+
+	// when
+	err = client.SetValue(context.Background(), "my-string", "new-value")
+
+	// then
+	assert.NotNil(t, err)
+	assert.Equal(t, strings.Repeat("value", 20), client.client.Get(context.Background(), "my-string").Val())
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestRedisClient_DeleteEntrypointChildrenLargerThanTheTrash(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap:     fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			Configuration: map[string]string{"trashMaxSize": "256"},
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	for i := 0; i < 10; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session:%d", i), strings.Repeat("value", 20), -1)
+	}
+	errorChannel := make(chan error, 10)
+
+	// when
+	_, err = client.DeleteEntrypointChildren(context.Background(), "session", 0, errorChannel)
+	var errs []error
+	for err := range errorChannel {
+		errs = append(errs, err)
+	}
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "cannot be kept in the trash, the deletion is cancelled")
+	assert.Equal(t, int64(10), client.client.Exists(context.Background(), "session:0", "session:1", "session:2", "session:3",
+		"session:4", "session:5", "session:6", "session:7", "session:8", "session:9").Val())
+	entries, _ := client.ListTrashEntries(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestRedisClient_ElementChangesAreSavedOnlyWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("trashElementChanges=%t", enabled), func(t *testing.T) {
+			// given
+			client := RedisClient{
+				datasource: &datasource.DataSourceDescriptor{
+					Bootstrap:     fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+					Configuration: map[string]string{"trashElementChanges": strconv.FormatBool(enabled)},
+				},
+			}
+			err := client.Open()
+			assert.Nil(t, err)
+			defer func() {
+				client.client.FlushAll(context.Background())
+				client.Close()
+			}()
+			client.client.HSet(context.Background(), "my-hash", "field", "value", "other", "value")
+			client.client.SAdd(context.Background(), "my-set", "a")
+
+			// when
+			err = client.SetHashFields(context.Background(), "my-hash", map[string]string{"field": "new-value"})
+			assert.Nil(t, err)
+			_, err = client.AddSetMembers(context.Background(), "my-set", []string{"b"})
+			assert.Nil(t, err)
+			// A new entry point has nothing to save.
+			_, err = client.PushListValues(context.Background(), "my-list", []string{"a"}, false)
+			assert.Nil(t, err)
+
+			// then
+			entries, _ := client.ListTrashEntries(context.Background())
+			if enabled {
+				assert.Len(t, entries, 2)
+				assert.ElementsMatch(t, []datasource.EntryPoint{"my-hash", "my-set"}, []datasource.EntryPoint{entries[0].EntryPoint, entries[1].EntryPoint})
+			} else {
+				assert.Empty(t, entries)
+			}
+		})
+	}
+}
+
+func TestRedisClient_WithoutTrash(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap:     fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			Configuration: map[string]string{"trashRetention": "0"},
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "my-string", "old-value", -1)
+
+	// when
+	err = client.DeleteEntrypoint(context.Background(), "my-string")
+
+	// then
+	assert.Nil(t, err)
+	entries, err := client.ListTrashEntries(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestRedisClient_RenameEntrypoint(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "my-string", "my-value", time.Hour)
+
+	// when
+	err = client.RenameEntrypoint(context.Background(), "my-string", "my-renamed-string")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), client.client.Exists(context.Background(), "my-string").Val())
+	assert.Equal(t, "my-value", client.client.Get(context.Background(), "my-renamed-string").Val())
+	assert.True(t, client.client.TTL(context.Background(), "my-renamed-string").Val() > 0)
+}
+
+func TestRedisClient_RenameEntrypointToExistingOne(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "my-string", "my-value", -1)
+	client.client.Set(context.Background(), "my-other-string", "my-other-value", -1)
+
+	// when
+	err = client.RenameEntrypoint(context.Background(), "my-string", "my-other-string")
+
+	// then
+	assert.NotNil(t, err)
+	assert.Equal(t, "my-value", client.client.Get(context.Background(), "my-string").Val())
+	assert.Equal(t, "my-other-value", client.client.Get(context.Background(), "my-other-string").Val())
+}
+
+func TestRedisClient_MoveEntrypointTreeInDryRun(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "a:b", "parent", -1)
+	client.client.Set(context.Background(), "a:b:1", "value", -1)
+	client.client.Set(context.Background(), "a:b:2:3", "value", -1)
+	client.client.Set(context.Background(), "a:c:1", "existing", -1)
+	progressChannel := make(chan datasource.DataBatch, 10)
+
+	// when
+	actionStatus, err := client.MoveEntrypointTree(context.Background(), "a:b", "a:c", true, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	var moves []interface{}
+	for batch := range progressChannel {
+		moves = append(moves, batch.Data...)
+	}
+	assert.Equal(t, []interface{}{
+		datasource.EntryPointMove{From: "a:b", To: "a:c"},
+		datasource.EntryPointMove{From: "a:b:1", To: "a:c:1", Conflict: true},
+		datasource.EntryPointMove{From: "a:b:2:3", To: "a:c:2:3"},
+	}, moves)
+	assert.Equal(t, int64(3), client.client.Exists(context.Background(), "a:b", "a:b:1", "a:b:2:3").Val())
+}
+
+func TestRedisClient_MoveEntrypointTree(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "a:b", "parent", -1)
+	client.client.Set(context.Background(), "a:b:1", "value-1", -1)
+	client.client.Set(context.Background(), "a:b:2:3", "value-3", -1)
+	client.client.Set(context.Background(), "a:bc", "sibling", -1)
+	progressChannel := make(chan datasource.DataBatch, 10)
+
+	// when
+	_, err = client.MoveEntrypointTree(context.Background(), "a:b", "a:c", false, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	var lastProgress datasource.Progress
+	for batch := range progressChannel {
+		lastProgress = batch.Progress.(datasource.Progress)
+	}
+	assert.Equal(t, datasource.Progress{Total: 3, Processed: 3}, lastProgress)
+	assert.Equal(t, "parent", client.client.Get(context.Background(), "a:c").Val())
+	assert.Equal(t, "value-1", client.client.Get(context.Background(), "a:c:1").Val())
+	assert.Equal(t, "value-3", client.client.Get(context.Background(), "a:c:2:3").Val())
+	assert.Equal(t, "sibling", client.client.Get(context.Background(), "a:bc").Val())
+	assert.Equal(t, int64(0), client.client.Exists(context.Background(), "a:b", "a:b:1", "a:b:2:3").Val())
+}
+
+// cancelOnScan cancels the context of the operation when its first SCAN is sent.
+type cancelOnScan struct {
+	cancel context.CancelFunc
+}
+
+func (h cancelOnScan) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h cancelOnScan) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "scan" {
+			h.cancel()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h cancelOnScan) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisClient_MoveEntrypointTreeCancelledWhileScanning(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "a:b", "parent", -1)
+	client.client.Set(context.Background(), "a:b:1", "value-1", -1)
+	ctx, cancel := context.WithCancel(context.Background())
+	client.client.(*redis.Client).AddHook(cancelOnScan{cancel: cancel})
+	progressChannel := make(chan datasource.DataBatch, 10)
+
+	// when
+	_, err = client.MoveEntrypointTree(ctx, "a:b", "a:c", false, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	for range progressChannel {
+	}
+	// Nothing is moved from an incomplete listing.
+	assert.Equal(t, int64(2), client.client.Exists(context.Background(), "a:b", "a:b:1").Val())
+	assert.Equal(t, int64(0), client.client.Exists(context.Background(), "a:c", "a:c:1").Val())
+}
+
+func TestRedisClient_MoveEntrypointTreeIntoItself(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	progressChannel := make(chan datasource.DataBatch, 10)
+
+	// when
+	_, err = client.MoveEntrypointTree(context.Background(), "a:b", "a:b:c", false, progressChannel)
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestKeySlot(t *testing.T) {
+	assert.Equal(t, 12182, keySlot("foo"))
+	assert.Equal(t, 5061, keySlot("bar"))
+	assert.Equal(t, keySlot("user1000"), keySlot("{user1000}.following"))
+	assert.Equal(t, keySlot("{user1000}.following"), keySlot("{user1000}.followers"))
+	// Empty hash tags are ignored.
+	assert.Equal(t, crc16("foo{}{bar}")%slotCount, uint16(keySlot("foo{}{bar}")))
+}
+
+func TestRedisClient_SetExpiry(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "my-string", "my-value", -1)
+
+	// when
+	err = client.SetExpiry(context.Background(), "my-string", datasource.Expiry{TimeToLive: time.Hour})
+
+	// then
+	assert.Nil(t, err)
+	ttl := client.client.PTTL(context.Background(), "my-string").Val()
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "The TTL should be one hour but was %s", ttl)
+
+	// when
+	err = client.SetExpiry(context.Background(), "my-string", datasource.Expiry{ExpireAt: time.Now().Add(2 * time.Hour)})
+
+	// then
+	assert.Nil(t, err)
+	ttl = client.client.PTTL(context.Background(), "my-string").Val()
+	assert.True(t, ttl > 119*time.Minute && ttl <= 2*time.Hour, "The TTL should be two hours but was %s", ttl)
+
+	// when
+	err = client.SetExpiry(context.Background(), "my-string", datasource.Expiry{})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(-1), client.client.TTL(context.Background(), "my-string").Val())
+}
+
+func TestRedisClient_SetExpiryOfMissingKey(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	err = client.SetExpiry(context.Background(), "my-string", datasource.Expiry{TimeToLive: time.Hour})
+
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestRedisClient_SetChildrenExpiry(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.Set(context.Background(), "session", "parent", -1)
+	for i := 0; i < 1500; i++ {
+		client.client.Set(context.Background(), fmt.Sprintf("session:%d", i), "value", -1)
+	}
+	client.client.Set(context.Background(), "other:1", "value", -1)
+	progressChannel := make(chan datasource.DataBatch, 10)
+
+	// when
+	actionStatus, err := client.SetChildrenExpiry(context.Background(), "session", datasource.Expiry{TimeToLive: time.Hour}, progressChannel)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	var lastProgress datasource.Progress
+	for batch := range progressChannel {
+		lastProgress = batch.Progress.(datasource.Progress)
+	}
+	assert.Equal(t, datasource.Progress{Total: 1500, Processed: 1500}, lastProgress)
+	assert.True(t, client.client.TTL(context.Background(), "session:1499").Val() > 0)
+	assert.Equal(t, time.Duration(-1), client.client.TTL(context.Background(), "session").Val())
+	assert.Equal(t, time.Duration(-1), client.client.TTL(context.Background(), "other:1").Val())
+}
+
+func TestRedisClient_SetValue(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	err = client.SetValue(context.Background(), "my-string", "my-value")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", client.client.Get(context.Background(), "my-string").Val())
+}
+
+func TestRedisClient_AddAndRemoveSetMembers(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	added, err := client.AddSetMembers(context.Background(), "my-set", []string{"a", "b", "c"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), added)
+
+	// when
+	removed, err := client.RemoveSetMembers(context.Background(), "my-set", []string{"a", "z"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), removed)
+	assert.ElementsMatch(t, []string{"b", "c"}, client.client.SMembers(context.Background(), "my-set").Val())
+}
+
+func TestRedisClient_AddSortedSetMembers(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.ZAdd(context.Background(), "my-zset", redis.Z{Score: 1, Member: "a"})
+
+	// when
+	added, err := client.AddSortedSetMembers(context.Background(), "my-zset", []datasource.ScoredMember{{Member: "a", Score: 3}, {Member: "b", Score: 2}})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), added)
+	assert.Equal(t, []redis.Z{{Score: 2, Member: "b"}, {Score: 3, Member: "a"}}, client.client.ZRangeWithScores(context.Background(), "my-zset", 0, -1).Val())
+}
+
+func TestRedisClient_SetAndDeleteHashFields(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	err = client.SetHashFields(context.Background(), "my-hash", map[string]string{"field-1": "value-1", "field-2": "value-2"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"field-1": "value-1", "field-2": "value-2"}, client.client.HGetAll(context.Background(), "my-hash").Val())
+
+	// when
+	deleted, err := client.DeleteHashFields(context.Background(), "my-hash", []string{"field-1"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, map[string]string{"field-2": "value-2"}, client.client.HGetAll(context.Background(), "my-hash").Val())
+}
+
+func TestRedisClient_EditList(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	length, err := client.PushListValues(context.Background(), "my-list", []string{"b", "c", "b"}, false)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), length)
+
+	// when
+	length, err = client.PushListValues(context.Background(), "my-list", []string{"a"}, true)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), length)
+
+	// when
+	err = client.SetListValue(context.Background(), "my-list", 2, "d")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "d", "b"}, client.client.LRange(context.Background(), "my-list", 0, -1).Val())
+
+	// when
+	removed, err := client.RemoveListValue(context.Background(), "my-list", "b", 0)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), removed)
+	assert.Equal(t, []string{"a", "d"}, client.client.LRange(context.Background(), "my-list", 0, -1).Val())
+}
+
+func TestRedisClient_AddAndDeleteStreamMessages(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	id, err := client.AddStreamMessage(context.Background(), "my-stream", "1-0", map[string]interface{}{"field": "value"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "1-0", id)
+
+	// when
+	id, err = client.AddStreamMessage(context.Background(), "my-stream", "", map[string]interface{}{"field": "other-value"})
+
+	// then
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", id)
+
+	// when
+	deleted, err := client.DeleteStreamMessages(context.Background(), "my-stream", []string{"1-0"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, int64(1), client.client.XLen(context.Background(), "my-stream").Val())
+}
+
+func TestRedisClient_WriteInReadOnlyMode(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			ReadOnly:  true,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	setErr := client.SetValue(context.Background(), "my-string", "my-value")
+	_, addErr := client.AddSetMembers(context.Background(), "my-set", []string{"a"})
+	_, pushErr := client.PushListValues(context.Background(), "my-list", []string{"a"}, false)
+	_, streamErr := client.AddStreamMessage(context.Background(), "my-stream", "", map[string]interface{}{"field": "value"})
+
+	// then
+	assert.NotNil(t, setErr)
+	assert.NotNil(t, addErr)
+	assert.NotNil(t, pushErr)
+	assert.NotNil(t, streamErr)
+	assert.Equal(t, int64(0), client.client.Exists(context.Background(), "my-string", "my-set", "my-list", "my-stream").Val())
+}
+
+func TestRedisClient_CommandInReadOnlyMode(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Id:        "test",
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			ReadOnly:  true,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when + then
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"KEYS", "*"}, "")
+	assert.Nil(t, err)
+
+	// when + then
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"SET", "test", "1234"}, "")
+	assert.Equal(t, err.Error(), "the data source test can only be read")
+
+	// when + then
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"CLUSTER", "NODES"}, "")
+	assert.NotNil(t, err)
+	assert.NotEqual(t, err.Error(), "the data source test can only be read")
+
+	// when + then
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"CLUSTER", "FORGET"}, "")
+	assert.Equal(t, err.Error(), "the data source test can only be read")
+
+	// when + then
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"CLIENT", "KILL"}, "")
+	assert.Equal(t, err.Error(), "the data source test can only be read")
+}
+
+func TestRedisClient_GetInfos(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	infos, err := client.GetInfos(context.Background())
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Cluster{}, infos)
+}
+
+func TestRedisClient_GetStatus(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	status, err := client.GetStatus(context.Background())
+
+	// then
+	assert.Nil(t, err)
+	assert.NotNil(t, status.Timestamp)
+	assert.Empty(t, status.StateSections)
+	assert.NotEmpty(t, status.NodeStates)
+}
+
+func TestRedisClient_SetAndGetValueWithExecuteCommand(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Id:        "test",
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			ReadOnly:  false,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	_, _ = client.ExecuteCommand(context.Background(), []interface{}{"SET", "key", "value"}, "")
+
+	// when
+	rs, _ := client.ExecuteCommand(context.Background(), []interface{}{"GET", "key"}, "")
+
+	// then
+	assert.Equal(t, "value", rs)
+}
+
+func TestRedisClient_ExecuteUnknownCommand(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Id:        "test",
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+			ReadOnly:  false,
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	// when
+	_, err = client.ExecuteCommand(context.Background(), []interface{}{"AN_UNKNOWN_COMMAND", "AN_UNKNOWN_ARG"}, "")
+
+	// then
+	assert.Contains(t, err.Error(), "ERR unknown command")
+}
+
+func TestRedisClient_GetContentForStream(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	for i := 1; i <= 3; i++ {
+		err = client.client.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: "my-stream",
+			ID:     fmt.Sprintf("1526985054069-%d", i),
+			Values: map[string]interface{}{"field": fmt.Sprintf("value-%d", i)},
+		}).Err()
+		assert.Nil(t, err)
+	}
+	data := make(chan datasource.DataBatch, 1)
+
+	// when
+	actionStatus, err := client.GetContent(context.Background(), "my-stream", datasource.Filter{}, data)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, actionStatus)
+	result := <-data
+	assert.Equal(t, uint64(3), result.Size)
+	for i, message := range result.Data {
+		streamMessage := message.(StreamMessage)
+		assert.Equal(t, fmt.Sprintf("1526985054069-%d", i+1), streamMessage.Id)
+		assert.Equal(t, map[string]interface{}{"field": fmt.Sprintf("value-%d", i+1)}, streamMessage.Fields)
+		assert.True(t, time.Unix(0, 1526985054069*int64(time.Millisecond)).Equal(streamMessage.Timestamp))
 	}
 }
 
-func TestRedisClient_DeleteEntrypointInReadOnlyMode(t *testing.T) {
+func TestRedisClient_GetContentForStreamLargerThanScanSize(t *testing.T) {
 	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
 			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
-			ReadOnly:  true,
 		},
 	}
 	err := client.Open()
@@ -1349,20 +2815,51 @@ func TestRedisClient_DeleteEntrypointInReadOnlyMode(t *testing.T) {
 		client.Close()
 	}()
 
+	messagesCount := int(2*scanSize + 5)
+	_, err = client.client.Pipelined(context.Background(), func(pipeliner redis.Pipeliner) error {
+		for i := 1; i <= messagesCount; i++ {
+			pipeliner.XAdd(context.Background(), &redis.XAddArgs{
+				Stream: "my-stream",
+				ID:     fmt.Sprintf("1526985054069-%d", i),
+				Values: map[string]interface{}{"field": fmt.Sprintf("value-%d", i)},
+			})
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	data := make(chan datasource.DataBatch, 1)
+
 	// when
-	err = client.DeleteEntrypoint("group-artic")
+	actionStatus, err := client.GetContent(context.Background(), "my-stream", datasource.Filter{}, data)
 
 	// then
-	assert.NotNil(t, err)
+	assert.Nil(t, err)
+	// The stream is sent by pages.
+	assert.Equal(t, datasource.Moved, actionStatus)
+	var messages []interface{}
+	batchesCount := 0
+	for batch := range data {
+		assert.Nil(t, batch.Error)
+		batchesCount++
+		messages = append(messages, batch.Data...)
+	}
+	assert.Equal(t, 3, batchesCount)
+	assert.Equal(t, messagesCount, len(messages))
+	for i, message := range messages {
+		assert.Equal(t, fmt.Sprintf("1526985054069-%d", i+1), message.(StreamMessage).Id)
+	}
 }
 
-func TestRedisClient_CommandInReadOnlyMode(t *testing.T) {
+func TestNextStreamId(t *testing.T) {
+	assert.Equal(t, "1526985054069-2", nextStreamId("1526985054069-1"))
+	assert.Equal(t, "1526985054070-0", nextStreamId("1526985054069-18446744073709551615"))
+}
+
+func TestRedisClient_GetStreamRange(t *testing.T) {
 	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
-			Id:        "test",
 			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
-			ReadOnly:  true,
 		},
 	}
 	err := client.Open()
@@ -1372,29 +2869,73 @@ func TestRedisClient_CommandInReadOnlyMode(t *testing.T) {
 		client.Close()
 	}()
 
-	// when + then
-	_, err = client.ExecuteCommand([]interface{}{"KEYS", "*"}, "")
+	for i := 1; i <= 10; i++ {
+		client.client.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: "my-stream",
+			ID:     fmt.Sprintf("%d-0", i),
+			Values: map[string]interface{}{"index": i},
+		})
+	}
+	data := make(chan datasource.DataBatch, 1)
+
+	// when
+	actionStatus, err := client.GetStreamRange(context.Background(), "my-stream", datasource.StreamRange{Start: "3", End: "8", Count: 4}, data)
+
+	// then
 	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, actionStatus)
+	result := <-data
+	ids := []interface{}{}
+	for _, message := range result.Data {
+		ids = append(ids, message.(StreamMessage).Id)
+	}
+	assert.Equal(t, uint64(4), result.Size)
+	assert.Equal(t, []interface{}{"3-0", "4-0", "5-0", "6-0"}, ids)
 
-	// when + then
-	_, err = client.ExecuteCommand([]interface{}{"SET", "test", "1234"}, "")
-	assert.Equal(t, err.Error(), "the data source test can only be read")
+	// when
+	actionStatus, err = client.GetStreamRange(context.Background(), "my-stream", datasource.StreamRange{End: "8", Count: 4, Reverse: true}, data)
 
-	// when + then
-	_, err = client.ExecuteCommand([]interface{}{"CLUSTER", "NODES"}, "")
+	// then
+	assert.Nil(t, err)
+	result = <-data
+	ids = []interface{}{}
+	for _, message := range result.Data {
+		ids = append(ids, message.(StreamMessage).Id)
+	}
+	assert.Equal(t, []interface{}{"8-0", "7-0", "6-0", "5-0"}, ids)
+}
+
+func TestRedisClient_GetStreamRangeForOtherType(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.Set(context.Background(), "my-string", "my-value", -1)
+	data := make(chan datasource.DataBatch, 1)
+
+	// when
+	_, err = client.GetStreamRange(context.Background(), "my-string", datasource.StreamRange{}, data)
+
+	// then
 	assert.NotNil(t, err)
-	assert.NotEqual(t, err.Error(), "the data source test can only be read")
 
-	// when + then
-	_, err = client.ExecuteCommand([]interface{}{"CLUSTER", "FORGET"}, "")
-	assert.Equal(t, err.Error(), "the data source test can only be read")
+	// when
+	_, err = client.GetStreamRange(context.Background(), "unknown-key", datasource.StreamRange{}, data)
 
-	// when + then
-	_, err = client.ExecuteCommand([]interface{}{"CLIENT", "KILL"}, "")
-	assert.Equal(t, err.Error(), "the data source test can only be read")
+	// then
+	assert.NotNil(t, err, "An error is expected here, because the key does not exist")
 }
 
-func TestRedisClient_GetInfos(t *testing.T) {
+func TestRedisClient_Consume(t *testing.T) {
 	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
@@ -1408,15 +2949,32 @@ func TestRedisClient_GetInfos(t *testing.T) {
 		client.Close()
 	}()
 
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: "1-0", Values: map[string]interface{}{"field": "before"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	data := make(chan datasource.DataBatch, 10)
+
 	// when
-	infos, err := client.GetInfos()
+	actionStatus, err := client.Consume(ctx, "my-stream", data, datasource.Filter{}, false)
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: "2-0", Values: map[string]interface{}{"field": "after"}})
 
 	// then
 	assert.Nil(t, err)
-	assert.Equal(t, datasource.Cluster{}, infos)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	result := <-data
+	// Only the message added after the start of the consumption is received.
+	assert.Equal(t, uint64(1), result.Size)
+	assert.Equal(t, "2-0", result.Data[0].(StreamMessage).Id)
+
+	// when
+	cancel()
+
+	// then
+	_, open := <-data
+	assert.False(t, open, "The channel should be closed once the context is cancelled")
 }
 
-func TestRedisClient_GetStatus(t *testing.T) {
+func TestRedisClient_ConsumeFromBeginning(t *testing.T) {
 	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
@@ -1430,23 +2988,25 @@ func TestRedisClient_GetStatus(t *testing.T) {
 		client.Close()
 	}()
 
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "my-stream", ID: "1-0", Values: map[string]interface{}{"field": "before"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	data := make(chan datasource.DataBatch, 10)
+
 	// when
-	status, err := client.GetStatus()
+	_, err = client.Consume(ctx, "my-stream", data, datasource.Filter{}, true)
 
 	// then
 	assert.Nil(t, err)
-	assert.NotNil(t, status.Timestamp)
-	assert.Empty(t, status.StateSections)
-	assert.NotEmpty(t, status.NodeStates)
+	result := <-data
+	assert.Equal(t, "1-0", result.Data[0].(StreamMessage).Id)
 }
 
-func TestRedisClient_SetAndGetValueWithExecuteCommand(t *testing.T) {
+func TestRedisClient_ConsumeOtherType(t *testing.T) {
 	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
-			Id:        "test",
 			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
-			ReadOnly:  false,
 		},
 	}
 	err := client.Open()
@@ -1455,22 +3015,22 @@ func TestRedisClient_SetAndGetValueWithExecuteCommand(t *testing.T) {
 		client.client.FlushAll(context.Background())
 		client.Close()
 	}()
-	_, _ = client.ExecuteCommand([]interface{}{"SET", "key", "value"}, "")
+
+	client.client.Set(context.Background(), "my-string", "my-value", -1)
+	data := make(chan datasource.DataBatch, 10)
 
 	// when
-	rs, _ := client.ExecuteCommand([]interface{}{"GET", "key"}, "")
+	_, err = client.Consume(context.Background(), "my-string", data, datasource.Filter{}, true)
 
 	// then
-	assert.Equal(t, "value", rs)
+	assert.NotNil(t, err)
 }
 
-func TestRedisClient_ExecuteUnknownCommand(t *testing.T) {
+func TestRedisClient_ListChannels(t *testing.T) {
 	// given
 	client := RedisClient{
 		datasource: &datasource.DataSourceDescriptor{
-			Id:        "test",
 			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
-			ReadOnly:  false,
 		},
 	}
 	err := client.Open()
@@ -1480,19 +3040,181 @@ func TestRedisClient_ExecuteUnknownCommand(t *testing.T) {
 		client.Close()
 	}()
 
+	otherClient := redis.NewClient(&redis.Options{Addr: fmt.Sprintf("%s:%d", redisIp, redisPort)})
+	defer otherClient.Close()
+	firstSubscription := otherClient.Subscribe(context.Background(), "news.sport", "news.weather")
+	defer firstSubscription.Close()
+	_, err = firstSubscription.Receive(context.Background())
+	assert.Nil(t, err)
+	secondSubscription := otherClient.Subscribe(context.Background(), "news.sport", "alerts")
+	defer secondSubscription.Close()
+	_, err = secondSubscription.Receive(context.Background())
+	assert.Nil(t, err)
+	channels := make(chan datasource.DataBatch, 1)
+
 	// when
-	_, err = client.ExecuteCommand([]interface{}{"AN_UNKNOWN_COMMAND", "AN_UNKNOWN_ARG"}, "")
+	actionStatus, err := client.ListChannels(context.Background(), datasource.Filter{Glob: "news.*"}, channels)
 
 	// then
-	assert.Contains(t, err.Error(), "ERR unknown command")
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Completed, actionStatus)
+	result := <-channels
+	assert.Equal(t, uint64(2), result.Size)
+	assert.Equal(t, datasource.ChannelNode{Path: "news.sport", Type: datasource.EntryPointTypesAsString[datasource.Channel], Subscribers: 2}, result.Data[0])
+	assert.Equal(t, datasource.ChannelNode{Path: "news.weather", Type: datasource.EntryPointTypesAsString[datasource.Channel], Subscribers: 1}, result.Data[1])
 }
 
-func TestRedisClient_GetContentForStream(t *testing.T) {
-	// TODO
+func TestRedisClient_Subscribe(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	data := make(chan datasource.DataBatch, 10)
+
+	// when
+	actionStatus, err := client.Subscribe(ctx, "news.*", true, data)
+	client.client.Publish(context.Background(), "news.sport", "goal")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	result := <-data
+	assert.Equal(t, uint64(1), result.Size)
+	message := result.Data[0].(ChannelMessage)
+	assert.Equal(t, "news.sport", message.Channel)
+	assert.Equal(t, "news.*", message.Pattern)
+	assert.Equal(t, "goal", message.Payload)
+
+	// when
+	cancel()
+
+	// then
+	_, open := <-data
+	assert.False(t, open, "The channel should be closed once the context is cancelled")
 }
 
-func TestRedisClient_Consume(t *testing.T) {
-	// TODO
+func TestRedisClient_Watch(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.ConfigSet(context.Background(), "notify-keyspace-events", "")
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.ConfigSet(context.Background(), "notify-keyspace-events", "KA")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan datasource.DataBatch, 10)
+
+	// when
+	actionStatus, err := client.Watch(ctx, "session", events)
+	client.client.Set(context.Background(), "other:1", "value", -1)
+	client.client.Set(context.Background(), "session:1", "value", -1)
+	client.client.Del(context.Background(), "session:1")
+	client.client.Set(context.Background(), "session:2", "value", time.Millisecond)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, datasource.Moved, actionStatus)
+	event := (<-events).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "session:1", event.Key)
+	assert.Equal(t, KeyUpdated, event.Event)
+	assert.Equal(t, "set", event.Command)
+	event = (<-events).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "session:1", event.Key)
+	assert.Equal(t, KeyDeleted, event.Event)
+	assert.Equal(t, "del", event.Command)
+	event = (<-events).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "session:2", event.Key)
+	assert.Equal(t, "set", event.Command)
+	// The event of the TTL.
+	event = (<-events).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "expire", event.Command)
+	event = (<-events).Data[0].(KeyspaceEvent)
+	assert.Equal(t, "session:2", event.Key)
+	assert.Equal(t, KeyExpired, event.Event)
+
+	// when
+	cancel()
+
+	// then
+	_, open := <-events
+	assert.False(t, open, "The channel should be closed once the context is cancelled")
+}
+
+func TestRedisClient_WatchWithDisabledNotifications(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+
+	client.client.ConfigSet(context.Background(), "notify-keyspace-events", "")
+	events := make(chan datasource.DataBatch, 10)
+
+	// when
+	_, err = client.Watch(context.Background(), "session", events)
+
+	// then
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "notify-keyspace-events")
+}
+
+func TestKeyspaceEventKind(t *testing.T) {
+	assert.Equal(t, KeyUpdated, keyspaceEventKind("hset"))
+	assert.Equal(t, KeyDeleted, keyspaceEventKind("del"))
+	assert.Equal(t, KeyDeleted, keyspaceEventKind("rename_from"))
+	assert.Equal(t, KeyCreated, keyspaceEventKind("rename_to"))
+	assert.Equal(t, KeyExpired, keyspaceEventKind("expired"))
+}
+
+func TestEscapeGlob(t *testing.T) {
+	assert.Equal(t, "session:\\*\\?\\[1\\]", escapeGlob("session:*?[1]"))
+}
+
+func TestRedisClient_Conformance(t *testing.T) {
+	conformance.Run(t, conformance.Suite{
+		Open: func(t *testing.T, readOnly bool) datasource.DataSource {
+			client := RedisClient{
+				datasource: &datasource.DataSourceDescriptor{
+					Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+					ReadOnly:  readOnly,
+				},
+			}
+			err := client.Open()
+			assert.Nil(t, err)
+			t.Cleanup(func() {
+				client.client.FlushAll(context.Background())
+				client.Close()
+			})
+			return &client
+		},
+	})
 }
 
 func EqualUnorderedSlices(t *testing.T, actual, expected []interface{}) {
@@ -1512,3 +3234,245 @@ func EqualUnorderedSlices(t *testing.T, actual, expected []interface{}) {
 		}
 	}
 }
+
+func TestRedisClient_GetSortedSetRange(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.ZAdd(context.Background(), "scores",
+		redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"}, redis.Z{Score: 3, Member: "c"},
+		redis.Z{Score: 3, Member: "d"}, redis.Z{Score: 5, Member: "e"})
+	// The lexicographic ranges require members with the same score.
+	client.client.ZAdd(context.Background(), "names",
+		redis.Z{Score: 0, Member: "alpha"}, redis.Z{Score: 0, Member: "bravo"},
+		redis.Z{Score: 0, Member: "charlie"}, redis.Z{Score: 0, Member: "delta"})
+
+	values := func(score float64, members ...string) SortedSetValues {
+		return SortedSetValues{Score: score, Values: members}
+	}
+	tests := []struct {
+		name     string
+		key      datasource.EntryPoint
+		rangeOf  datasource.SortedSetRange
+		expected []interface{}
+	}{
+		{"ranks", "scores", datasource.SortedSetRange{Start: "0", Stop: "1"},
+			[]interface{}{values(1, "a"), values(2, "b")}},
+		{"reverse ranks", "scores", datasource.SortedSetRange{Start: "0", Stop: "1", Reverse: true},
+			[]interface{}{values(5, "e"), values(3, "d")}},
+		{"negative ranks", "scores", datasource.SortedSetRange{By: datasource.ByRank, Start: "-2", Stop: "-1"},
+			[]interface{}{values(3, "d"), values(5, "e")}},
+		{"reverse negative ranks", "scores", datasource.SortedSetRange{By: datasource.ByRank, Start: "-2", Stop: "-1", Reverse: true},
+			[]interface{}{values(2, "b"), values(1, "a")}},
+		{"negative rank until the end", "scores", datasource.SortedSetRange{Start: "-3"},
+			[]interface{}{values(3, "c", "d"), values(5, "e")}},
+		{"reverse negative rank until the end", "scores", datasource.SortedSetRange{Start: "-3", Reverse: true},
+			[]interface{}{values(3, "c"), values(2, "b"), values(1, "a")}},
+		{"scores with exclusive lower bound", "scores", datasource.SortedSetRange{By: datasource.ByScore, Start: "(1", Stop: "3"},
+			[]interface{}{values(2, "b"), values(3, "c", "d")}},
+		{"reverse scores with exclusive lower bound", "scores", datasource.SortedSetRange{By: datasource.ByScore, Start: "(1", Stop: "3", Reverse: true},
+			[]interface{}{values(3, "d", "c"), values(2, "b")}},
+		{"scores from -inf with exclusive upper bound", "scores", datasource.SortedSetRange{By: datasource.ByScore, Start: "-inf", Stop: "(3"},
+			[]interface{}{values(1, "a"), values(2, "b")}},
+		{"reverse scores from -inf with exclusive upper bound", "scores", datasource.SortedSetRange{By: datasource.ByScore, Start: "-inf", Stop: "(3", Reverse: true},
+			[]interface{}{values(2, "b"), values(1, "a")}},
+		{"scores until +inf", "scores", datasource.SortedSetRange{By: datasource.ByScore, Start: "(2", Stop: "+inf"},
+			[]interface{}{values(3, "c", "d"), values(5, "e")}},
+		{"reverse scores until +inf", "scores", datasource.SortedSetRange{By: datasource.ByScore, Start: "(2", Stop: "+inf", Reverse: true},
+			[]interface{}{values(5, "e"), values(3, "d", "c")}},
+		{"scores with offset and count", "scores", datasource.SortedSetRange{By: datasource.ByScore, Offset: 1, Count: 2},
+			[]interface{}{values(2, "b"), values(3, "c")}},
+		{"reverse scores with offset and count", "scores", datasource.SortedSetRange{By: datasource.ByScore, Offset: 1, Count: 2, Reverse: true},
+			[]interface{}{values(3, "d", "c")}},
+		{"members with inclusive and exclusive bounds", "names", datasource.SortedSetRange{By: datasource.ByLex, Start: "[bravo", Stop: "(delta"},
+			[]interface{}{values(0, "bravo", "charlie")}},
+		{"reverse members with inclusive and exclusive bounds", "names", datasource.SortedSetRange{By: datasource.ByLex, Start: "[bravo", Stop: "(delta", Reverse: true},
+			[]interface{}{values(0, "charlie", "bravo")}},
+		{"members from the first one", "names", datasource.SortedSetRange{By: datasource.ByLex, Start: "-", Stop: "[bravo"},
+			[]interface{}{values(0, "alpha", "bravo")}},
+		{"reverse members from the first one", "names", datasource.SortedSetRange{By: datasource.ByLex, Start: "-", Stop: "[bravo", Reverse: true},
+			[]interface{}{values(0, "bravo", "alpha")}},
+		{"members until the last one", "names", datasource.SortedSetRange{By: datasource.ByLex, Start: "(alpha", Stop: "+"},
+			[]interface{}{values(0, "bravo", "charlie", "delta")}},
+		{"reverse members until the last one", "names", datasource.SortedSetRange{By: datasource.ByLex, Start: "(alpha", Stop: "+", Reverse: true},
+			[]interface{}{values(0, "delta", "charlie", "bravo")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := make(chan datasource.DataBatch, 1)
+
+			// when
+			status, err := client.GetSortedSetRange(context.Background(), test.key, test.rangeOf, data)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, datasource.Completed, status)
+			result := <-data
+			assert.Equal(t, test.expected, result.Data)
+			assert.Equal(t, uint64(len(test.expected)), result.Size)
+		})
+	}
+}
+
+func TestRedisClient_GetSortedSetRangeWithInvalidArguments(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	client.client.ZAdd(context.Background(), "scores", redis.Z{Score: 1, Member: "a"})
+	client.client.Set(context.Background(), "my-string", "value", -1)
+
+	for _, test := range []struct {
+		key     datasource.EntryPoint
+		rangeOf datasource.SortedSetRange
+	}{
+		{"scores", datasource.SortedSetRange{Start: "first"}},
+		{"scores", datasource.SortedSetRange{By: "other"}},
+		{"my-string", datasource.SortedSetRange{}},
+		{"unknown", datasource.SortedSetRange{}},
+	} {
+		// when
+		_, err := client.GetSortedSetRange(context.Background(), test.key, test.rangeOf, make(chan datasource.DataBatch, 1))
+
+		// then
+		assert.NotNil(t, err, "%s %v", test.key, test.rangeOf)
+	}
+}
+
+func TestRedisClient_GetContentWithBinaryValues(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	binary := []byte{0xff, 0x00, 0xfe, 0x01}
+	compressed := gzipped(t, "my-value")
+	client.client.Set(context.Background(), "binary:value", binary, -1)
+	client.client.Set(context.Background(), "binary:gzip", compressed, -1)
+	client.client.SAdd(context.Background(), "binary:set", binary)
+	client.client.HSet(context.Background(), "binary:hash", "my-field", binary)
+	client.client.XAdd(context.Background(), &redis.XAddArgs{Stream: "binary:stream", ID: "1-1", Values: map[string]interface{}{"my-field": binary}})
+	encodedBinary := base64.StdEncoding.EncodeToString(binary)
+
+	tests := []struct {
+		key      datasource.EntryPoint
+		expected string
+	}{
+		{"binary:value", `[{"raw": "` + encodedBinary + `", "encoding": "binary"}]`},
+		{"binary:gzip", `[{"raw": "` + base64.StdEncoding.EncodeToString(compressed) + `", "encoding": "gzip"}]`},
+		{"binary:set", `[{"raw": "` + encodedBinary + `", "encoding": "binary"}]`},
+		{"binary:hash", `[{"key": "my-field", "value": {"raw": "` + encodedBinary + `", "encoding": "binary"}}]`},
+		{"binary:stream", `[{"id": "1-1", "timestamp": "` + time.Unix(0, int64(time.Millisecond)).Format(time.RFC3339Nano) + `", "fields": {"my-field": {"raw": "` + encodedBinary + `", "encoding": "binary"}}}]`},
+	}
+	for _, test := range tests {
+		t.Run(string(test.key), func(t *testing.T) {
+			data := make(chan datasource.DataBatch, 1)
+
+			// when
+			_, err := client.GetContent(context.Background(), test.key, datasource.Filter{}, data)
+
+			// then
+			assert.Nil(t, err)
+			// The bytes are kept as is and sent in base64 with their encoding, without decoders.
+			result, err := json.Marshal(decoding.Pipeline(nil).DecodeBatch(<-data).Data)
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.expected, string(result))
+		})
+	}
+}
+
+func TestRedisClient_GetContentDecodedByPrefix(t *testing.T) {
+	// given
+	client := RedisClient{
+		datasource: &datasource.DataSourceDescriptor{
+			Bootstrap: fmt.Sprintf("redis://%s:%d", redisIp, redisPort),
+		},
+	}
+	err := client.Open()
+	assert.Nil(t, err)
+	defer func() {
+		client.client.FlushAll(context.Background())
+		client.Close()
+	}()
+	decoders, err := decoding.FromConfiguration(map[string]string{
+		"decoders":                 "base64",
+		"decoders[sessions:]":      "base64,gzip",
+		"decoders[sessions:json:]": "json",
+	})
+	assert.Nil(t, err)
+	client.client.Set(context.Background(), "users:1", base64.StdEncoding.EncodeToString([]byte("alice")), -1)
+	client.client.Set(context.Background(), "sessions:1", base64.StdEncoding.EncodeToString(gzipped(t, "my-session")), -1)
+	client.client.HSet(context.Background(), "sessions:2", "my-field", base64.StdEncoding.EncodeToString(gzipped(t, "my-value")))
+	client.client.Set(context.Background(), "sessions:json:1", `{"a":1}`, -1)
+
+	tests := []struct {
+		key      datasource.EntryPoint
+		expected interface{}
+	}{
+		// The pipeline of the data source applies when no prefix matches.
+		{"users:1", "alice"},
+		{"sessions:1", "my-session"},
+		{"sessions:2", "my-value"},
+		// The longest prefix has precedence.
+		{"sessions:json:1", "{\n  \"a\": 1\n}"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.key), func(t *testing.T) {
+			data := make(chan datasource.DataBatch, 1)
+
+			// when
+			_, err := client.GetContent(context.Background(), test.key, datasource.Filter{}, data)
+
+			// then
+			assert.Nil(t, err)
+			result := decoders.PipelineOf(string(test.key)).DecodeBatch(<-data)
+			assert.Len(t, result.Data, 1)
+			value, isValue := result.Data[0].(decoding.Value)
+			if !isValue {
+				// The fields of the hashes are decoded apart.
+				encoded, err := json.Marshal(result.Data[0])
+				assert.Nil(t, err)
+				var hashValue struct {
+					Value decoding.Value `json:"value"`
+				}
+				assert.Nil(t, json.Unmarshal(encoded, &hashValue))
+				value = hashValue.Value
+			}
+			assert.Empty(t, value.Error)
+			assert.Equal(t, test.expected, value.Decoded)
+		})
+	}
+}
+
+func gzipped(t *testing.T, value string) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(value))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return buffer.Bytes()
+}
@@ -0,0 +1,33 @@
+package redis
+
+import "strings"
+
+// Count of the hash slots of a Redis cluster.
+const slotCount = 16384
+
+// keySlot returns the hash slot of the key in a Redis cluster, considering the hash tags.
+// See https://redis.io/docs/reference/cluster-spec/#hash-tags
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % slotCount)
+}
+
+// crc16 computes the CRC16-CCITT (XMODEM) checksum used by Redis to distribute the keys.
+func crc16(key string) uint16 {
+	crc := uint16(0)
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc = crc << 1
+			}
+		}
+	}
+	return crc
+}
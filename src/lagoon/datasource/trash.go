@@ -0,0 +1,249 @@
+package datasource
+
+import (
+	"errors"
+	"fmt"
+	"github.com/twinj/uuid"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default duration during which the entries of the trash can be restored.
+const DefaultTrashRetention = 24 * time.Hour
+
+// Default maximal total size of the payloads kept in the trash, in bytes.
+const DefaultTrashMaxSize = 256 * 1024 * 1024
+
+// TrashEntry is a backup of an entry point, saved before it was deleted or changed.
+type TrashEntry struct {
+	Id         string     `json:"id"`
+	EntryPoint EntryPoint `json:"entrypoint"`
+	Operation  string     `json:"operation"`
+	// Serialized value of the entry point, specific to the vendor.
+	Payload []byte `json:"-"`
+	Size    int    `json:"size"`
+	// Time when the entry point was expiring, if any.
+	ExpireAt *time.Time `json:"expireAt,omitempty"`
+	SavedAt  time.Time  `json:"savedAt"`
+}
+
+// Trash keeps the backups of the entry points in memory during the retention, the oldest being removed
+// when the maximal size is reached.
+type Trash struct {
+	retention time.Duration
+	maxSize   int
+	// elementChanges is true when the entry points are saved before some of their elements are added, changed or removed,
+	// and not only before they are deleted or replaced.
+	elementChanges bool
+	size           int
+	// Size reserved for the backups of the operations in progress.
+	reserved int
+	entries  []TrashEntry
+	mutex    sync.Mutex
+}
+
+// TrashReservation is the space reserved in the trash for the backups of an operation, which cannot evict each other.
+type TrashReservation struct {
+	trash     *Trash
+	remaining int
+}
+
+func NewTrash(retention time.Duration, maxSize int) *Trash {
+	return &Trash{
+		retention: retention,
+		maxSize:   maxSize,
+	}
+}
+
+// NewTrashFromConfiguration creates the trash from the configuration of a data source, with the
+// keys trashRetention (a duration, 0 disabling the trash), trashMaxSize (in bytes) and trashElementChanges
+// (false by default, true enabling the backups before the elements of an entry point are added, changed or removed).
+// It returns nil when the trash is disabled.
+func NewTrashFromConfiguration(configuration map[string]string) (*Trash, error) {
+	retention := DefaultTrashRetention
+	maxSize := DefaultTrashMaxSize
+	if value, ok := configuration["trashRetention"]; ok {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("the retention of the trash '%s' is not valid: %s", value, err.Error()))
+		}
+		retention = duration
+	}
+	if value, ok := configuration["trashMaxSize"]; ok {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("the maximal size of the trash '%s' is not valid: %s", value, err.Error()))
+		}
+		maxSize = size
+	}
+	elementChanges := false
+	if value, ok := configuration["trashElementChanges"]; ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("the backup of the element changes '%s' is not valid: %s", value, err.Error()))
+		}
+		elementChanges = enabled
+	}
+	if retention <= 0 || maxSize <= 0 {
+		return nil, nil
+	}
+	trash := NewTrash(retention, maxSize)
+	trash.elementChanges = elementChanges
+	return trash, nil
+}
+
+// KeepsElementChanges returns true when the entry points have to be saved before some of their elements
+// are added, changed or removed.
+func (t *Trash) KeepsElementChanges() bool {
+	return t.elementChanges
+}
+
+// Save adds the entry to the trash and returns its ID. It fails when the entry is larger than the maximal
+// size of the trash, which could not keep it.
+func (t *Trash) Save(entry TrashEntry) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry.Size = len(entry.Payload)
+	if entry.Size > t.maxSize-t.reserved {
+		return "", errors.New(fmt.Sprintf("the backup of %s (%d bytes) exceeds the maximal size of the trash (%d bytes)", entry.EntryPoint, entry.Size, t.maxSize-t.reserved))
+	}
+	return t.add(entry), nil
+}
+
+// add adds the entry to the trash, evicting the oldest ones exceeding the maximal size, and returns its ID.
+// The mutex has to be acquired by the caller.
+func (t *Trash) add(entry TrashEntry) string {
+	entry.Id = uuid.NewV4().String()
+	if entry.SavedAt.IsZero() {
+		entry.SavedAt = time.Now()
+	}
+	t.entries = append(t.entries, entry)
+	t.size += entry.Size
+	t.purge()
+	return entry.Id
+}
+
+// Reserve reserves size bytes for the backups of an operation, evicting the oldest entries when needed,
+// so that the backups saved with the reservation are not evicted by each other. It fails when the size exceeds
+// the maximal size of the trash. The reservation has to be released once the operation is complete.
+func (t *Trash) Reserve(size int) (*TrashReservation, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if size > t.maxSize-t.reserved {
+		return nil, errors.New(fmt.Sprintf("the backups (%d bytes) exceed the available size of the trash (%d bytes)", size, t.maxSize-t.reserved))
+	}
+	t.reserved += size
+	t.purge()
+	return &TrashReservation{trash: t, remaining: size}, nil
+}
+
+// Save adds the entry to the trash within the reservation and returns its ID. Since the reserved size
+// is only an estimate, the entry exceeding the remaining size of the reservation is saved like with Trash.Save,
+// and fails only when it is larger than the trash could keep.
+func (r *TrashReservation) Save(entry TrashEntry) (string, error) {
+	t := r.trash
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry.Size = len(entry.Payload)
+	if entry.Size <= r.remaining {
+		r.remaining -= entry.Size
+		t.reserved -= entry.Size
+	} else if entry.Size > t.maxSize-t.reserved {
+		return "", errors.New(fmt.Sprintf("the backup of %s (%d bytes) exceeds the maximal size of the trash (%d bytes)", entry.EntryPoint, entry.Size, t.maxSize-t.reserved))
+	}
+	return t.add(entry), nil
+}
+
+// Release releases the size of the reservation, which was not used.
+func (r *TrashReservation) Release() {
+	r.trash.mutex.Lock()
+	defer r.trash.mutex.Unlock()
+
+	r.trash.reserved -= r.remaining
+	r.remaining = 0
+}
+
+// TakeOver moves the entries of the previous trash into this one, when the data source of the previous trash
+// is replaced. The entries exceeding the retention or the maximal size of this trash are dropped.
+func (t *Trash) TakeOver(previous *Trash) {
+	previous.mutex.Lock()
+	entries := previous.entries
+	previous.entries = nil
+	previous.size = 0
+	previous.mutex.Unlock()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	merged := make([]TrashEntry, 0, len(entries)+len(t.entries))
+	merged = append(merged, entries...)
+	merged = append(merged, t.entries...)
+	// The oldest entries come first, to be the first ones purged.
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].SavedAt.Before(merged[j].SavedAt)
+	})
+	for _, entry := range entries {
+		t.size += entry.Size
+	}
+	t.entries = merged
+	t.purge()
+}
+
+// List returns the entries of the trash, the most recent first.
+func (t *Trash) List() []TrashEntry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.purge()
+	result := make([]TrashEntry, len(t.entries))
+	copy(result, t.entries)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].SavedAt.After(result[j].SavedAt)
+	})
+	return result
+}
+
+// Get returns the entry of the trash with the ID.
+func (t *Trash) Get(id string) (TrashEntry, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.purge()
+	for _, entry := range t.entries {
+		if entry.Id == id {
+			return entry, true
+		}
+	}
+	return TrashEntry{}, false
+}
+
+// Remove removes the entry with the ID from the trash.
+func (t *Trash) Remove(id string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i, entry := range t.entries {
+		if entry.Id == id {
+			t.size -= entry.Size
+			t.entries = append(t.entries[:i], t.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// purge removes the entries older than the retention and the oldest ones exceeding the maximal size,
+// the reserved size included. The mutex has to be acquired by the caller.
+func (t *Trash) purge() {
+	limit := time.Now().Add(-t.retention)
+	first := 0
+	for first < len(t.entries) && (t.entries[first].SavedAt.Before(limit) || t.size+t.reserved > t.maxSize) {
+		t.size -= t.entries[first].Size
+		first++
+	}
+	t.entries = t.entries[first:]
+}
@@ -0,0 +1,199 @@
+package datasource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTrashSaveAndGet(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 100)
+
+	// when
+	id, err := trash.Save(TrashEntry{EntryPoint: "my-key", Operation: "delete", Payload: []byte("payload")})
+
+	// then
+	assert.Nil(t, err)
+	entry, exists := trash.Get(id)
+	assert.True(t, exists)
+	assert.Equal(t, EntryPoint("my-key"), entry.EntryPoint)
+	assert.Equal(t, 7, entry.Size)
+	assert.False(t, entry.SavedAt.IsZero())
+
+	// when
+	trash.Remove(id)
+
+	// then
+	_, exists = trash.Get(id)
+	assert.False(t, exists)
+}
+
+func TestTrashListMostRecentFirst(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 100)
+	now := time.Now()
+	trash.Save(TrashEntry{EntryPoint: "first", Payload: []byte("1"), SavedAt: now.Add(-time.Minute)})
+	trash.Save(TrashEntry{EntryPoint: "second", Payload: []byte("2"), SavedAt: now})
+
+	// when
+	entries := trash.List()
+
+	// then
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, EntryPoint("second"), entries[0].EntryPoint)
+	assert.Equal(t, EntryPoint("first"), entries[1].EntryPoint)
+}
+
+func TestTrashPurgeExpiredEntries(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 100)
+	trash.Save(TrashEntry{EntryPoint: "expired", Payload: []byte("1"), SavedAt: time.Now().Add(-2 * time.Hour)})
+	trash.Save(TrashEntry{EntryPoint: "kept", Payload: []byte("2")})
+
+	// when
+	entries := trash.List()
+
+	// then
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, EntryPoint("kept"), entries[0].EntryPoint)
+}
+
+func TestTrashPurgeOldestEntriesOverMaxSize(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 10)
+	trash.Save(TrashEntry{EntryPoint: "oldest", Payload: []byte("123456")})
+
+	// when
+	trash.Save(TrashEntry{EntryPoint: "newest", Payload: []byte("123456")})
+
+	// then
+	entries := trash.List()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, EntryPoint("newest"), entries[0].EntryPoint)
+}
+
+func TestTrashRefusesEntryOverMaxSize(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 10)
+	trash.Save(TrashEntry{EntryPoint: "kept", Payload: []byte("123456")})
+
+	// when
+	id, err := trash.Save(TrashEntry{EntryPoint: "too-large", Payload: []byte("12345678901")})
+
+	// then
+	assert.NotNil(t, err)
+	assert.Equal(t, "the backup of too-large (11 bytes) exceeds the maximal size of the trash (10 bytes)", err.Error())
+	assert.Empty(t, id)
+	// The previous entries are not evicted.
+	entries := trash.List()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, EntryPoint("kept"), entries[0].EntryPoint)
+}
+
+func TestTrashReservationKeepsTheBackupsOfTheOperation(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 10)
+	trash.Save(TrashEntry{EntryPoint: "oldest", Payload: []byte("1234")})
+
+	// when
+	reservation, err := trash.Reserve(8)
+
+	// then
+	assert.Nil(t, err)
+	// The oldest entry is evicted to make room for the reservation.
+	assert.Empty(t, trash.List())
+
+	// when
+	_, err = reservation.Save(TrashEntry{EntryPoint: "first", Payload: []byte("1234")})
+	assert.Nil(t, err)
+	_, err = reservation.Save(TrashEntry{EntryPoint: "second", Payload: []byte("1234")})
+	assert.Nil(t, err)
+	reservation.Release()
+
+	// then
+	entries := trash.List()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, 0, trash.reserved)
+}
+
+func TestTrashReservationSavesTheBackupsBeyondTheReservedSize(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 10)
+	reservation, err := trash.Reserve(4)
+	assert.Nil(t, err)
+
+	// when
+	_, err = reservation.Save(TrashEntry{EntryPoint: "first", Payload: []byte("1234")})
+	assert.Nil(t, err)
+	// The reservation is exhausted, the backup is saved like outside of it.
+	_, err = reservation.Save(TrashEntry{EntryPoint: "second", Payload: []byte("12")})
+	assert.Nil(t, err)
+	_, err = reservation.Save(TrashEntry{EntryPoint: "third", Payload: []byte("12345678901")})
+
+	// then
+	assert.NotNil(t, err)
+	assert.Equal(t, "the backup of third (11 bytes) exceeds the maximal size of the trash (10 bytes)", err.Error())
+	assert.Equal(t, 2, len(trash.List()))
+	reservation.Release()
+	assert.Equal(t, 0, trash.reserved)
+}
+
+func TestTrashRefusesReservationOverMaxSize(t *testing.T) {
+	// given
+	trash := NewTrash(time.Hour, 10)
+	trash.Save(TrashEntry{EntryPoint: "kept", Payload: []byte("123456")})
+
+	// when
+	reservation, err := trash.Reserve(11)
+
+	// then
+	assert.NotNil(t, err)
+	assert.Nil(t, reservation)
+	assert.Equal(t, 1, len(trash.List()))
+}
+
+func TestTrashTakesOverThePreviousEntries(t *testing.T) {
+	// given
+	previous := NewTrash(time.Hour, 10)
+	previous.Save(TrashEntry{EntryPoint: "oldest", Payload: []byte("1234"), SavedAt: time.Now().Add(-time.Minute)})
+	previous.Save(TrashEntry{EntryPoint: "older", Payload: []byte("1234"), SavedAt: time.Now().Add(-time.Second)})
+	trash := NewTrash(time.Hour, 6)
+	trash.Save(TrashEntry{EntryPoint: "recent", Payload: []byte("12")})
+
+	// when
+	trash.TakeOver(previous)
+
+	// then
+	// The oldest entry exceeds the maximal size of the new trash.
+	entries := trash.List()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, EntryPoint("recent"), entries[0].EntryPoint)
+	assert.Equal(t, EntryPoint("older"), entries[1].EntryPoint)
+	assert.Equal(t, 6, trash.size)
+	assert.Empty(t, previous.List())
+}
+
+func TestNewTrashFromConfiguration(t *testing.T) {
+	trash, err := NewTrashFromConfiguration(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultTrashRetention, trash.retention)
+	assert.Equal(t, DefaultTrashMaxSize, trash.maxSize)
+	assert.False(t, trash.KeepsElementChanges())
+
+	trash, err = NewTrashFromConfiguration(map[string]string{"trashRetention": "30m", "trashMaxSize": "1024", "trashElementChanges": "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Minute, trash.retention)
+	assert.Equal(t, 1024, trash.maxSize)
+	assert.True(t, trash.KeepsElementChanges())
+
+	trash, err = NewTrashFromConfiguration(map[string]string{"trashRetention": "0"})
+	assert.Nil(t, err)
+	assert.Nil(t, trash)
+
+	_, err = NewTrashFromConfiguration(map[string]string{"trashRetention": "one day"})
+	assert.NotNil(t, err)
+
+	_, err = NewTrashFromConfiguration(map[string]string{"trashElementChanges": "sometimes"})
+	assert.NotNil(t, err)
+}
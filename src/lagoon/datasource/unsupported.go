@@ -0,0 +1,134 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsupportedOperation is returned by the data sources for the operations their vendor does not provide.
+var ErrUnsupportedOperation = errors.New("the operation is not supported by the data source")
+
+// UnsupportedOperations can be embedded by the implementations of DataSource, to reject all the operations
+// they do not override with ErrUnsupportedOperation.
+type UnsupportedOperations struct {
+}
+
+func (u UnsupportedOperations) GetContentPage(context.Context, EntryPoint, Filter, Page) (ContentPage, error) {
+	return ContentPage{}, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) GetStreamInfos(context.Context, EntryPoint, int64) (StreamInfos, error) {
+	return StreamInfos{}, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) GetStreamRange(context.Context, EntryPoint, StreamRange, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) GetSortedSetRange(context.Context, EntryPoint, SortedSetRange, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) AnalyzeEntryPoints(context.Context, *Analysis) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) DeleteEntrypoint(context.Context, EntryPoint) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) DeleteEntrypointChildren(context.Context, EntryPoint, uint, chan<- error) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) PreviewEntrypointChildrenDeletion(context.Context, EntryPoint, int) (DeletionPreview, error) {
+	return DeletionPreview{}, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) ListTrashEntries(context.Context) ([]TrashEntry, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) RestoreTrashEntry(context.Context, string, bool) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) RenameEntrypoint(context.Context, EntryPoint, EntryPoint) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) MoveEntrypointTree(context.Context, EntryPoint, EntryPoint, bool, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) SetExpiry(context.Context, EntryPoint, Expiry) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) SetChildrenExpiry(context.Context, EntryPoint, Expiry, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) SetValue(context.Context, EntryPoint, string) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) AddSetMembers(context.Context, EntryPoint, []string) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) RemoveSetMembers(context.Context, EntryPoint, []string) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) AddSortedSetMembers(context.Context, EntryPoint, []ScoredMember) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) SetHashFields(context.Context, EntryPoint, map[string]string) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) DeleteHashFields(context.Context, EntryPoint, []string) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) PushListValues(context.Context, EntryPoint, []string, bool) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) SetListValue(context.Context, EntryPoint, int64, string) error {
+	return ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) RemoveListValue(context.Context, EntryPoint, string, int64) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) AddStreamMessage(context.Context, EntryPoint, string, map[string]interface{}) (string, error) {
+	return "", ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) DeleteStreamMessages(context.Context, EntryPoint, []string) (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) Consume(context.Context, EntryPoint, chan<- DataBatch, Filter, bool) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) ListChannels(context.Context, Filter, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) Subscribe(context.Context, EntryPoint, bool, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) Watch(context.Context, EntryPoint, chan<- DataBatch) (ActionStatus, error) {
+	return None, ErrUnsupportedOperation
+}
+
+func (u UnsupportedOperations) ExecuteCommand(context.Context, []interface{}, string) (interface{}, error) {
+	return nil, ErrUnsupportedOperation
+}
@@ -3,15 +3,19 @@ module lagoon
 go 1.12
 
 require (
+	github.com/IBM/sarama v1.43.3
 	github.com/gin-contrib/cors v1.3.0
 	github.com/gin-gonic/gin v1.4.0
 	github.com/go-redis/redis v6.15.6+incompatible
 	github.com/golang/mock v1.3.1
+	github.com/golang/protobuf v1.3.1
 	github.com/gorilla/websocket v1.4.1
-	github.com/redis/go-redis/v9 v9.0.3 // indirect
+	github.com/klauspost/compress v1.17.9
+	github.com/rabbitmq/amqp091-go v1.10.0
+	github.com/redis/go-redis/v9 v9.0.3
 	github.com/sirupsen/logrus v1.9.0
 	github.com/spf13/cobra v0.0.5
-	github.com/stretchr/testify v1.8.2
+	github.com/stretchr/testify v1.9.0
 	github.com/testcontainers/testcontainers-go v0.0.8
 	github.com/twinj/uuid v1.0.0
 	github.com/ugorji/go/codec v1.1.7
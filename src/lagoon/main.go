@@ -18,6 +18,9 @@ import (
 	"syscall"
 	"time"
 
+	_ "lagoon/datasource/kafka"
+	_ "lagoon/datasource/memory"
+	_ "lagoon/datasource/rabbitmq"
 	_ "lagoon/datasource/redis"
 )
 
@@ -49,10 +52,14 @@ var rootCmd = &cobra.Command{
 				log.Fatalf("error: %v", err)
 			}
 
+			if configuration.PendingStreamExpiry != nil {
+				api.SetPendingWebSocketExpiry(*configuration.PendingStreamExpiry)
+			}
 			for _, ds := range configuration.Datasources {
 				log.Printf("Creating data source %s", ds.Name)
-				dsInfos, _ := api.CreateDataSourceFromDescriptor(ds, true)
-				api.DataSourcesHeaders[dsInfos.Id] = dsInfos
+				if _, err := api.CreateDataSourceFromDescriptor(ds); err != nil {
+					log.Printf("ERROR while creating the data source %s: %s", ds.Name, err.Error())
+				}
 			}
 		}
 
@@ -77,7 +84,7 @@ var rootCmd = &cobra.Command{
 		}()
 
 		// Waiting for a signal to stop the server.
-		quit := make(chan os.Signal)
+		quit := make(chan os.Signal, 1)
 		// SIGKILL but can't be catch and is therefore ignored.
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 		<-quit
@@ -107,6 +114,8 @@ var (
 	configuration struct {
 		Port        int                               `yaml:"port"`
 		Datasources []datasource.DataSourceDescriptor `yaml:"datasources"`
+		// PendingStreamExpiry is the duration after which the web-sockets whose client did not connect expire.
+		PendingStreamExpiry *time.Duration `yaml:"pendingStreamExpiry"`
 	}
 
 	debug *bool
@@ -164,23 +173,149 @@ func setupRouter() *gin.Engine {
 		api.GetEntryPointContent(c)
 	})
 
+	r.GET(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/stream", func(c *gin.Context) {
+		api.GetEntryPointStreamRange(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/zset/range", func(c *gin.Context) {
+		api.GetSortedSetRange(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/consume", func(c *gin.Context) {
+		api.ConsumeEntryPoint(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/watch", func(c *gin.Context) {
+		api.WatchEntryPoint(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/stream/groups", func(c *gin.Context) {
+		api.GetEntryPointStreamInfos(c)
+	})
+
 	r.DELETE(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint", func(c *gin.Context) {
 		api.DeleteEntryPoint(c)
 	})
 
+	r.GET(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/children/preview", func(c *gin.Context) {
+		api.PreviewEntryPointChildrenDeletion(c)
+	})
+
 	r.DELETE(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/children", func(c *gin.Context) {
 		api.DeleteEntryPointChildren(c)
 	})
 
+	r.POST(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/rename", func(c *gin.Context) {
+		api.RenameEntryPoint(c)
+	})
+
+	r.POST(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/move", func(c *gin.Context) {
+		api.MoveEntryPointTree(c)
+	})
+
+	r.PUT(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/expiry", func(c *gin.Context) {
+		api.SetEntryPointExpiry(c)
+	})
+
+	r.PUT(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/children/expiry", func(c *gin.Context) {
+		api.SetEntryPointChildrenExpiry(c)
+	})
+
+	r.PUT(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/value", func(c *gin.Context) {
+		api.SetEntryPointValue(c)
+	})
+
+	r.POST(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/set/members", func(c *gin.Context) {
+		api.AddSetMembers(c)
+	})
+
+	r.DELETE(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/set/members", func(c *gin.Context) {
+		api.RemoveSetMembers(c)
+	})
+
+	r.POST(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/zset/members", func(c *gin.Context) {
+		api.AddSortedSetMembers(c)
+	})
+
+	r.PUT(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/hash/fields", func(c *gin.Context) {
+		api.SetHashFields(c)
+	})
+
+	r.DELETE(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/hash/fields", func(c *gin.Context) {
+		api.DeleteHashFields(c)
+	})
+
+	r.POST(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/list/values", func(c *gin.Context) {
+		api.PushListValues(c)
+	})
+
+	r.PUT(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/list/values/:index", func(c *gin.Context) {
+		api.SetListValue(c)
+	})
+
+	r.DELETE(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/list/values", func(c *gin.Context) {
+		api.RemoveListValue(c)
+	})
+
+	r.POST(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/stream/messages", func(c *gin.Context) {
+		api.AddStreamMessage(c)
+	})
+
+	r.DELETE(contextPath+"/data/:DataSourceId/entrypoint/:entrypoint/stream/messages", func(c *gin.Context) {
+		api.DeleteStreamMessages(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/trash", func(c *gin.Context) {
+		api.ListTrashEntries(c)
+	})
+
+	r.POST(contextPath+"/data/:DataSourceId/trash/:trashEntryId/restore", func(c *gin.Context) {
+		api.RestoreTrashEntry(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/channel", func(c *gin.Context) {
+		api.ListChannels(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/channel/:channel/subscribe", func(c *gin.Context) {
+		api.SubscribeChannel(c)
+	})
+
 	r.POST(contextPath+"/data/:DataSourceId/command", func(c *gin.Context) {
 		api.ExecuteCommand(c)
 	})
 
+	// Analyses of the entry points, running in background.
+	r.POST(contextPath+"/data/:DataSourceId/analysis", func(c *gin.Context) {
+		api.StartAnalysis(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/analysis", func(c *gin.Context) {
+		api.ListAnalyses(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/analysis/:analysisId", func(c *gin.Context) {
+		api.GetAnalysis(c)
+	})
+
+	r.GET(contextPath+"/data/:DataSourceId/analysis/:analysisId/stream", func(c *gin.Context) {
+		api.StreamAnalysis(c)
+	})
+
+	r.DELETE(contextPath+"/data/:DataSourceId/analysis/:analysisId", func(c *gin.Context) {
+		api.CancelAnalysis(c)
+	})
+
 	// Consume web-socket.
 	r.GET(contextPath+"/ws/:wsUuid", func(c *gin.Context) {
 		api.ReadChannelContentAndSendToWebSocket(c)
 	})
 
+	// List the pending and active web-sockets.
+	r.GET(contextPath+"/admin/streams", func(c *gin.Context) {
+		api.ListWebSockets(c)
+	})
+
 	r.Static(contextPath+"/ui", "./ui/dist")
 
 	r.GET(contextPath+"/", func(context *gin.Context) {
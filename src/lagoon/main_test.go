@@ -1,19 +1,75 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
 	"io/ioutil"
 	"lagoon/api"
 	"lagoon/datasource"
+	"lagoon/datasource/memory"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// createDataSource creates the data source of the descriptor through the API.
+func createDataSource(t *testing.T, router http.Handler, descriptor datasource.DataSourceDescriptor) {
+	body, _ := json.Marshal(descriptor)
+	req, _ := http.NewRequest("POST", contextPath+"/datasource", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+}
+
+// openMemoryDataSource declares the memory vendor and creates its data source my-datasource, seeded with the entry points
+// of the YAML fixture. The read-only flag and the configuration of the descriptor are kept.
+func openMemoryDataSource(t *testing.T, router http.Handler, fixture string, descriptor datasource.DataSourceDescriptor) {
+	// The vendors declared at initialization time may have been cleared by the previous tests.
+	datasource.DeclareImplementation(&memory.MemoryVendor{})
+	configuration := map[string]string{}
+	for key, value := range descriptor.Configuration {
+		configuration[key] = value
+	}
+	if fixture != "" {
+		dir, err := ioutil.TempDir("", "lagoon-fixture")
+		assert.Nil(t, err)
+		// The fixture is only read when the data source is opened.
+		defer os.RemoveAll(dir)
+		configuration["fixture"] = filepath.Join(dir, "fixture.yml")
+		assert.Nil(t, ioutil.WriteFile(configuration["fixture"], []byte(fixture), 0600))
+	}
+	descriptor.Id = "my-datasource"
+	descriptor.Vendor = "memory"
+	descriptor.Name = "test-memory"
+	descriptor.Bootstrap = "memory://test"
+	descriptor.Configuration = configuration
+	createDataSource(t, router, descriptor)
+}
+
+// openMockDataSource creates the data source my-datasource of a mock vendor, for the behaviors the memory vendor
+// cannot have, like the failures of a cluster.
+func openMockDataSource(t *testing.T, router http.Handler, ctrl *gomock.Controller) *datasource.MockDataSource {
+	ds := datasource.NewMockDataSource(ctrl)
+	vendor := datasource.NewMockVendor(ctrl)
+	datasource.DeclareImplementation(vendor)
+	vendor.EXPECT().Accept(gomock.Any()).Return(true).Times(1)
+	vendor.EXPECT().CreateDataSource(gomock.Any()).Return(ds, nil).Times(1)
+	ds.EXPECT().Open().Return(nil).Times(1)
+	// The data source is closed when the data sources are cleared, before the mocks are checked.
+	ds.EXPECT().Close().Times(1)
+	createDataSource(t, router, datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock", Bootstrap: "any:path"})
+	return ds
+}
+
 func TestRedirectionAtRoot(t *testing.T) {
 	router := setupRouter()
 	recorder := httptest.NewRecorder()
@@ -56,10 +112,10 @@ func TestCreateNewDataSourceListAndDelete(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	ctrl := gomock.NewController(t)
 	defer func() {
+		api.ClearDatasources()
 		ctrl.Finish()
 		defer recorder.Flush()
 		datasource.ClearVendors()
-		api.ClearDatasources()
 	}()
 
 	// This datasource is created and deleted.
@@ -67,9 +123,10 @@ func TestCreateNewDataSourceListAndDelete(t *testing.T) {
 	ds1.EXPECT().Open().Return(nil).Times(1)
 	ds1.EXPECT().Close().Times(1)
 
-	// This datasource is only created.
+	// This datasource is only created, and closed when the data sources are cleared.
 	ds2 := datasource.NewMockDataSource(ctrl)
 	ds2.EXPECT().Open().Return(nil).Times(1)
+	ds2.EXPECT().Close().Times(1)
 
 	vendor := datasource.NewMockVendor(ctrl)
 	datasource.DeclareImplementation(vendor)
@@ -179,10 +236,10 @@ func TestPatchDataSource(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	ctrl := gomock.NewController(t)
 	defer func() {
+		api.ClearDatasources()
 		ctrl.Finish()
 		defer recorder.Flush()
 		datasource.ClearVendors()
-		api.ClearDatasources()
 	}()
 
 	ds := datasource.NewMockDataSource(ctrl)
@@ -191,7 +248,8 @@ func TestPatchDataSource(t *testing.T) {
 	vendor.EXPECT().Accept(gomock.Any()).Return(true).Times(2)
 	vendor.EXPECT().CreateDataSource(gomock.Any()).Return(ds, nil).Times(2)
 	ds.EXPECT().Open().Return(nil).Times(2)
-	ds.EXPECT().Close().Times(1)
+	// Closed when replaced, then when the data sources are cleared.
+	ds.EXPECT().Close().Times(2)
 
 	req, _ := http.NewRequest("POST", contextPath+"/datasource", strings.NewReader("{\"id\":\"my-datasource\",\"vendor\":\"mock\",\"name\":\"test-mock\",\"bootstrap\":\"any:path\"}"))
 	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
@@ -227,3 +285,1115 @@ func TestPatchDataSource(t *testing.T) {
 	body, _ = ioutil.ReadAll(recorder.Body)
 	assert.Equal(t, "{\"datasources\":[{\"id\":\"my-datasource\",\"vendor\":\"mock\",\"name\":\"test-mock-2\",\"description\":\"\",\"readonly\":true}]}", string(body))
 }
+
+func TestConsumeEntryPoint(t *testing.T) {
+	// given
+	router := setupRouter()
+	server := httptest.NewServer(router)
+	ctrl := gomock.NewController(t)
+	ds := openMockDataSource(t, router, ctrl)
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		server.Close()
+		datasource.ClearVendors()
+	}()
+
+	// The mock tells when the consumption is stopped.
+	consumptionStopped := make(chan bool)
+	ds.EXPECT().Consume(gomock.Any(), gomock.Eq(datasource.EntryPoint("my-stream")), gomock.Any(), gomock.Any(), gomock.Eq(true)).DoAndReturn(
+		func(ctx context.Context, entryPointValue datasource.EntryPoint, values chan<- datasource.DataBatch, filter datasource.Filter, fromBeginning bool) (datasource.ActionStatus, error) {
+			go func() {
+				values <- datasource.DataBatch{Size: 1, Data: []interface{}{"my-value"}}
+				<-ctx.Done()
+				close(values)
+				consumptionStopped <- true
+			}()
+			return datasource.Moved, nil
+		}).Times(1)
+
+	// when
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+contextPath+"/data/my-datasource/entrypoint/my-stream/consume?fromBeginning=true", nil)
+
+	// then
+	assert.Nil(t, err)
+	var frame api.WebSocketFrame
+	err = conn.ReadJSON(&frame)
+	assert.Nil(t, err)
+	assert.Equal(t, api.WebSocketFrame{Kind: api.DataFrame, Size: 1, Data: []interface{}{map[string]interface{}{"raw": "bXktdmFsdWU=", "encoding": "text", "text": "my-value"}}}, frame)
+
+	// when
+	conn.Close()
+
+	// then
+	assert.True(t, <-consumptionStopped, "The consumption should stop when the web-socket is closed")
+}
+
+func TestListEntryPointsWithFramedWebSocket(t *testing.T) {
+	// given
+	router := setupRouter()
+	server := httptest.NewServer(router)
+	ctrl := gomock.NewController(t)
+	ds := openMockDataSource(t, router, ctrl)
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		server.Close()
+		datasource.ClearVendors()
+	}()
+
+	// The mock simulates an unreachable node of a cluster.
+	ds.EXPECT().ListEntryPoints(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter datasource.Filter, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
+			go func() {
+				entrypointsChannel <- datasource.NewProgressBatch(datasource.ScanProgress{ScannedKeys: 1000, ScannedNodes: 1, Nodes: 2})
+				entrypointsChannel <- datasource.DataBatch{Size: 2, Data: []interface{}{"a", "b"}}
+				entrypointsChannel <- datasource.NewErrorBatch(errors.New("node unreachable"))
+				close(entrypointsChannel)
+			}()
+			return datasource.Moved, nil
+		}).Times(1)
+
+	response, err := http.Get(server.URL + contextPath + "/data/my-datasource/entrypoint")
+	assert.Nil(t, err)
+	assert.Equal(t, 202, response.StatusCode)
+	var link struct {
+		Link string `json:"link"`
+	}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&link))
+
+	// when
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+contextPath+link.Link, nil)
+	assert.Nil(t, err)
+	var frames []string
+	for {
+		_, message, err := conn.ReadMessage()
+		assert.Nil(t, err)
+		frames = append(frames, string(message))
+		if strings.Contains(string(message), api.CompletionFrame) {
+			break
+		}
+	}
+	conn.Close()
+
+	// then
+	assert.Len(t, frames, 4)
+	assert.JSONEq(t, "{\"kind\":\"progress\",\"progress\":{\"scannedKeys\":1000,\"scannedNodes\":1,\"nodes\":2}}", frames[0])
+	assert.JSONEq(t, "{\"kind\":\"data\",\"size\":2,\"data\":[\"a\",\"b\"]}", frames[1])
+	assert.JSONEq(t, "{\"kind\":\"error\",\"error\":\"node unreachable\"}", frames[2])
+	assert.JSONEq(t, "{\"kind\":\"completion\",\"completion\":{\"total\":2,\"errors\":1}}", frames[3])
+}
+
+func TestListEntryPointsWithFramedWebSocketWhenTimedOut(t *testing.T) {
+	// given
+	router := setupRouter()
+	server := httptest.NewServer(router)
+	ctrl := gomock.NewController(t)
+	ds := datasource.NewMockDataSource(ctrl)
+	vendor := datasource.NewMockVendor(ctrl)
+	datasource.DeclareImplementation(vendor)
+	vendor.EXPECT().Accept(gomock.Any()).Return(true).Times(1)
+	vendor.EXPECT().CreateDataSource(gomock.Any()).Return(ds, nil).Times(1)
+	ds.EXPECT().Open().Return(nil).Times(1)
+	ds.EXPECT().Close().Times(1)
+	createDataSource(t, router, datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "mock", Name: "test-mock", Bootstrap: "any:path", Configuration: map[string]string{"timeout": "200ms"}})
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		server.Close()
+		datasource.ClearVendors()
+	}()
+
+	// The mock stops at the timeout without reporting it.
+	ds.EXPECT().ListEntryPoints(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter datasource.Filter, entrypointsChannel chan<- datasource.DataBatch, minTreeLevel uint, maxTreeLevel uint) (datasource.ActionStatus, error) {
+			go func() {
+				entrypointsChannel <- datasource.DataBatch{Size: 2, Data: []interface{}{"a", "b"}}
+				<-ctx.Done()
+				close(entrypointsChannel)
+			}()
+			return datasource.Moved, nil
+		}).Times(1)
+
+	response, err := http.Get(server.URL + contextPath + "/data/my-datasource/entrypoint")
+	assert.Nil(t, err)
+	assert.Equal(t, 202, response.StatusCode)
+	var link struct {
+		Link string `json:"link"`
+	}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&link))
+
+	// when
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+contextPath+link.Link, nil)
+	assert.Nil(t, err)
+	var frames []string
+	for {
+		_, message, err := conn.ReadMessage()
+		assert.Nil(t, err)
+		frames = append(frames, string(message))
+		if strings.Contains(string(message), api.CompletionFrame) {
+			break
+		}
+	}
+	conn.Close()
+
+	// then
+	assert.Len(t, frames, 3)
+	assert.JSONEq(t, "{\"kind\":\"data\",\"size\":2,\"data\":[\"a\",\"b\"]}", frames[0])
+	assert.JSONEq(t, "{\"kind\":\"error\",\"error\":\"context deadline exceeded\"}", frames[1])
+	assert.JSONEq(t, "{\"kind\":\"completion\",\"completion\":{\"total\":2,\"errors\":1}}", frames[2])
+}
+
+func TestGetEntryPointInfosWithObjectAndLocation(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	ctrl := gomock.NewController(t)
+	ds := openMockDataSource(t, router, ctrl)
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		defer recorder.Flush()
+		datasource.ClearVendors()
+	}()
+
+	// Only a cluster has a location for its entry points.
+	idleTime := int64(1500)
+	ds.EXPECT().GetEntryPointInfos(gomock.Any(), gomock.Eq(datasource.EntryPoint("my-hash"))).Return(datasource.EntryPointInfos{
+		Type:       datasource.Hash,
+		Length:     3,
+		TimeToLive: -1,
+		Object:     &datasource.ObjectInfos{MemoryUsage: 1024, Encoding: "listpack", IdleTime: &idleTime},
+		Location:   &datasource.LocationInfos{Slot: 12182, NodeId: "abcd", Node: "10.0.0.1:6379", Replicas: []string{"10.0.0.2:6379"}},
+	}, nil).Times(1)
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-hash/info", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.JSONEq(t, `{"type":"HASH","length":3,"timeToLive":0,
+		"object":{"memoryUsage":1024,"encoding":"listpack","idleTime":1500},
+		"location":{"slot":12182,"nodeId":"abcd","node":"10.0.0.1:6379","replicas":["10.0.0.2:6379"]}}`, string(body))
+}
+
+func TestGetEntryPointContentPage(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-list
+    type: list
+    values: [a, b, c, d, e]
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-list/content?cursor=1&count=2", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.JSONEq(t, "{\"size\":2,\"data\":[{\"raw\":\"Yg==\",\"encoding\":\"text\",\"text\":\"b\"},{\"raw\":\"Yw==\",\"encoding\":\"text\",\"text\":\"c\"}],\"cursor\":\"3\"}", string(body))
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-list/content?count=many", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestGetSortedSetRange(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-leaderboard
+    type: scored_set
+    scoredMembers:
+      - {member: alice, score: 5}
+      - {member: bob, score: 10}
+      - {member: carol, score: 20}
+      - {member: dave, score: 30}
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-leaderboard/zset/range?by=score&start=(10&stop=%2Binf&reverse=true&offset=1&count=10", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.JSONEq(t, "{\"size\":1,\"data\":[{\"score\":20,\"values\":[{\"raw\":\"Y2Fyb2w=\",\"encoding\":\"text\",\"text\":\"carol\"}]}]}", string(body))
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-leaderboard/zset/range?by=weight", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestGetEntryPointStreamRangeWithInvalidParameters(t *testing.T) {
+	// given
+	router := setupRouter()
+	defer func() {
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-stream
+    type: stream
+    messages:
+      - id: 1-1
+        fields:
+          kind: login
+`, datasource.DataSourceDescriptor{})
+
+	for _, query := range []string{"count=ten", "count=-1", "reverse=maybe"} {
+		// when
+		req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-stream/stream?"+query, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		// then
+		assert.Equal(t, 400, recorder.Code, query)
+	}
+}
+
+func TestGetEntryPointStreamInfosWithInvalidCount(t *testing.T) {
+	// given
+	router := setupRouter()
+	defer func() {
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-stream
+    type: stream
+    messages:
+      - id: 1-1
+        fields:
+          kind: login
+`, datasource.DataSourceDescriptor{})
+
+	for _, count := range []string{"ten", "-1"} {
+		// when
+		req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-stream/stream/groups?count="+count, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		// then
+		assert.Equal(t, 400, recorder.Code, count)
+	}
+}
+
+func TestGetEntryPointContentWithCommaInFilter(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-list
+    type: list
+    values: ["a,b", "c"]
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-list/content?filter=a,*", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.JSONEq(t, "{\"size\":1,\"data\":[{\"raw\":\"YSxi\",\"encoding\":\"text\",\"text\":\"a,b\"}]}", string(body))
+}
+
+func TestListEntryPointsWithInvalidFilter(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, "", datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint?filter=group-*&regex=group-[0-9", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestListChannels(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	ctrl := gomock.NewController(t)
+	ds := openMockDataSource(t, router, ctrl)
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		defer recorder.Flush()
+		datasource.ClearVendors()
+	}()
+
+	// The subscribers are clients of the data source, out of the reach of the test.
+	ds.EXPECT().ListChannels(gomock.Any(), datasource.Filter{Glob: "news.*"}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter datasource.Filter, channels chan<- datasource.DataBatch) (datasource.ActionStatus, error) {
+			channels <- datasource.DataBatch{
+				Size: 1,
+				Data: []interface{}{datasource.ChannelNode{Path: "news.sport", Type: datasource.EntryPointTypesAsString[datasource.Channel], Subscribers: 2}},
+			}
+			return datasource.Completed, nil
+		}).Times(1)
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/channel?filter=news.*", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.JSONEq(t, "{\"size\":1,\"data\":[{\"path\":\"news.sport\",\"type\":\"CHANNEL\",\"subscribers\":2}]}", string(body))
+}
+
+func TestSubscribeChannelWithInvalidPattern(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	ctrl := gomock.NewController(t)
+	openMockDataSource(t, router, ctrl)
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		defer recorder.Flush()
+		datasource.ClearVendors()
+	}()
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/channel/news.*/subscribe?pattern=maybe", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	// The data source is not subscribed to.
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestWatchEntryPointWithDisabledNotifications(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	ctrl := gomock.NewController(t)
+	ds := openMockDataSource(t, router, ctrl)
+	defer func() {
+		api.ClearDatasources()
+		ctrl.Finish()
+		defer recorder.Flush()
+		datasource.ClearVendors()
+	}()
+
+	// The memory vendor always notifies the changes.
+	ds.EXPECT().Watch(gomock.Any(), gomock.Eq(datasource.EntryPoint("session")), gomock.Any()).Return(datasource.None, errors.New("the keyspace notifications are disabled")).Times(1)
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/session/watch", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 500, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"error\":\"the keyspace notifications are disabled\"}", string(body))
+}
+
+func TestAddSetMembers(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-set
+    type: set
+    members: [a]
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("POST", contextPath+"/data/my-datasource/entrypoint/my-set/set/members", strings.NewReader("{\"members\":[\"a\",\"b\"]}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"count\":1}", string(body))
+}
+
+func TestAddSetMembersAndGetContentWithMemoryDataSource(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, "", datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("POST", contextPath+"/data/my-datasource/entrypoint/my-set/set/members", strings.NewReader("{\"members\":[\"b\",\"a\"]}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-set/content", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"data\":[{\"raw\":\"YQ==\",\"encoding\":\"text\",\"text\":\"a\"},{\"raw\":\"Yg==\",\"encoding\":\"text\",\"text\":\"b\"}],\"size\":2}", string(body))
+}
+
+func TestGetContentDecodedWithMemoryDataSource(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: encoded:set
+    type: set
+    members: [YQ==]
+  - key: plain:set
+    type: set
+    members: [YQ==]
+`, datasource.DataSourceDescriptor{Configuration: map[string]string{"decoders[encoded:]": "base64"}})
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/encoded:set/content", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"data\":[{\"raw\":\"WVE9PQ==\",\"encoding\":\"text\",\"text\":\"YQ==\",\"decoded\":\"a\"}],\"size\":1}", string(body))
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/plain:set/content", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ = ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"data\":[{\"raw\":\"WVE9PQ==\",\"encoding\":\"text\",\"text\":\"YQ==\"}],\"size\":1}", string(body))
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/plain:set/content?decoders=base64,hex", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	body, _ = ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"data\":[{\"raw\":\"WVE9PQ==\",\"encoding\":\"text\",\"text\":\"YQ==\",\"error\":\"the decoder hex failed: encoding/hex: odd length hex string\"}],\"size\":1}", string(body))
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/plain:set/content?decoders=rot13", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+	body, _ = ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"error\":\"the decoder 'rot13' is unknown\"}", string(body))
+}
+
+func TestRemoveSetMembersWithoutMember(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-set
+    type: set
+    members: [a]
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/my-set/set/members", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-set/info", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "\"length\":1")
+}
+
+func TestSetListValueInReadOnlyMode(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-list
+    type: list
+    values: [a, b, c]
+`, datasource.DataSourceDescriptor{ReadOnly: true})
+
+	// when
+	req, _ := http.NewRequest("PUT", contextPath+"/data/my-datasource/entrypoint/my-list/list/values/2", strings.NewReader("{\"value\":\"my-value\"}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 500, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Equal(t, "{\"error\":\"the data source can be only read\"}", string(body))
+}
+
+func TestSetEntryPointExpiry(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-key
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("PUT", contextPath+"/data/my-datasource/entrypoint/my-key/expiry", strings.NewReader("{\"timeToLive\":1500}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-key/info", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	var infos struct {
+		TimeToLive int64 `json:"timeToLive"`
+	}
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&infos))
+	assert.True(t, infos.TimeToLive > 0 && infos.TimeToLive <= 1500, "The time to live should be set")
+}
+
+func TestSetEntryPointExpiryWithTwoExpiries(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-key
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("PUT", contextPath+"/data/my-datasource/entrypoint/my-key/expiry", strings.NewReader("{\"timeToLive\":1500,\"expireAt\":\"2030-01-01T00:00:00Z\"}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestSetEntryPointChildrenExpiry(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: session:1
+    type: value
+    value: my-value
+    timeToLive: 10m
+  - key: session:2
+    type: value
+    value: my-value
+    timeToLive: 10m
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("PUT", contextPath+"/data/my-datasource/entrypoint/session/children/expiry", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 202, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Contains(t, string(body), "\"link\":\"/ws/")
+}
+
+func TestSetEntryPointChildrenExpiryNotInTheFuture(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: session:1
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+
+	for _, body := range []string{"{\"expireAt\":\"2020-01-01T00:00:00Z\"}", "{\"timeToLive\":0}", "{\"timeToLive\":-1}"} {
+		// when
+		req, _ := http.NewRequest("PUT", contextPath+"/data/my-datasource/entrypoint/session/children/expiry", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		// then
+		assert.Equal(t, 400, recorder.Code, body)
+	}
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/session:1/info", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code, "The children should not be deleted")
+}
+
+func TestListStreams(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: session:1
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+	req, _ := http.NewRequest("PUT", contextPath+"/data/my-datasource/entrypoint/session/children/expiry", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/admin/streams", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	var response struct {
+		Streams []api.WebSocketStream `json:"streams"`
+	}
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.Len(t, response.Streams, 1)
+	assert.Equal(t, datasource.DataSourceId("my-datasource"), response.Streams[0].DataSourceId)
+	assert.Equal(t, contextPath+"/data/my-datasource/entrypoint/session/children/expiry", response.Streams[0].Path)
+	assert.False(t, response.Streams[0].Active)
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/datasource/my-datasource", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("GET", contextPath+"/admin/streams", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.JSONEq(t, "{\"streams\":[]}", string(body))
+}
+
+func TestMoveEntryPointTreeInDryRun(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: a:b:1
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("POST", contextPath+"/data/my-datasource/entrypoint/a:b/move", strings.NewReader("{\"target\":\"a:c\",\"dryRun\":true}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 202, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Contains(t, string(body), "\"link\":\"/ws/")
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/a:b:1/info", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code, "The entry points should not be moved in dry run")
+}
+
+func TestDeleteEntryPointChildrenWithConfirmation(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: session:1
+    type: value
+    value: my-value
+  - key: session:2
+    type: value
+    value: my-value
+  - key: session:3
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/session/children/preview?sample=2", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	var preview map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &preview)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), preview["count"])
+	assert.Equal(t, []interface{}{"session:1", "session:2"}, preview["sample"])
+	assert.Equal(t, float64(24), preview["memory"])
+	token := preview["token"].(string)
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/other/children?token="+token, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code, "The token should only confirm the deletion of the previewed entry point")
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/session/children?rate=500&token="+token, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 202, recorder.Code)
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/session/children?token="+token, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code, "The token should be used only once")
+}
+
+func TestDeleteEntryPointChildrenKeepsConfirmationWhenFailing(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: session:1
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{ReadOnly: true})
+	req, _ := http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/session/children/preview", nil)
+	router.ServeHTTP(recorder, req)
+	var preview map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &preview)
+	assert.Nil(t, err)
+	token := preview["token"].(string)
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/session/children?token="+token, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 500, recorder.Code)
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/session/children?token="+token, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 500, recorder.Code, "The token should remain valid after a failed deletion")
+}
+
+func TestDeleteEntryPointChildrenWithoutConfirmation(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: session:1
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/session/children", nil)
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 400, recorder.Code)
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/session:1/info", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code, "The children should not be deleted")
+}
+
+func TestUpdateDataSourceKeepsTheTrashEntries(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-key
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+	req, _ := http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/my-key", nil)
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+
+	// when
+	body, _ := json.Marshal(datasource.DataSourceDescriptor{Id: "my-datasource", Vendor: "memory", Name: "renamed-memory", Bootstrap: "memory://test"})
+	req, _ = http.NewRequest("PATCH", contextPath+"/datasource", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 204, recorder.Code)
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/trash", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+	var trash struct {
+		Entries []datasource.TrashEntry `json:"entries"`
+	}
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&trash))
+	assert.Len(t, trash.Entries, 1)
+	assert.Equal(t, datasource.EntryPoint("my-key"), trash.Entries[0].EntryPoint)
+}
+
+func TestListAndRestoreTrashEntries(t *testing.T) {
+	// given
+	router := setupRouter()
+	recorder := httptest.NewRecorder()
+	defer func() {
+		defer recorder.Flush()
+		datasource.ClearVendors()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: my-key
+    type: value
+    value: my-value
+`, datasource.DataSourceDescriptor{})
+	req, _ := http.NewRequest("DELETE", contextPath+"/data/my-datasource/entrypoint/my-key", nil)
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/trash", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	var trash struct {
+		Entries []datasource.TrashEntry `json:"entries"`
+	}
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&trash))
+	assert.Len(t, trash.Entries, 1)
+	assert.Equal(t, datasource.EntryPoint("my-key"), trash.Entries[0].EntryPoint)
+	assert.Equal(t, "delete", trash.Entries[0].Operation)
+
+	// when
+	req, _ = http.NewRequest("POST", contextPath+"/data/my-datasource/trash/"+trash.Entries[0].Id+"/restore?replace=true", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/entrypoint/my-key/content", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "\"text\":\"my-value\"")
+}
+
+func TestAnalyzeEntryPointsWithMemoryDataSource(t *testing.T) {
+	// given
+	router := setupRouter()
+	server := httptest.NewServer(router)
+	recorder := httptest.NewRecorder()
+	defer func() {
+		server.Close()
+		api.ClearDatasources()
+	}()
+	openMemoryDataSource(t, router, `
+entries:
+  - key: users:1
+    type: set
+    members: [a, b]
+  - key: users:2
+    type: set
+    members: [a, b]
+  - key: counter
+    type: set
+    members: [a, b]
+`, datasource.DataSourceDescriptor{})
+
+	// when
+	req, _ := http.NewRequest("POST", contextPath+"/data/my-datasource/analysis", strings.NewReader("{\"top\":1}"))
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 202, recorder.Code)
+	var started api.AnalysisJob
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&started))
+	assert.NotEmpty(t, started.Id)
+	assert.Equal(t, 1, started.Options.Top)
+
+	// when
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+contextPath+"/data/my-datasource/analysis/"+started.Id+"/stream", nil)
+
+	// then
+	assert.Nil(t, err)
+	defer conn.Close()
+	var frame api.WebSocketFrame
+	for frame.Kind != api.DataFrame {
+		assert.Nil(t, conn.ReadJSON(&frame))
+	}
+	assert.Equal(t, uint64(1), frame.Size)
+	report := frame.Data[0].(map[string]interface{})
+	assert.Equal(t, api.AnalysisCompleted, report["status"])
+	assert.Nil(t, conn.ReadJSON(&frame))
+	assert.Equal(t, api.CompletionFrame, frame.Kind)
+
+	// when
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/analysis/"+started.Id, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 200, recorder.Code)
+	var finished api.AnalysisJob
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&finished))
+	assert.Equal(t, api.AnalysisCompleted, finished.Status)
+	assert.Equal(t, datasource.KeysUsage{Keys: 3, MemoryUsage: 27}, finished.Report.Total)
+	assert.Equal(t, []datasource.PrefixUsage{{Prefix: "users:", KeysUsage: datasource.KeysUsage{Keys: 2, MemoryUsage: 18}}}, finished.Report.LargestPrefixes)
+	assert.Equal(t, map[string]datasource.KeysUsage{"SET": {Keys: 3, MemoryUsage: 27}}, finished.Report.Types)
+	assert.Len(t, finished.Report.LargestKeys, 1)
+
+	// when
+	req, _ = http.NewRequest("DELETE", contextPath+"/data/my-datasource/analysis/"+started.Id, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, 202, recorder.Code)
+	req, _ = http.NewRequest("GET", contextPath+"/data/my-datasource/analysis/"+started.Id, nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 404, recorder.Code)
+}
@@ -19,6 +19,24 @@ if (!_.isNil(process) && !_.isNil(process.env) && !_.isNil(process.env.VUE_APP_A
   wsRoot += location.hostname + ':' + location.port + apiRoot
 }
 
+// The values of the contents are sent with their raw bytes in base64, their text and their decoded form:
+// the most readable one is displayed.
+function displayedValues(value) {
+  if (_.isArray(value)) {
+    return value.map(displayedValues);
+  }
+  if (_.isPlainObject(value)) {
+    if (_.has(value, 'raw') && _.has(value, 'encoding')) {
+      if (!_.isNil(value.decoded)) {
+        return _.isString(value.decoded) ? value.decoded : JSON.stringify(value.decoded);
+      }
+      return _.isNil(value.text) ? value.raw : value.text;
+    }
+    return _.mapValues(value, displayedValues);
+  }
+  return value;
+}
+
 export const ApiService = {
   init() {
     Vue.use(VueAxios, axios);
@@ -92,19 +110,27 @@ export const DatasourcesService = {
               .then(response => {
                 if (response.status === 200) {
                   details.content = response.data
+                  details.content.data = displayedValues(details.content.data)
                   resolve(details)
                 } else if (response.status === 202) {
                   let receivedValues = [];
                   let socket = new WebSocket(this.wsRoot + response.data.link);
                   socket.onopen = () => {
+                    let errors = [];
                     socket.onmessage = ({data}) => {
-                      let jsonData = JSON.parse(data);
-                      if (jsonData.size) {
-                        receivedValues = receivedValues.concat(jsonData.data);
-                      } else {
+                      let frame = JSON.parse(data);
+                      if (frame.kind === 'data') {
+                        receivedValues = receivedValues.concat(frame.data);
+                      } else if (frame.kind === 'error') {
+                        errors.push(frame.error);
+                      } else if (frame.kind === 'completion') {
+                        if (errors.length > 0) {
+                          reject(errors.join(', '));
+                          return;
+                        }
                         details.content = {
                           length: receivedValues.length,
-                          data: receivedValues
+                          data: displayedValues(receivedValues)
                         };
                         resolve(details);
                       }
@@ -145,26 +171,75 @@ export const DatasourcesService = {
         })
   },
 
-  deleteEntrypointChildren(datasourceId, fullPath) {
-    return ApiService.delete(`data/${encodeURIComponent(datasourceId)}/entrypoint/${encodeURIComponent(fullPath)}/children`, {format: 'json'})
+  // The preview contains the count, a sample and the memory of the children to delete, and the token
+  // confirming their deletion.
+  previewEntrypointChildrenDeletion(datasourceId, fullPath) {
+    return ApiService.get(`data/${encodeURIComponent(datasourceId)}/entrypoint/${encodeURIComponent(fullPath)}/children/preview`)
+        .then(response => response.data)
         .catch(e => {
           return Promise.reject(e.response.data.error)
         })
   },
 
-  getEntryPointsFromWebsocket(link) {
+  // The deletion runs in background on the server and its result is read from the web-socket.
+  deleteEntrypointChildren(datasourceId, fullPath, token) {
+    return ApiService.delete(`data/${encodeURIComponent(datasourceId)}/entrypoint/${encodeURIComponent(fullPath)}/children?token=${encodeURIComponent(token)}`)
+        .catch(e => {
+          return Promise.reject(e.response.data.error)
+        })
+        .then(response => this.waitForCompletionFromWebsocket(response.data.link))
+  },
+
+  // Resolves with the completion of the operation, or rejects with its errors.
+  waitForCompletionFromWebsocket(link) {
+    let errors = [];
+    return new Promise((resolve, reject) => {
+      let socket = new WebSocket(wsRoot + link);
+      socket.onmessage = ({data}) => {
+        let frame = JSON.parse(data);
+        if (frame.kind === 'error') {
+          errors.push(frame.error);
+        } else if (frame.kind === 'completion') {
+          socket.close(1000, "End of data");
+          if (errors.length > 0) {
+            reject(errors.join(', '));
+            return;
+          }
+          resolve(frame.completion);
+        }
+      };
+      socket.onerror = (e) => {
+        reject(e);
+      };
+    })
+  },
+
+  // onProgress is called with the scanned keys and nodes, while the entry points are listed.
+  getEntryPointsFromWebsocket(link, onProgress) {
     let receivedValues = [];
+    let errors = [];
     return new Promise((resolve, reject) => {
       let socket = new WebSocket(wsRoot + link);
       socket.onopen = () => {
         socket.onmessage = ({data}) => {
-          let jsonData = JSON.parse(data);
-          if (jsonData.size > 0) {
-            receivedValues = receivedValues.concat(jsonData.data);
-          } else {
+          let frame = JSON.parse(data);
+          if (frame.kind === 'data') {
+            receivedValues = receivedValues.concat(frame.data);
+          } else if (frame.kind === 'progress') {
+            if (onProgress) {
+              onProgress(frame.progress);
+            }
+          } else if (frame.kind === 'error') {
+            errors.push(frame.error);
+          } else if (frame.kind === 'completion') {
             // eslint-disable-next-line
             console.log("Closing the websocket");
             socket.close(1000, "End of data");
+            if (errors.length > 0) {
+              // The tree is incomplete when a node could not be scanned.
+              reject(errors.join(', '));
+              return;
+            }
             receivedValues = receivedValues
               .sort((a, b) => {
                 return a.path < b.path ? -1 : 1
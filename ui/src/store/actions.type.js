@@ -1,6 +1,7 @@
 export const FETCH_DATASOURCE = 'FETCH_DATASOURCE'
 export const SELECT_DATASOURCE = 'SELECT_DATASOURCE'
 export const FETCH_ENTRY_POINTS = 'FETCH_ENTRY_POINTS'
+export const PREVIEW_CHILDREN_DELETION = 'PREVIEW_CHILDREN_DELETION'
 export const DELETE_CHILDREN_NODE = 'DELETE_CHILDREN_NODE'
 export const SELECT_NODE = 'SELECT_NODE'
 export const FETCH_NODE_DETAILS = 'FETCH_NODE_DETAILS'
@@ -4,6 +4,7 @@ import {
     FETCH_DATASOURCE,
     FETCH_ENTRY_POINTS,
     FETCH_NODE_DETAILS,
+    PREVIEW_CHILDREN_DELETION,
     SELECT_DATASOURCE,
     SELECT_NODE
 } from './actions.type';
@@ -67,10 +68,19 @@ export const actions = {
                 context.commit(ADD_ERROR, e);
             });
     },
-    [DELETE_CHILDREN_NODE](context, node) {
-        return DatasourcesService.deleteEntrypointChildren(context.state.selectedDatasourceId, node.fullPath)
+    [PREVIEW_CHILDREN_DELETION](context, node) {
+        return DatasourcesService.previewEntrypointChildrenDeletion(context.state.selectedDatasourceId, node.fullPath)
             .catch((e) => {
                 context.commit(ADD_ERROR, e);
+                return Promise.reject(e);
+            });
+    },
+    // The token is the one of the preview of the deletion, confirmed by the user.
+    [DELETE_CHILDREN_NODE](context, {node, token}) {
+        return DatasourcesService.deleteEntrypointChildren(context.state.selectedDatasourceId, node.fullPath, token)
+            .catch((e) => {
+                context.commit(ADD_ERROR, e);
+                return Promise.reject(e);
             });
     },
     [SELECT_NODE](context, node) {
@@ -0,0 +1,105 @@
+import Vuex from "vuex"
+import { shallowMount, createLocalVue } from "@vue/test-utils"
+import EntrypointComponent from "../../../src/components/Entrypoint"
+import EventBus from "../../../src/eventBus"
+
+const localVue = createLocalVue()
+localVue.use(Vuex)
+
+const flushPromises = () => new Promise(resolve => setTimeout(resolve))
+
+const preview = {
+  count: 3,
+  sample: ['users:1', 'users:<2>'],
+  memory: 120,
+  token: 'the-token'
+}
+
+describe("EntrypointComponent", () => {
+  let actions
+  let node
+  let wrapper
+  let confirmation
+
+  beforeEach(() => {
+    actions = {
+      'PREVIEW_CHILDREN_DELETION': jest.fn(() => Promise.resolve(preview)),
+      'DELETE_CHILDREN_NODE': jest.fn(() => Promise.resolve({total: 0, errors: 0}))
+    }
+    node = {
+      fullPath: 'users',
+      path: 'users',
+      level: 0,
+      length: 3,
+      hasChildren: true
+    }
+    wrapper = shallowMount(EntrypointComponent, {
+      store: new Vuex.Store({actions}),
+      localVue,
+      propsData: {node}
+    })
+    confirmation = null
+    EventBus.$on('display-modal', (event) => {
+      confirmation = event
+    })
+  })
+
+  afterEach(() => {
+    EventBus.$off('display-modal')
+  })
+
+  it("previews the deletion of the children before asking the confirmation", async () => {
+    // when
+    wrapper.vm.deleteChildren()
+    await flushPromises()
+
+    // then
+    expect(actions.PREVIEW_CHILDREN_DELETION).toHaveBeenCalledWith(expect.anything(), node)
+    expect(confirmation.message).toContain('delete the 3 children of users, using 120 bytes?')
+    expect(confirmation.message).toContain('users:1<br/>users:&lt;2&gt;<br/>...')
+    expect(actions.DELETE_CHILDREN_NODE).not.toHaveBeenCalled()
+  })
+
+  it("deletes the children with the token of the preview once confirmed", async () => {
+    // given
+    wrapper.vm.deleteChildren()
+    await flushPromises()
+
+    // when
+    confirmation.yesHandler()
+    await flushPromises()
+
+    // then
+    expect(actions.DELETE_CHILDREN_NODE).toHaveBeenCalledWith(expect.anything(), {node, token: 'the-token'})
+    expect(node.length).toBe(0)
+    expect(node.hasChildren).toBe(false)
+    expect(wrapper.vm.loading).toBe(false)
+  })
+
+  it("does not delete the children when the confirmation is refused", async () => {
+    // given
+    wrapper.vm.deleteChildren()
+    await flushPromises()
+
+    // when
+    confirmation.noHandler()
+    await flushPromises()
+
+    // then
+    expect(actions.DELETE_CHILDREN_NODE).not.toHaveBeenCalled()
+    expect(node.length).toBe(3)
+  })
+
+  it("does not ask the confirmation when the preview fails", async () => {
+    // given
+    actions.PREVIEW_CHILDREN_DELETION.mockImplementation(() => Promise.reject('The preview failed'))
+
+    // when
+    wrapper.vm.deleteChildren()
+    await flushPromises()
+
+    // then
+    expect(confirmation).toBeNull()
+    expect(wrapper.vm.loading).toBe(false)
+  })
+})
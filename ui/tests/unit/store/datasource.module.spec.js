@@ -1,6 +1,6 @@
 import {actions, getters, mutations} from '../../../src/store/datasource.module';
 import {testAction} from './store.test.helper';
-import {SET_DATASOURCES} from '../../../src/store/mutations.type'
+import {ADD_ERROR, SET_DATASOURCES} from '../../../src/store/mutations.type'
 import {datasourceResponse} from '../data/api-data'
 import {DatasourcesService} from '../../../src/services/api.service'
 
@@ -37,6 +37,52 @@ describe('actions', () => {
             {type: SET_DATASOURCES, payload: datasourceResponse.datasources}
         ], done)
     })
+
+    it('PREVIEW_CHILDREN_DELETION', async () => {
+        // given
+        const state = {
+            selectedDatasourceId: 'single'
+        }
+        const preview = {count: 2, sample: ['users:1', 'users:2'], memory: 100, token: 'the-token'}
+        DatasourcesService.previewEntrypointChildrenDeletion.mockResolvedValue(preview);
+
+        // when
+        const result = await actions.PREVIEW_CHILDREN_DELETION({commit: jest.fn(), state}, {fullPath: 'users'})
+
+        // then
+        expect(DatasourcesService.previewEntrypointChildrenDeletion).toHaveBeenCalledWith('single', 'users')
+        expect(result).toEqual(preview)
+    })
+
+    it('DELETE_CHILDREN_NODE with the token of the preview', async () => {
+        // given
+        const state = {
+            selectedDatasourceId: 'single'
+        }
+        DatasourcesService.deleteEntrypointChildren.mockResolvedValue({total: 0, errors: 0});
+
+        // when
+        await actions.DELETE_CHILDREN_NODE({commit: jest.fn(), state}, {node: {fullPath: 'users'}, token: 'the-token'})
+
+        // then
+        expect(DatasourcesService.deleteEntrypointChildren).toHaveBeenCalledWith('single', 'users', 'the-token')
+    })
+
+    it('DELETE_CHILDREN_NODE reports the failure', async () => {
+        // given
+        const state = {
+            selectedDatasourceId: 'single'
+        }
+        const commit = jest.fn()
+        DatasourcesService.deleteEntrypointChildren.mockRejectedValue('The token is not valid');
+
+        // when
+        await expect(actions.DELETE_CHILDREN_NODE({commit, state}, {node: {fullPath: 'users'}, token: 'the-token'}))
+            .rejects.toEqual('The token is not valid')
+
+        // then
+        expect(commit).toHaveBeenCalledWith(ADD_ERROR, 'The token is not valid')
+    })
 })
 
 describe('getters', () => {